@@ -0,0 +1,342 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/redhat-developer/gitops-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// V1beta1SpecAnnotation holds the v1beta1 spec of a GitopsService whose settings cannot be
+// represented in v1alpha1, so that a round trip through v1alpha1 does not lose them.
+const V1beta1SpecAnnotation = "pipelines.openshift.io/v1beta1-spec"
+
+// ConvertTo converts this GitopsService to the Hub version (v1beta1).
+func (src *GitopsService) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.GitopsService)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub type %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = v1beta1.GitopsServiceSpec{}
+	convertSpecToV1beta1(&src.Spec, &dst.Spec)
+	convertStatusToV1beta1(&src.Status, &dst.Status)
+
+	stashed, found := dst.Annotations[V1beta1SpecAnnotation]
+	if !found {
+		return nil
+	}
+	delete(dst.Annotations, V1beta1SpecAnnotation)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	restored := v1beta1.GitopsServiceSpec{}
+	if err := json.Unmarshal([]byte(stashed), &restored); err != nil {
+		return fmt.Errorf("failed to restore v1beta1 spec from annotation %s: %w", V1beta1SpecAnnotation, err)
+	}
+
+	// The stashed spec is the state of the object when it was last converted from v1beta1.
+	// Settings changed through v1alpha1 since then take precedence over it.
+	stashedSpec := GitopsServiceSpec{}
+	convertSpecFromV1beta1(&restored, &stashedSpec)
+	if src.Spec.RunOnInfra != stashedSpec.RunOnInfra {
+		restored.RunOnInfra = src.Spec.RunOnInfra
+	}
+	if src.Spec.ImagePullPolicy != stashedSpec.ImagePullPolicy {
+		restored.ImagePullPolicy = src.Spec.ImagePullPolicy
+	}
+	if !equality.Semantic.DeepEqual(src.Spec.NodeSelector, stashedSpec.NodeSelector) ||
+		!equality.Semantic.DeepEqual(src.Spec.Tolerations, stashedSpec.Tolerations) {
+		restored.DefaultArgoCD = mergeDefaultArgoCDPlacement(restored.DefaultArgoCD, dst.Spec.DefaultArgoCD)
+		restored.Backend = mergeBackendPlacement(restored.Backend, dst.Spec.Backend)
+		restored.ConsolePlugin = mergeConsolePluginPlacement(restored.ConsolePlugin, dst.Spec.ConsolePlugin)
+	}
+	if !equality.Semantic.DeepEqual(backendResources(&src.Spec), backendResources(&stashedSpec)) {
+		if restored.Backend == nil {
+			restored.Backend = &v1beta1.BackendSpec{}
+		}
+		restored.Backend.Resources = backendResources(&src.Spec)
+	}
+	if !equality.Semantic.DeepEqual(gitopsPluginResources(&src.Spec), gitopsPluginResources(&stashedSpec)) {
+		if restored.ConsolePlugin == nil {
+			restored.ConsolePlugin = &v1beta1.ConsolePluginSpec{}
+		}
+		restored.ConsolePlugin.Resources = gitopsPluginResources(&src.Spec)
+	}
+	dst.Spec = restored
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1beta1) to this version.
+func (dst *GitopsService) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.GitopsService)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub type %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = GitopsServiceSpec{}
+	convertSpecFromV1beta1(&src.Spec, &dst.Spec)
+	convertStatusFromV1beta1(&src.Status, &dst.Status)
+
+	// Stash the v1beta1 spec only when v1alpha1 cannot represent it
+	converted := v1beta1.GitopsServiceSpec{}
+	convertSpecToV1beta1(&dst.Spec, &converted)
+	if equality.Semantic.DeepEqual(converted, src.Spec) {
+		if dst.Annotations != nil {
+			delete(dst.Annotations, V1beta1SpecAnnotation)
+		}
+		return nil
+	}
+
+	stashed, err := json.Marshal(src.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to store v1beta1 spec in annotation %s: %w", V1beta1SpecAnnotation, err)
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[V1beta1SpecAnnotation] = string(stashed)
+	return nil
+}
+
+// convertSpecToV1beta1 applies the global v1alpha1 placement to every v1beta1 component
+func convertSpecToV1beta1(src *GitopsServiceSpec, dst *v1beta1.GitopsServiceSpec) {
+	dst.RunOnInfra = src.RunOnInfra
+	dst.ImagePullPolicy = src.ImagePullPolicy
+
+	if len(src.NodeSelector) > 0 || len(src.Tolerations) > 0 {
+		dst.DefaultArgoCD = &v1beta1.DefaultArgoCDSpec{
+			NodeSelector: copyNodeSelector(src.NodeSelector),
+			Tolerations:  copyTolerations(src.Tolerations),
+		}
+		dst.Backend = &v1beta1.BackendSpec{
+			NodeSelector: copyNodeSelector(src.NodeSelector),
+			Tolerations:  copyTolerations(src.Tolerations),
+		}
+		dst.ConsolePlugin = &v1beta1.ConsolePluginSpec{
+			NodeSelector: copyNodeSelector(src.NodeSelector),
+			Tolerations:  copyTolerations(src.Tolerations),
+		}
+	}
+
+	if resources := backendResources(src); resources != nil {
+		if dst.Backend == nil {
+			dst.Backend = &v1beta1.BackendSpec{}
+		}
+		dst.Backend.Resources = resources.DeepCopy()
+	}
+	if resources := gitopsPluginResources(src); resources != nil {
+		if dst.ConsolePlugin == nil {
+			dst.ConsolePlugin = &v1beta1.ConsolePluginSpec{}
+		}
+		dst.ConsolePlugin.Resources = resources.DeepCopy()
+	}
+}
+
+// convertSpecFromV1beta1 keeps the placement of the default Argo CD instance, or of the first component
+// that sets one, as v1alpha1 only has a single placement shared by every workload
+func convertSpecFromV1beta1(src *v1beta1.GitopsServiceSpec, dst *GitopsServiceSpec) {
+	dst.RunOnInfra = src.RunOnInfra
+	dst.ImagePullPolicy = src.ImagePullPolicy
+
+	switch {
+	case src.DefaultArgoCD != nil && (len(src.DefaultArgoCD.NodeSelector) > 0 || len(src.DefaultArgoCD.Tolerations) > 0):
+		dst.NodeSelector = copyNodeSelector(src.DefaultArgoCD.NodeSelector)
+		dst.Tolerations = copyTolerations(src.DefaultArgoCD.Tolerations)
+	case src.Backend != nil && (len(src.Backend.NodeSelector) > 0 || len(src.Backend.Tolerations) > 0):
+		dst.NodeSelector = copyNodeSelector(src.Backend.NodeSelector)
+		dst.Tolerations = copyTolerations(src.Backend.Tolerations)
+	case src.ConsolePlugin != nil && (len(src.ConsolePlugin.NodeSelector) > 0 || len(src.ConsolePlugin.Tolerations) > 0):
+		dst.NodeSelector = copyNodeSelector(src.ConsolePlugin.NodeSelector)
+		dst.Tolerations = copyTolerations(src.ConsolePlugin.Tolerations)
+	}
+
+	if src.Backend != nil && src.Backend.Resources != nil {
+		if dst.ConsolePlugin == nil {
+			dst.ConsolePlugin = &ConsolePluginStruct{}
+		}
+		dst.ConsolePlugin.Backend = &BackendStruct{Resources: src.Backend.Resources.DeepCopy()}
+	}
+	if src.ConsolePlugin != nil && src.ConsolePlugin.Resources != nil {
+		if dst.ConsolePlugin == nil {
+			dst.ConsolePlugin = &ConsolePluginStruct{}
+		}
+		dst.ConsolePlugin.GitopsPlugin = &GitopsPluginStruct{Resources: src.ConsolePlugin.Resources.DeepCopy()}
+	}
+}
+
+func convertStatusToV1beta1(src *GitopsServiceStatus, dst *v1beta1.GitopsServiceStatus) {
+	dst.ObservedGeneration = src.ObservedGeneration
+	dst.Conditions = copyConditions(src.Conditions)
+	dst.Backend = nil
+	if src.Backend != nil {
+		dst.Backend = &v1beta1.ComponentStatus{
+			ObservedGeneration: src.Backend.ObservedGeneration,
+			Image:              src.Backend.Image,
+			Replicas:           src.Backend.Replicas,
+			AvailableReplicas:  src.Backend.AvailableReplicas,
+		}
+	}
+	dst.ConsolePlugin = nil
+	if src.ConsolePlugin != nil {
+		dst.ConsolePlugin = &v1beta1.ComponentStatus{
+			ObservedGeneration: src.ConsolePlugin.ObservedGeneration,
+			Image:              src.ConsolePlugin.Image,
+			Replicas:           src.ConsolePlugin.Replicas,
+			AvailableReplicas:  src.ConsolePlugin.AvailableReplicas,
+		}
+	}
+	dst.DefaultArgoCD = nil
+	if src.DefaultArgoCD != nil {
+		dst.DefaultArgoCD = &v1beta1.ArgoCDInstanceStatus{
+			ObservedGeneration: src.DefaultArgoCD.ObservedGeneration,
+			Name:               src.DefaultArgoCD.Name,
+			Namespace:          src.DefaultArgoCD.Namespace,
+			Phase:              src.DefaultArgoCD.Phase,
+		}
+	}
+}
+
+func convertStatusFromV1beta1(src *v1beta1.GitopsServiceStatus, dst *GitopsServiceStatus) {
+	dst.ObservedGeneration = src.ObservedGeneration
+	dst.Conditions = copyConditions(src.Conditions)
+	dst.Backend = nil
+	if src.Backend != nil {
+		dst.Backend = &ComponentStatus{
+			ObservedGeneration: src.Backend.ObservedGeneration,
+			Image:              src.Backend.Image,
+			Replicas:           src.Backend.Replicas,
+			AvailableReplicas:  src.Backend.AvailableReplicas,
+		}
+	}
+	dst.ConsolePlugin = nil
+	if src.ConsolePlugin != nil {
+		dst.ConsolePlugin = &ComponentStatus{
+			ObservedGeneration: src.ConsolePlugin.ObservedGeneration,
+			Image:              src.ConsolePlugin.Image,
+			Replicas:           src.ConsolePlugin.Replicas,
+			AvailableReplicas:  src.ConsolePlugin.AvailableReplicas,
+		}
+	}
+	dst.DefaultArgoCD = nil
+	if src.DefaultArgoCD != nil {
+		dst.DefaultArgoCD = &ArgoCDInstanceStatus{
+			ObservedGeneration: src.DefaultArgoCD.ObservedGeneration,
+			Name:               src.DefaultArgoCD.Name,
+			Namespace:          src.DefaultArgoCD.Namespace,
+			Phase:              src.DefaultArgoCD.Phase,
+		}
+	}
+}
+
+// mergeDefaultArgoCDPlacement replaces the placement of the stashed default Argo CD instance with the converted one,
+// keeping the settings v1alpha1 cannot represent
+func mergeDefaultArgoCDPlacement(stashed, converted *v1beta1.DefaultArgoCDSpec) *v1beta1.DefaultArgoCDSpec {
+	if stashed == nil {
+		return converted
+	}
+	merged := stashed.DeepCopy()
+	merged.NodeSelector, merged.Tolerations = nil, nil
+	if converted != nil {
+		merged.NodeSelector, merged.Tolerations = converted.NodeSelector, converted.Tolerations
+	}
+	return merged
+}
+
+// mergeBackendPlacement replaces the placement of the stashed backend with the converted one,
+// keeping the settings v1alpha1 cannot represent
+func mergeBackendPlacement(stashed, converted *v1beta1.BackendSpec) *v1beta1.BackendSpec {
+	if stashed == nil {
+		return converted
+	}
+	merged := stashed.DeepCopy()
+	merged.NodeSelector, merged.Tolerations = nil, nil
+	if converted != nil {
+		merged.NodeSelector, merged.Tolerations = converted.NodeSelector, converted.Tolerations
+	}
+	return merged
+}
+
+// mergeConsolePluginPlacement replaces the placement of the stashed console plugin with the converted one,
+// keeping the settings v1alpha1 cannot represent
+func mergeConsolePluginPlacement(stashed, converted *v1beta1.ConsolePluginSpec) *v1beta1.ConsolePluginSpec {
+	if stashed == nil {
+		return converted
+	}
+	merged := stashed.DeepCopy()
+	merged.NodeSelector, merged.Tolerations = nil, nil
+	if converted != nil {
+		merged.NodeSelector, merged.Tolerations = converted.NodeSelector, converted.Tolerations
+	}
+	return merged
+}
+
+func backendResources(spec *GitopsServiceSpec) *corev1.ResourceRequirements {
+	if spec.ConsolePlugin == nil || spec.ConsolePlugin.Backend == nil {
+		return nil
+	}
+	return spec.ConsolePlugin.Backend.Resources
+}
+
+func gitopsPluginResources(spec *GitopsServiceSpec) *corev1.ResourceRequirements {
+	if spec.ConsolePlugin == nil || spec.ConsolePlugin.GitopsPlugin == nil {
+		return nil
+	}
+	return spec.ConsolePlugin.GitopsPlugin.Resources
+}
+
+func copyNodeSelector(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for key, val := range in {
+		out[key] = val
+	}
+	return out
+}
+
+func copyTolerations(in []corev1.Toleration) []corev1.Toleration {
+	if in == nil {
+		return nil
+	}
+	out := make([]corev1.Toleration, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func copyConditions(in []metav1.Condition) []metav1.Condition {
+	if in == nil {
+		return nil
+	}
+	out := make([]metav1.Condition, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
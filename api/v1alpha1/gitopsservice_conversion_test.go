@@ -0,0 +1,145 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/redhat-developer/gitops-operator/api/v1beta1"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	resourcev1 "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testResources(memory string) *corev1.ResourceRequirements {
+	return &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resourcev1.MustParse(memory),
+		},
+	}
+}
+
+func testTolerations() []corev1.Toleration {
+	return []corev1.Toleration{
+		{
+			Key:      "key1",
+			Operator: corev1.TolerationOpEqual,
+			Value:    "value1",
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	}
+}
+
+func TestGitopsServiceConversion_v1alpha1RoundTrip(t *testing.T) {
+	src := &GitopsService{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: GitopsServiceSpec{
+			RunOnInfra:      true,
+			Tolerations:     testTolerations(),
+			NodeSelector:    map[string]string{"foo": "bar"},
+			ImagePullPolicy: corev1.PullAlways,
+			ConsolePlugin: &ConsolePluginStruct{
+				Backend:      &BackendStruct{Resources: testResources("128Mi")},
+				GitopsPlugin: &GitopsPluginStruct{Resources: testResources("256Mi")},
+			},
+		},
+		Status: GitopsServiceStatus{
+			ObservedGeneration: 2,
+			Backend:            &ComponentStatus{Image: "backend:latest", Replicas: 1, AvailableReplicas: 1},
+		},
+	}
+
+	hub := &v1beta1.GitopsService{}
+	assert.NilError(t, src.ConvertTo(hub))
+
+	assert.Equal(t, hub.Spec.RunOnInfra, true)
+	assert.Equal(t, hub.Spec.ImagePullPolicy, corev1.PullAlways)
+	assert.DeepEqual(t, hub.Spec.DefaultArgoCD.NodeSelector, src.Spec.NodeSelector)
+	assert.DeepEqual(t, hub.Spec.Backend.Tolerations, src.Spec.Tolerations)
+	assert.DeepEqual(t, hub.Spec.ConsolePlugin.NodeSelector, src.Spec.NodeSelector)
+	assert.DeepEqual(t, hub.Spec.Backend.Resources, testResources("128Mi"))
+	assert.DeepEqual(t, hub.Spec.ConsolePlugin.Resources, testResources("256Mi"))
+	assert.Equal(t, hub.Status.Backend.Image, "backend:latest")
+
+	dst := &GitopsService{}
+	assert.NilError(t, dst.ConvertFrom(hub))
+	assert.DeepEqual(t, dst.Spec, src.Spec)
+	assert.DeepEqual(t, dst.Status, src.Status)
+	_, stashed := dst.Annotations[V1beta1SpecAnnotation]
+	assert.Assert(t, !stashed, "spec representable in v1alpha1 must not be stashed")
+}
+
+func TestGitopsServiceConversion_v1beta1RoundTrip(t *testing.T) {
+	hub := &v1beta1.GitopsService{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: v1beta1.GitopsServiceSpec{
+			DefaultArgoCD: &v1beta1.DefaultArgoCDSpec{
+				NodeSelector: map[string]string{"argocd": "true"},
+			},
+			Backend: &v1beta1.BackendSpec{
+				Tolerations:     testTolerations(),
+				Resources:       testResources("128Mi"),
+				ImagePullPolicy: corev1.PullNever,
+			},
+			ConsoleLink: &v1beta1.ConsoleLinkSpec{Disabled: true},
+		},
+	}
+
+	spoke := &GitopsService{}
+	assert.NilError(t, spoke.ConvertFrom(hub))
+	assert.DeepEqual(t, spoke.Spec.NodeSelector, map[string]string{"argocd": "true"})
+	assert.DeepEqual(t, spoke.Spec.ConsolePlugin.Backend.Resources, testResources("128Mi"))
+	_, stashed := spoke.Annotations[V1beta1SpecAnnotation]
+	assert.Assert(t, stashed, "spec not representable in v1alpha1 must be stashed")
+
+	restored := &v1beta1.GitopsService{}
+	assert.NilError(t, spoke.ConvertTo(restored))
+	assert.DeepEqual(t, restored.Spec, hub.Spec)
+	assert.Assert(t, restored.Annotations == nil)
+}
+
+func TestGitopsServiceConversion_v1alpha1ChangesOverrideStash(t *testing.T) {
+	hub := &v1beta1.GitopsService{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: v1beta1.GitopsServiceSpec{
+			Backend: &v1beta1.BackendSpec{
+				Resources:       testResources("128Mi"),
+				ImagePullPolicy: corev1.PullNever,
+			},
+			ConsoleLink: &v1beta1.ConsoleLinkSpec{Disabled: true},
+		},
+	}
+
+	spoke := &GitopsService{}
+	assert.NilError(t, spoke.ConvertFrom(hub))
+
+	// Edit the object through v1alpha1
+	spoke.Spec.RunOnInfra = true
+	spoke.Spec.Tolerations = testTolerations()
+	spoke.Spec.ConsolePlugin.Backend.Resources = testResources("512Mi")
+
+	restored := &v1beta1.GitopsService{}
+	assert.NilError(t, spoke.ConvertTo(restored))
+	assert.Equal(t, restored.Spec.RunOnInfra, true)
+	assert.DeepEqual(t, restored.Spec.Backend.Resources, testResources("512Mi"))
+	assert.DeepEqual(t, restored.Spec.Backend.Tolerations, testTolerations())
+	assert.DeepEqual(t, restored.Spec.DefaultArgoCD.Tolerations, testTolerations())
+	// Settings v1alpha1 cannot represent are kept
+	assert.Equal(t, restored.Spec.Backend.ImagePullPolicy, corev1.PullNever)
+	assert.DeepEqual(t, restored.Spec.ConsoleLink, &v1beta1.ConsoleLinkSpec{Disabled: true})
+}
@@ -61,8 +61,43 @@ type GitopsPluginStruct struct {
 
 // GitopsServiceStatus defines the observed state of GitopsService
 type GitopsServiceStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the most recent generation of the GitopsService reconciled by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the current state of the GitopsService and the components it manages
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Backend reports the state of the backend Deployment
+	Backend *ComponentStatus `json:"backend,omitempty"`
+	// ConsolePlugin reports the state of the console plugin Deployment
+	ConsolePlugin *ComponentStatus `json:"consolePlugin,omitempty"`
+	// DefaultArgoCD reports the state of the default Argo CD instance
+	DefaultArgoCD *ArgoCDInstanceStatus `json:"defaultArgoCD,omitempty"`
+}
+
+// ComponentStatus defines the observed state of a workload managed by the GitopsService
+type ComponentStatus struct {
+	// ObservedGeneration is the generation of the GitopsService this status was computed from
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Image is the container image the component is running
+	Image string `json:"image,omitempty"`
+	// Replicas is the desired number of replicas of the component
+	Replicas int32 `json:"replicas,omitempty"`
+	// AvailableReplicas is the number of replicas of the component that are available
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+}
+
+// ArgoCDInstanceStatus defines the observed state of an Argo CD instance managed by the GitopsService
+type ArgoCDInstanceStatus struct {
+	// ObservedGeneration is the generation of the GitopsService this status was computed from
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Name is the name of the Argo CD instance
+	Name string `json:"name,omitempty"`
+	// Namespace is the namespace of the Argo CD instance
+	Namespace string `json:"namespace,omitempty"`
+	// Phase is the phase reported by the Argo CD instance
+	Phase string `json:"phase,omitempty"`
 }
 
 //+kubebuilder:object:root=true
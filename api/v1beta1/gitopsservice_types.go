@@ -0,0 +1,374 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// GitopsServiceSpec defines the desired state of GitopsService
+type GitopsServiceSpec struct {
+	// RunOnInfra will add infra NodeSelector to all the default workloads of gitops operator
+	RunOnInfra bool `json:"runOnInfra,omitempty"`
+	// ImagePullPolicy defines the image pull policy for GitOps workloads, unless overridden by a component
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// DefaultArgoCD defines the configuration of the default Argo CD instance
+	DefaultArgoCD *DefaultArgoCDSpec `json:"defaultArgoCD,omitempty"`
+	// Instances lists additional Argo CD instances created and kept in sync by the operator, like the default one
+	// +listType=map
+	// +listMapKey=namespace
+	// +listMapKey=name
+	// +optional
+	Instances []ArgoCDInstanceSpec `json:"instances,omitempty"`
+	// Backend defines the configuration of the backend service
+	Backend *BackendSpec `json:"backend,omitempty"`
+	// ConsolePlugin defines the configuration of the gitops console plugin
+	ConsolePlugin *ConsolePluginSpec `json:"consolePlugin,omitempty"`
+	// ConsoleLink defines the configuration of the Argo CD ConsoleLink
+	ConsoleLink *ConsoleLinkSpec `json:"consoleLink,omitempty"`
+	// NetworkPolicy defines the configuration of the NetworkPolicies restricting the ingress traffic of the backend
+	// and console plugin workloads
+	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
+	// Monitoring defines the configuration of the monitoring dashboards installed in the console
+	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+}
+
+// DefaultArgoCDProfile is a sizing profile for the default Argo CD instance
+// +kubebuilder:validation:Enum=small;medium;large
+type DefaultArgoCDProfile string
+
+const (
+	// DefaultArgoCDProfileSmall sizes the default Argo CD instance for edge and single node OpenShift clusters
+	DefaultArgoCDProfileSmall DefaultArgoCDProfile = "small"
+	// DefaultArgoCDProfileMedium is the sizing of the default Argo CD instance when no profile is set
+	DefaultArgoCDProfileMedium DefaultArgoCDProfile = "medium"
+	// DefaultArgoCDProfileLarge sizes the default Argo CD instance for large fleets, with HA mode and sharding enabled
+	DefaultArgoCDProfileLarge DefaultArgoCDProfile = "large"
+)
+
+// DefaultArgoCDManagementState defines how the operator manages the default Argo CD instance
+// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+type DefaultArgoCDManagementState string
+
+const (
+	// DefaultArgoCDManaged creates the default Argo CD instance and keeps it in sync with the GitopsService
+	DefaultArgoCDManaged DefaultArgoCDManagementState = "Managed"
+	// DefaultArgoCDUnmanaged releases the default Argo CD instance: it is left running but no longer reconciled
+	DefaultArgoCDUnmanaged DefaultArgoCDManagementState = "Unmanaged"
+	// DefaultArgoCDRemoved deletes the default Argo CD instance
+	DefaultArgoCDRemoved DefaultArgoCDManagementState = "Removed"
+)
+
+// DefaultArgoCDSpec defines the configuration of the default Argo CD instance
+type DefaultArgoCDSpec struct {
+	// ManagementState selects whether the operator manages, releases or deletes the default Argo CD instance.
+	// When not set, the instance is Removed if the DISABLE_DEFAULT_ARGOCD_INSTANCE environment variable of the
+	// operator is true, and Managed otherwise.
+	// +optional
+	ManagementState DefaultArgoCDManagementState `json:"managementState,omitempty"`
+	// Profile selects the resources, HA mode, controller sharding and repo-server replicas of the default Argo CD instance.
+	// Defaults to medium.
+	// +optional
+	Profile DefaultArgoCDProfile `json:"profile,omitempty"`
+	// NodeSelector is a map of key value pairs used for node selection in the default Argo CD workloads
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations allow the default Argo CD workloads to schedule onto nodes with matching taints
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// ResourceExclusions adds entries to, or removes kinds from, the resource exclusions the operator
+	// configures on the default Argo CD instance
+	// +optional
+	ResourceExclusions *ResourceExclusionsSpec `json:"resourceExclusions,omitempty"`
+	// Overrides is a strategic merge patch of the ArgoCD spec, applied on top of the default Argo CD instance
+	// when it is created and re-applied on every reconcile
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Overrides *runtime.RawExtension `json:"overrides,omitempty"`
+}
+
+// ArgoCDInstanceSpec defines an additional Argo CD instance managed by the operator
+type ArgoCDInstanceSpec struct {
+	// Name is the name of the Argo CD instance
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace is the namespace of the Argo CD instance, created if it does not exist
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// ClusterConfig grants the Argo CD instance the privileges to manage cluster configuration, like the
+	// instances in the namespaces listed in the ARGOCD_CLUSTER_CONFIG_NAMESPACES environment variable of the operator
+	// +optional
+	ClusterConfig bool `json:"clusterConfig,omitempty"`
+	// DefaultArgoCDSpec configures the instance like spec.defaultArgoCD configures the default Argo CD instance.
+	// When managementState is not set, the instance is Managed.
+	DefaultArgoCDSpec `json:",inline"`
+}
+
+// ResourceExclusionsSpec defines changes to the default resource exclusions of the default Argo CD instance
+type ResourceExclusionsSpec struct {
+	// Add lists resource exclusions configured in addition to the operator defaults
+	Add []ResourceExclusion `json:"add,omitempty"`
+	// Remove lists kinds that are no longer excluded by the operator defaults. A kind is removed from every
+	// default entry that has one of the given API groups, or from every default entry if no API group is given.
+	Remove []ResourceExclusion `json:"remove,omitempty"`
+}
+
+// ResourceExclusion defines a set of resources Argo CD does not discover or sync
+type ResourceExclusion struct {
+	// APIGroups are the API groups of the excluded resources
+	APIGroups []string `json:"apiGroups,omitempty"`
+	// Kinds are the kinds of the excluded resources
+	Kinds []string `json:"kinds,omitempty"`
+	// Clusters are the clusters the exclusion applies to
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// BackendSpec defines the configuration of the backend service
+type BackendSpec struct {
+	// NodeSelector is a map of key value pairs used for node selection in the backend workload
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations allow the backend workload to schedule onto nodes with matching taints
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Resources defines the resource requests and limits for the backend service
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// ImagePullPolicy defines the image pull policy for the backend service
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Replicas is the number of pods of the backend workload, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Affinity defines the scheduling constraints of the backend pods. When neither affinity nor topology spread
+	// constraints are set, the replicas are preferably scheduled on different nodes.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// TopologySpreadConstraints describe how the backend pods are spread across the topology domains of the cluster
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// PodTemplateOverrides are merged into the pod template of the backend workload
+	PodTemplateOverrides *PodTemplateOverrides `json:"podTemplateOverrides,omitempty"`
+	// RBACScope defines where the backend service can read Applications and Secrets. Cluster, the default, grants
+	// access in every namespace. Namespaced only grants access in the namespaces of the Argo CD instances, their
+	// source namespaces and the namespaces they manage.
+	// +kubebuilder:validation:Enum=Cluster;Namespaced
+	RBACScope BackendRBACScope `json:"rbacScope,omitempty"`
+}
+
+// BackendRBACScope defines where the backend service is granted access
+type BackendRBACScope string
+
+const (
+	// BackendRBACScopeCluster grants access to the backend service with a ClusterRole
+	BackendRBACScopeCluster BackendRBACScope = "Cluster"
+	// BackendRBACScopeNamespaced grants access to the backend service with a Role in each namespace used by Argo CD
+	BackendRBACScopeNamespaced BackendRBACScope = "Namespaced"
+)
+
+// ConsolePluginSpec defines the configuration of the gitops console plugin
+type ConsolePluginSpec struct {
+	// NodeSelector is a map of key value pairs used for node selection in the console plugin workload
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations allow the console plugin workload to schedule onto nodes with matching taints
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Resources defines the resource requests and limits for the gitops plugin service
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// ImagePullPolicy defines the image pull policy for the gitops plugin service
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Replicas is the number of pods of the console plugin workload, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Affinity defines the scheduling constraints of the console plugin pods. When neither affinity nor topology
+	// spread constraints are set, the replicas are preferably scheduled on different nodes.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// TopologySpreadConstraints describe how the console plugin pods are spread across the topology domains of
+	// the cluster
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// PodTemplateOverrides are merged into the pod template of the console plugin workload
+	PodTemplateOverrides *PodTemplateOverrides `json:"podTemplateOverrides,omitempty"`
+}
+
+// PodTemplateOverrides defines additions to the pod template generated by the operator for a workload. The values
+// generated by the operator take precedence over the labels and annotations set here, and the workload container
+// cannot be replaced by a sidecar.
+type PodTemplateOverrides struct {
+	// Labels are added to the pods of the workload
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to the pods of the workload
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// PriorityClassName is the name of the priority class of the pods of the workload
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Env lists environment variables set in the workload container, replacing the variables of the same name set
+	// by the operator
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// VolumeMounts lists volumes mounted in the workload container, in addition to the volumes mounted by the operator
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// Volumes lists volumes added to the pods of the workload, replacing the volumes of the same name set by the
+	// operator
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+	// Sidecars lists containers run in the pods of the workload next to the workload container
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+}
+
+// ConsoleLinkSpec defines the configuration of the Argo CD ConsoleLink
+type ConsoleLinkSpec struct {
+	// Disabled removes the ConsoleLink to the default Argo CD instance from the OpenShift console
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// NetworkPolicySpec defines the configuration of the NetworkPolicies of the backend and console plugin workloads
+type NetworkPolicySpec struct {
+	// Disabled removes the NetworkPolicies, the backend and console plugin workloads then accept traffic from every
+	// pod of the cluster
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// MonitoringSpec defines the configuration of the monitoring dashboards installed in the console
+type MonitoringSpec struct {
+	// Dashboards sets the state of the dashboards embedded in the operator, by name. The dashboards that are not
+	// listed are managed by the operator.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Dashboards []DashboardSpec `json:"dashboards,omitempty"`
+	// ExtraDashboardNamespaces lists the namespaces, in addition to openshift-gitops, whose ConfigMaps labelled
+	// pipelines.openshift.io/dashboard are published as dashboards in the console
+	// +listType=set
+	// +optional
+	ExtraDashboardNamespaces []string `json:"extraDashboardNamespaces,omitempty"`
+}
+
+// DashboardSpec defines how the operator manages a dashboard embedded in the operator
+type DashboardSpec struct {
+	// Name of the dashboard
+	// +kubebuilder:validation:Enum=gitops-overview;gitops-components;gitops-grpc;gitops-rollouts
+	Name string `json:"name"`
+	// State selects whether the operator installs and updates the dashboard, leaves it unchanged or deletes it.
+	// Defaults to Managed.
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Absent
+	State DashboardState `json:"state,omitempty"`
+}
+
+// DashboardState defines how the operator manages a dashboard
+type DashboardState string
+
+const (
+	// DashboardManaged dashboards are installed and kept up to date by the operator
+	DashboardManaged DashboardState = "Managed"
+	// DashboardUnmanaged dashboards are neither installed, updated nor deleted by the operator, so that they can be
+	// changed or replaced
+	DashboardUnmanaged DashboardState = "Unmanaged"
+	// DashboardAbsent dashboards are deleted by the operator
+	DashboardAbsent DashboardState = "Absent"
+)
+
+// Condition types reported in GitopsServiceStatus.Conditions
+const (
+	// ConditionReady is True when every component managed by the operator is available
+	ConditionReady = "Ready"
+	// ConditionBackendAvailable is True when the backend Deployment has all of its replicas available
+	ConditionBackendAvailable = "BackendAvailable"
+	// ConditionConsolePluginAvailable is True when the console plugin Deployment has all of its replicas available
+	ConditionConsolePluginAvailable = "ConsolePluginAvailable"
+	// ConditionDefaultArgoCDAvailable is True when the default Argo CD instance reports the Available phase
+	ConditionDefaultArgoCDAvailable = "DefaultArgoCDAvailable"
+	// ConditionDegraded is True when the last reconciliation of the GitopsService failed
+	ConditionDegraded = "Degraded"
+	// ConditionDefaultArgoCDConflict is True when fields of the default Argo CD instance set by the operator are
+	// owned by another field manager, and the values of the other manager are kept
+	ConditionDefaultArgoCDConflict = "DefaultArgoCDConflict"
+)
+
+// Condition reasons reported in GitopsServiceStatus.Conditions
+const (
+	ReasonAvailable          = "Available"
+	ReasonUnavailable        = "Unavailable"
+	ReasonNotFound           = "NotFound"
+	ReasonDisabled           = "Disabled"
+	ReasonNotSupported       = "NotSupported"
+	ReasonReconcileFailed    = "ReconcileFailed"
+	ReasonReconcileSucceeded = "ReconcileSucceeded"
+	ReasonFieldConflict      = "FieldConflict"
+	ReasonNoConflict         = "NoConflict"
+	ReasonUnmanaged          = "Unmanaged"
+)
+
+// GitopsServiceStatus defines the observed state of GitopsService
+type GitopsServiceStatus struct {
+	// ObservedGeneration is the most recent generation of the GitopsService reconciled by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the current state of the GitopsService and the components it manages
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Backend reports the state of the backend Deployment
+	Backend *ComponentStatus `json:"backend,omitempty"`
+	// ConsolePlugin reports the state of the console plugin Deployment
+	ConsolePlugin *ComponentStatus `json:"consolePlugin,omitempty"`
+	// DefaultArgoCD reports the state of the default Argo CD instance
+	DefaultArgoCD *ArgoCDInstanceStatus `json:"defaultArgoCD,omitempty"`
+}
+
+// ComponentStatus defines the observed state of a workload managed by the GitopsService
+type ComponentStatus struct {
+	// ObservedGeneration is the generation of the GitopsService this status was computed from
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Image is the container image the component is running
+	Image string `json:"image,omitempty"`
+	// Replicas is the desired number of replicas of the component
+	Replicas int32 `json:"replicas,omitempty"`
+	// AvailableReplicas is the number of replicas of the component that are available
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+}
+
+// ArgoCDInstanceStatus defines the observed state of an Argo CD instance managed by the GitopsService
+type ArgoCDInstanceStatus struct {
+	// ObservedGeneration is the generation of the GitopsService this status was computed from
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Name is the name of the Argo CD instance
+	Name string `json:"name,omitempty"`
+	// Namespace is the namespace of the Argo CD instance
+	Namespace string `json:"namespace,omitempty"`
+	// Phase is the phase reported by the Argo CD instance
+	Phase string `json:"phase,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:storageversion
+
+// GitopsService is the Schema for the gitopsservices API
+type GitopsService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitopsServiceSpec   `json:"spec,omitempty"`
+	Status GitopsServiceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GitopsServiceList contains a list of GitopsService
+type GitopsServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitopsService `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitopsService{}, &GitopsServiceList{})
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/redhat-developer/gitops-operator/common"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the conversion and validating webhooks for GitopsService with the manager.
+func (r *GitopsService) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, r).
+		WithValidator(&gitopsServiceValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-pipelines-openshift-io-v1beta1-gitopsservice,mutating=false,failurePolicy=fail,sideEffects=None,groups=pipelines.openshift.io,resources=gitopsservices,verbs=create;update,versions=v1beta1,name=vgitopsservice.kb.io,admissionReviewVersions=v1
+
+// gitopsServiceValidator rejects GitopsServices the operator would not reconcile, or would reconcile into broken workloads.
+type gitopsServiceValidator struct{}
+
+var _ admission.Validator[*GitopsService] = &gitopsServiceValidator{}
+
+// ValidateCreate implements admission.Validator
+func (v *gitopsServiceValidator) ValidateCreate(_ context.Context, obj *GitopsService) (admission.Warnings, error) {
+	return nil, obj.validate()
+}
+
+// ValidateUpdate implements admission.Validator
+func (v *gitopsServiceValidator) ValidateUpdate(_ context.Context, _, newObj *GitopsService) (admission.Warnings, error) {
+	return nil, newObj.validate()
+}
+
+// ValidateDelete implements admission.Validator
+func (v *gitopsServiceValidator) ValidateDelete(_ context.Context, _ *GitopsService) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *GitopsService) validate() error {
+	var allErrs field.ErrorList
+
+	if r.Name != common.GitopsServiceName {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), r.Name,
+			fmt.Sprintf("only a single GitopsService named %q is supported", common.GitopsServiceName)))
+	}
+
+	specPath := field.NewPath("spec")
+	if argocd := r.Spec.DefaultArgoCD; argocd != nil {
+		path := specPath.Child("defaultArgoCD")
+		allErrs = append(allErrs, validateNodeSelector(argocd.NodeSelector, r.Spec.RunOnInfra, path.Child("nodeSelector"))...)
+		allErrs = append(allErrs, validateTolerations(argocd.Tolerations, path.Child("tolerations"))...)
+		allErrs = append(allErrs, validateResourceExclusions(argocd.ResourceExclusions, path.Child("resourceExclusions"))...)
+		allErrs = append(allErrs, validateOverrides(argocd.Overrides, path.Child("overrides"))...)
+	}
+	allErrs = append(allErrs, validateInstances(r.Spec.Instances, r.Spec.RunOnInfra, specPath.Child("instances"))...)
+	if backend := r.Spec.Backend; backend != nil {
+		path := specPath.Child("backend")
+		allErrs = append(allErrs, validateNodeSelector(backend.NodeSelector, r.Spec.RunOnInfra, path.Child("nodeSelector"))...)
+		allErrs = append(allErrs, validateTolerations(backend.Tolerations, path.Child("tolerations"))...)
+		allErrs = append(allErrs, validateResources(backend.Resources, path.Child("resources"))...)
+		allErrs = append(allErrs, validatePodTemplateOverrides(backend.PodTemplateOverrides, path.Child("podTemplateOverrides"))...)
+	}
+	if plugin := r.Spec.ConsolePlugin; plugin != nil {
+		path := specPath.Child("consolePlugin")
+		allErrs = append(allErrs, validateNodeSelector(plugin.NodeSelector, r.Spec.RunOnInfra, path.Child("nodeSelector"))...)
+		allErrs = append(allErrs, validateTolerations(plugin.Tolerations, path.Child("tolerations"))...)
+		allErrs = append(allErrs, validateResources(plugin.Resources, path.Child("resources"))...)
+		allErrs = append(allErrs, validatePodTemplateOverrides(plugin.PodTemplateOverrides, path.Child("podTemplateOverrides"))...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "GitopsService"}, r.Name, allErrs)
+}
+
+// validateInstances rejects Argo CD instances that cannot be reconciled: instances in the namespace of the default
+// instance, which is configured by spec.defaultArgoCD, and several instances in the same namespace, which the Argo CD
+// operator does not support.
+func validateInstances(instances []ArgoCDInstanceSpec, runOnInfra bool, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	namespaces := map[string]bool{}
+	for i, instance := range instances {
+		idxPath := path.Index(i)
+		for _, msg := range validation.IsDNS1123Subdomain(instance.Name) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), instance.Name, msg))
+		}
+		for _, msg := range validation.IsDNS1123Label(instance.Namespace) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("namespace"), instance.Namespace, msg))
+		}
+		if instance.Namespace == common.ArgoCDDefaultNamespace {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("namespace"), "the namespace of the default Argo CD instance, configured by spec.defaultArgoCD"))
+		}
+		if namespaces[instance.Namespace] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("namespace"), instance.Namespace))
+		}
+		namespaces[instance.Namespace] = true
+		allErrs = append(allErrs, validateNodeSelector(instance.NodeSelector, runOnInfra, idxPath.Child("nodeSelector"))...)
+		allErrs = append(allErrs, validateTolerations(instance.Tolerations, idxPath.Child("tolerations"))...)
+		allErrs = append(allErrs, validateResourceExclusions(instance.ResourceExclusions, idxPath.Child("resourceExclusions"))...)
+		allErrs = append(allErrs, validateOverrides(instance.Overrides, idxPath.Child("overrides"))...)
+	}
+	return allErrs
+}
+
+// validateNodeSelector rejects node selectors that can never be satisfied together with the infra node selector added by runOnInfra.
+func validateNodeSelector(nodeSelector map[string]string, runOnInfra bool, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, key := range sortedKeys(nodeSelector) {
+		value := nodeSelector[key]
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(path.Key(key), key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			allErrs = append(allErrs, field.Invalid(path.Key(key), value, msg))
+		}
+		if runOnInfra && key == common.InfraNodeLabelSelector && value != "" {
+			allErrs = append(allErrs, field.Invalid(path.Key(key), value,
+				fmt.Sprintf("conflicts with runOnInfra, which requires %s to be empty", common.InfraNodeLabelSelector)))
+		}
+	}
+	return allErrs
+}
+
+// validateTolerations mirrors the checks the API server applies to pod tolerations, so that invalid
+// tolerations are rejected on the GitopsService instead of failing when the workloads are updated.
+func validateTolerations(tolerations []corev1.Toleration, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, toleration := range tolerations {
+		idxPath := path.Index(i)
+
+		if toleration.Key != "" {
+			for _, msg := range validation.IsQualifiedName(toleration.Key) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("key"), toleration.Key, msg))
+			}
+		}
+
+		switch toleration.Operator {
+		case corev1.TolerationOpEqual, "":
+			if toleration.Key == "" {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("operator"), toleration.Operator,
+					"operator must be Exists when key is empty"))
+			}
+			for _, msg := range validation.IsValidLabelValue(toleration.Value) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("value"), toleration.Value, msg))
+			}
+		case corev1.TolerationOpExists:
+			if toleration.Value != "" {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("value"), toleration.Value,
+					"value must be empty when operator is Exists"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("operator"), toleration.Operator,
+				[]string{string(corev1.TolerationOpEqual), string(corev1.TolerationOpExists)}))
+		}
+
+		switch toleration.Effect {
+		case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("effect"), toleration.Effect,
+				[]string{string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute)}))
+		}
+
+		if toleration.TolerationSeconds != nil && toleration.Effect != corev1.TaintEffectNoExecute {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("tolerationSeconds"), *toleration.TolerationSeconds,
+				"tolerationSeconds can only be set when effect is NoExecute"))
+		}
+	}
+	return allErrs
+}
+
+// validateResourceExclusions rejects exclusions that would match every kind, which is never intended
+func validateResourceExclusions(exclusions *ResourceExclusionsSpec, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if exclusions == nil {
+		return allErrs
+	}
+	for i, exclusion := range exclusions.Add {
+		if len(exclusion.Kinds) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("add").Index(i).Child("kinds"), "at least one kind must be excluded"))
+		}
+	}
+	for i, exclusion := range exclusions.Remove {
+		if len(exclusion.Kinds) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("remove").Index(i).Child("kinds"), "at least one kind must be removed"))
+		}
+	}
+	return allErrs
+}
+
+// validateOverrides rejects overrides the operator cannot apply to the ArgoCD spec, like unknown fields, decoded
+// strictly as on reconcile so that typos are reported at admission
+func validateOverrides(overrides *runtime.RawExtension, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if overrides == nil || len(overrides.Raw) == 0 {
+		return allErrs
+	}
+	patched, err := strategicpatch.StrategicMergePatch([]byte("{}"), overrides.Raw, argoapp.ArgoCDSpec{})
+	if err != nil {
+		return append(allErrs, field.Invalid(path, string(overrides.Raw), err.Error()))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&argoapp.ArgoCDSpec{}); err != nil {
+		allErrs = append(allErrs, field.Invalid(path, string(overrides.Raw), err.Error()))
+	}
+	return allErrs
+}
+
+// validateResources rejects resource requests that exceed their limits, which the API server would refuse on the Deployment.
+func validateResources(resources *corev1.ResourceRequirements, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if resources == nil {
+		return allErrs
+	}
+	names := make([]string, 0, len(resources.Requests))
+	for name := range resources.Requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		request := resources.Requests[corev1.ResourceName(name)]
+		limit, ok := resources.Limits[corev1.ResourceName(name)]
+		if ok && request.Cmp(limit) > 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("requests").Key(name), request.String(),
+				fmt.Sprintf("must be less than or equal to %s limit of %s", name, limit.String())))
+		}
+	}
+	return allErrs
+}
+
+// validatePodTemplateOverrides rejects labels, annotations, volumes and sidecars the API server would refuse on the pod template.
+func validatePodTemplateOverrides(overrides *PodTemplateOverrides, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if overrides == nil {
+		return allErrs
+	}
+	for _, key := range sortedKeys(overrides.Labels) {
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(path.Child("labels").Key(key), key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(overrides.Labels[key]) {
+			allErrs = append(allErrs, field.Invalid(path.Child("labels").Key(key), overrides.Labels[key], msg))
+		}
+	}
+	for _, key := range sortedKeys(overrides.Annotations) {
+		for _, msg := range validation.IsQualifiedName(strings.ToLower(key)) {
+			allErrs = append(allErrs, field.Invalid(path.Child("annotations").Key(key), key, msg))
+		}
+	}
+	volumes := map[string]bool{}
+	for i, volume := range overrides.Volumes {
+		for _, msg := range validation.IsDNS1123Label(volume.Name) {
+			allErrs = append(allErrs, field.Invalid(path.Child("volumes").Index(i).Child("name"), volume.Name, msg))
+		}
+		if volumes[volume.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("volumes").Index(i).Child("name"), volume.Name))
+		}
+		volumes[volume.Name] = true
+	}
+	sidecars := map[string]bool{}
+	for i, sidecar := range overrides.Sidecars {
+		for _, msg := range validation.IsDNS1123Label(sidecar.Name) {
+			allErrs = append(allErrs, field.Invalid(path.Child("sidecars").Index(i).Child("name"), sidecar.Name, msg))
+		}
+		if sidecars[sidecar.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("sidecars").Index(i).Child("name"), sidecar.Name))
+		}
+		sidecars[sidecar.Name] = true
+		if sidecar.Image == "" {
+			allErrs = append(allErrs, field.Required(path.Child("sidecars").Index(i).Child("image"), ""))
+		}
+	}
+	return allErrs
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
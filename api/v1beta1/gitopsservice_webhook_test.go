@@ -0,0 +1,245 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	resourcev1 "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGitopsServiceValidator(t *testing.T) {
+	tolerationSeconds := int64(60)
+
+	tests := []struct {
+		name    string
+		service *GitopsService
+		wantErr string
+	}{
+		{
+			name: "valid",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				RunOnInfra: true,
+				DefaultArgoCD: &DefaultArgoCDSpec{
+					NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+					Tolerations: []corev1.Toleration{
+						{Key: "infra", Value: "reserved", Effect: corev1.TaintEffectNoSchedule},
+						{Operator: corev1.TolerationOpExists},
+						{Key: "infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute, TolerationSeconds: &tolerationSeconds},
+					},
+				},
+				Backend: &BackendSpec{
+					Resources: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resourcev1.MustParse("128Mi")},
+						Limits:   corev1.ResourceList{corev1.ResourceMemory: resourcev1.MustParse("256Mi")},
+					},
+					PodTemplateOverrides: &PodTemplateOverrides{
+						Labels:      map[string]string{"sidecar.istio.io/inject": "true"},
+						Annotations: map[string]string{"proxy.istio.io/config": "{}"},
+						Volumes:     []corev1.Volume{{Name: "trusted-ca"}},
+						Sidecars:    []corev1.Container{{Name: "proxy", Image: "registry.example.com/proxy:1.0"}},
+					},
+				},
+			}),
+		},
+		{
+			name: "valid instances",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				Instances: []ArgoCDInstanceSpec{
+					{Name: "cluster-config", Namespace: "cluster-config", ClusterConfig: true},
+					{Name: "tenants", Namespace: "tenants", DefaultArgoCDSpec: DefaultArgoCDSpec{Profile: DefaultArgoCDProfileSmall}},
+				},
+			}),
+		},
+		{
+			name: "valid overrides",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				DefaultArgoCD: &DefaultArgoCDSpec{
+					Overrides: &runtime.RawExtension{Raw: []byte(`{"server": {"replicas": 2}, "ha": {"enabled": false}}`)},
+				},
+			}),
+		},
+		{
+			name: "unknown override field",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				DefaultArgoCD: &DefaultArgoCDSpec{
+					Overrides: &runtime.RawExtension{Raw: []byte(`{"server": {"replica": 2}}`)},
+				},
+			}),
+			wantErr: `spec.defaultArgoCD.overrides: Invalid value: "{\"server\": {\"replica\": 2}}": json: unknown field "replica"`,
+		},
+		{
+			name: "invalid override of an instance",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				Instances: []ArgoCDInstanceSpec{{Name: "tenants", Namespace: "tenants", DefaultArgoCDSpec: DefaultArgoCDSpec{
+					Overrides: &runtime.RawExtension{Raw: []byte(`{"server": {"replicas": "two"}}`)},
+				}}},
+			}),
+			wantErr: `spec.instances[0].overrides: Invalid value`,
+		},
+		{
+			name:    "second instance",
+			service: newGitopsService("other", GitopsServiceSpec{}),
+			wantErr: `metadata.name: Invalid value: "other": only a single GitopsService named "cluster" is supported`,
+		},
+		{
+			name: "requests exceed limits",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				ConsolePlugin: &ConsolePluginSpec{
+					Resources: &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resourcev1.MustParse("500m")},
+						Limits:   corev1.ResourceList{corev1.ResourceCPU: resourcev1.MustParse("250m")},
+					},
+				},
+			}),
+			wantErr: `spec.consolePlugin.resources.requests[cpu]: Invalid value: "500m": must be less than or equal to cpu limit of 250m`,
+		},
+		{
+			name: "unsupported toleration operator",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				Backend: &BackendSpec{
+					Tolerations: []corev1.Toleration{{Key: "infra", Operator: "In"}},
+				},
+			}),
+			wantErr: `spec.backend.tolerations[0].operator: Unsupported value: "In"`,
+		},
+		{
+			name: "toleration without key must use Exists",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				DefaultArgoCD: &DefaultArgoCDSpec{
+					Tolerations: []corev1.Toleration{{Value: "reserved"}},
+				},
+			}),
+			wantErr: `spec.defaultArgoCD.tolerations[0].operator: Invalid value: "": operator must be Exists when key is empty`,
+		},
+		{
+			name: "tolerationSeconds without NoExecute",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				DefaultArgoCD: &DefaultArgoCDSpec{
+					Tolerations: []corev1.Toleration{{Key: "infra", Effect: corev1.TaintEffectNoSchedule, TolerationSeconds: &tolerationSeconds}},
+				},
+			}),
+			wantErr: `spec.defaultArgoCD.tolerations[0].tolerationSeconds: Invalid value: 60: tolerationSeconds can only be set when effect is NoExecute`,
+		},
+		{
+			name: "resource exclusion without kinds",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				DefaultArgoCD: &DefaultArgoCDSpec{
+					ResourceExclusions: &ResourceExclusionsSpec{
+						Remove: []ResourceExclusion{{APIGroups: []string{"tekton.dev"}}},
+					},
+				},
+			}),
+			wantErr: `spec.defaultArgoCD.resourceExclusions.remove[0].kinds: Required value: at least one kind must be removed`,
+		},
+		{
+			name: "node selector conflicts with runOnInfra",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				RunOnInfra: true,
+				Backend: &BackendSpec{
+					NodeSelector: map[string]string{"node-role.kubernetes.io/infra": "false"},
+				},
+			}),
+			wantErr: `spec.backend.nodeSelector[node-role.kubernetes.io/infra]: Invalid value: "false": conflicts with runOnInfra`,
+		},
+		{
+			name: "invalid pod template label",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				ConsolePlugin: &ConsolePluginSpec{
+					PodTemplateOverrides: &PodTemplateOverrides{Labels: map[string]string{"sidecar.istio.io/inject": "yes please"}},
+				},
+			}),
+			wantErr: `spec.consolePlugin.podTemplateOverrides.labels[sidecar.istio.io/inject]: Invalid value: "yes please"`,
+		},
+		{
+			name: "duplicate sidecar",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				Backend: &BackendSpec{
+					PodTemplateOverrides: &PodTemplateOverrides{
+						Sidecars: []corev1.Container{
+							{Name: "proxy", Image: "registry.example.com/proxy:1.0"},
+							{Name: "proxy", Image: "registry.example.com/proxy:1.1"},
+						},
+					},
+				},
+			}),
+			wantErr: `spec.backend.podTemplateOverrides.sidecars[1].name: Duplicate value: "proxy"`,
+		},
+		{
+			name: "sidecar without image",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				Backend: &BackendSpec{
+					PodTemplateOverrides: &PodTemplateOverrides{Sidecars: []corev1.Container{{Name: "proxy"}}},
+				},
+			}),
+			wantErr: `spec.backend.podTemplateOverrides.sidecars[0].image: Required value`,
+		},
+		{
+			name: "instance in the namespace of the default instance",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				Instances: []ArgoCDInstanceSpec{{Name: "tenants", Namespace: "openshift-gitops"}},
+			}),
+			wantErr: `spec.instances[0].namespace: Forbidden: the namespace of the default Argo CD instance`,
+		},
+		{
+			name: "instances in the same namespace",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				Instances: []ArgoCDInstanceSpec{
+					{Name: "cluster-config", Namespace: "argocd"},
+					{Name: "tenants", Namespace: "argocd"},
+				},
+			}),
+			wantErr: `spec.instances[1].namespace: Duplicate value: "argocd"`,
+		},
+		{
+			name: "invalid instance namespace",
+			service: newGitopsService("cluster", GitopsServiceSpec{
+				Instances: []ArgoCDInstanceSpec{{Name: "tenants", Namespace: "Tenants"}},
+			}),
+			wantErr: `spec.instances[0].namespace: Invalid value: "Tenants"`,
+		},
+	}
+
+	validator := &gitopsServiceValidator{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, createErr := validator.ValidateCreate(context.TODO(), test.service)
+			_, updateErr := validator.ValidateUpdate(context.TODO(), newGitopsService("cluster", GitopsServiceSpec{}), test.service)
+			for _, err := range []error{createErr, updateErr} {
+				if test.wantErr == "" {
+					assert.NilError(t, err)
+					continue
+				}
+				assert.Assert(t, apierrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+				assert.ErrorContains(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func newGitopsService(name string, spec GitopsServiceSpec) *GitopsService {
+	return &GitopsService{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -67,6 +68,7 @@ import (
 
 	tlspkg "github.com/openshift/controller-runtime-common/pkg/tls"
 	pipelinesv1alpha1 "github.com/redhat-developer/gitops-operator/api/v1alpha1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	"github.com/redhat-developer/gitops-operator/common"
 	"github.com/redhat-developer/gitops-operator/controllers"
 	"github.com/redhat-developer/gitops-operator/controllers/argocd/openshift"
@@ -88,6 +90,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(pipelinesv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(pipelinesv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -99,6 +102,9 @@ func main() {
 	var enableHTTP2 = false
 	var skipControllerNameValidation = true
 	var disableClusterTLSProfile = false
+	var apiDiscoveryInterval time.Duration
+	var prometheusConfig controllers.PrometheusConfig
+	var serviceMonitorLabels, namespaceMonitoring string
 
 	var labelSelectorFlag string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -110,6 +116,22 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", enableHTTP2, "If HTTP/2 should be enabled for the metrics and webhook servers.")
 	flag.BoolVar(&disableClusterTLSProfile, "disable-cluster-tls-profile", false, "Disable use of the cluster TLS security profile")
 	flag.BoolVar(&secureMetrics, "metrics-secure", secureMetrics, "If the metrics endpoint should be served securely.")
+	flag.DurationVar(&apiDiscoveryInterval, "api-discovery-interval", time.Minute,
+		"How often the cluster is inspected for the optional APIs installed after the operator started, like the console or the Prometheus operator. "+
+			"Set to 0 to only inspect the cluster at startup.")
+	flag.StringVar(&prometheusConfig.ServiceAccount, "prometheus-service-account",
+		common.StringFromEnv(common.PrometheusServiceAccountEnvVar, controllers.DefaultPrometheusServiceAccount),
+		"The ServiceAccount of the Prometheus granted read access to the namespaces of the Argo CD instances.")
+	flag.StringVar(&prometheusConfig.Namespace, "prometheus-namespace",
+		common.StringFromEnv(common.PrometheusNamespaceEnvVar, controllers.DefaultPrometheusNamespace),
+		"The namespace of the Prometheus granted read access to the namespaces of the Argo CD instances.")
+	flag.StringVar(&serviceMonitorLabels, "service-monitor-labels",
+		common.StringFromEnv(common.ServiceMonitorLabelsEnvVar, controllers.DefaultServiceMonitorLabels),
+		"The labels of the ServiceMonitors of the Argo CD instances, as key=value pairs separated by commas, matching the ServiceMonitor selector of the Prometheus.")
+	flag.StringVar(&namespaceMonitoring, "namespace-monitoring",
+		common.StringFromEnv(common.NamespaceMonitoringEnvVar, string(controllers.NamespaceMonitoringAuto)),
+		"The label enabling the monitoring of the namespaces of the Argo CD instances: "+
+			"auto for the cluster monitoring of the openshift- namespaces and the user workload monitoring of the others, cluster, user, or none to leave the namespaces unlabelled.")
 
 	//Configure log level
 	logLevelStr := strings.ToLower(os.Getenv("LOG_LEVEL"))
@@ -250,99 +272,111 @@ func main() {
 			os.Exit(1)
 		}
 	}
-	// Setup Scheme for OpenShift Console if available (verified by InspectCluster)
-	if util.IsConsoleAPIFound() {
-		registerComponentOrExit(mgr, console.AddToScheme)
-	}
-
-	// Setup Scheme for OpenShift Route if available (verified by InspectCluster)
-	if util.IsRouteAPIFound() {
-		registerComponentOrExit(mgr, routev1.AddToScheme)
-	}
-
-	// Setup Scheme for Prometheus Operator if available (verified by InspectCluster)
-	if util.IsMonitoringAPIFound() {
-		registerComponentOrExit(mgr, monitoringv1.AddToScheme)
-	}
-
-	// Setup Scheme for OLM if available (verified by InspectCluster)
-	if util.IsOLMAPIFound() {
-		registerComponentOrExit(mgr, operatorsv1.AddToScheme)
-		registerComponentOrExit(mgr, operatorsv1alpha1.AddToScheme)
-	}
-
+	// The schemes of the optional APIs are registered whether the APIs are available or not, the scheme of the
+	// manager must not be mutated once it started
+	registerComponentOrExit(mgr, console.AddToScheme)
+	registerComponentOrExit(mgr, routev1.AddToScheme)
+	registerComponentOrExit(mgr, monitoringv1.AddToScheme)
+	registerComponentOrExit(mgr, operatorsv1.AddToScheme)
+	registerComponentOrExit(mgr, operatorsv1alpha1.AddToScheme)
 	registerComponentOrExit(mgr, argov1alpha1api.AddToScheme)
 	registerComponentOrExit(mgr, argov1beta1api.AddToScheme)
-
-	// Setup Scheme for OpenShift Config if available
-	// Disables default Argo CD instance if the cluster doesn't contain OpenShift config API
-	if util.IsConfigAPIFound() {
-		registerComponentOrExit(mgr, configv1.AddToScheme)
-	}
-
+	registerComponentOrExit(mgr, configv1.AddToScheme)
 	registerComponentOrExit(mgr, rolloutManagerApi.AddToScheme)
-
-	// Setup Scheme for OpenShift Template if available (verified by InspectCluster)
-	if util.IsTemplateAPIFound() {
-		registerComponentOrExit(mgr, templatev1.AddToScheme)
-	}
-
-	// Setup Scheme for OpenShift Apps if available (verified by InspectCluster)
-	if util.IsAppsAPIFound() {
-		registerComponentOrExit(mgr, appsv1.AddToScheme)
-	}
-
-	// Setup Scheme for OpenShift OAuth if available (verified by InspectCluster)
-	if util.IsOAuthAPIFound() {
-		registerComponentOrExit(mgr, oauthv1.AddToScheme)
-	}
-
+	registerComponentOrExit(mgr, templatev1.AddToScheme)
+	registerComponentOrExit(mgr, appsv1.AddToScheme)
+	registerComponentOrExit(mgr, oauthv1.AddToScheme)
 	registerComponentOrExit(mgr, crdv1.AddToScheme)
 
-	// Start webhook only if ENABLE_CONVERSION_WEBHOOK is set
+	// The controllers of the optional APIs found after the operator started are started by the API discovery,
+	// which inspects the cluster every apiDiscoveryInterval
+	apiDiscovery := &util.APIDiscovery{Interval: apiDiscoveryInterval}
+
+	// Start webhooks only if ENABLE_CONVERSION_WEBHOOK is set
 	if strings.EqualFold(os.Getenv("ENABLE_CONVERSION_WEBHOOK"), "true") {
 		if err = (&argov1beta1api.ArgoCD{}).SetupWebhookWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "ArgoCD")
 			os.Exit(1)
 		}
+		if err = (&pipelinesv1beta1.GitopsService{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "GitopsService")
+			os.Exit(1)
+		}
 	}
 
 	if util.IsOpenShiftCluster() {
-		if err = (&controllers.ReconcileGitopsService{
+		gitopsServiceReconciler := &controllers.ReconcileGitopsService{
 			Client:                client,
 			Scheme:                mgr.GetScheme(),
 			DisableDefaultInstall: strings.ToLower(os.Getenv(common.DisableDefaultInstallEnvVar)) == "true",
 			CentralTLSProfile:     profile,
-		}).SetupWithManager(mgr); err != nil {
+			Recorder:              mgr.GetEventRecorderFor("gitopsservice-controller"), //nolint:staticcheck // SA1019: core events are used by the operator
+		}
+		// The GitopsService instance is created once the webhook validating it is serving
+		if strings.EqualFold(os.Getenv("ENABLE_CONVERSION_WEBHOOK"), "true") {
+			gitopsServiceReconciler.WebhookReady = mgr.GetWebhookServer().StartedChecker()
+		}
+		// The Argo CD reconciler must not revoke the cluster configuration privileges granted by the GitopsService
+		if err = controllers.InitClusterConfigNamespaces(ctx, mgr.GetAPIReader()); err != nil {
+			setupLog.Error(err, "unable to read the cluster configuration namespaces", "controller", "GitopsService")
+			os.Exit(1)
+		}
+		if err = gitopsServiceReconciler.SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "GitopsService")
 			os.Exit(1)
 		}
+		// The Routes of the GitopsService are watched once the Route API is available
+		if err = apiDiscovery.OnAPIFound("route.openshift.io", util.IsRouteAPIFound, func() error {
+			return gitopsServiceReconciler.WatchRoutes(mgr)
+		}); err != nil {
+			setupLog.Error(err, "unable to watch Routes", "controller", "GitopsService")
+			os.Exit(1)
+		}
 	} else {
 		setupLog.Info("skipping GitopsService controller setup", "reason", "OpenShift Config API not available")
 	}
 
-	if util.IsRouteAPIFound() {
-		if err = (&controllers.ReconcileArgoCDRoute{
+	// The Argo CD route controller is started once the Route API is available
+	if err = apiDiscovery.OnAPIFound("route.openshift.io", util.IsRouteAPIFound, func() error {
+		return (&controllers.ReconcileArgoCDRoute{
 			Client: client,
 			Scheme: mgr.GetScheme(),
-		}).SetupWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "Argo CD route")
-			os.Exit(1)
-		}
-	} else {
-		setupLog.Info("Route API not found, skipping ReconcileArgoCDRoute controller setup")
+		}).SetupWithManager(mgr)
+	}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Argo CD route")
+		os.Exit(1)
 	}
 
-	if util.IsMonitoringAPIFound() {
-		if err = (&controllers.ArgoCDMetricsReconciler{
-			Client: client,
-			Scheme: mgr.GetScheme(),
-		}).SetupWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "Argo CD metrics")
+	monitorLabels, err := labels.ConvertSelectorToLabelsMap(serviceMonitorLabels)
+	if err != nil {
+		setupLog.Error(err, "error parsing the ServiceMonitor labels", "labels", serviceMonitorLabels)
+		os.Exit(1)
+	}
+	prometheusConfig.ServiceMonitorLabels = monitorLabels
+	prometheusConfig.NamespaceMonitoring = controllers.NamespaceMonitoring(namespaceMonitoring)
+	if err := prometheusConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid Prometheus configuration")
+		os.Exit(1)
+	}
+
+	// The Argo CD metrics controller is started once the Prometheus Operator API is available
+	if err = apiDiscovery.OnAPIFound("monitoring.coreos.com", util.IsMonitoringAPIFound, func() error {
+		return (&controllers.ArgoCDMetricsReconciler{
+			Client:     client,
+			Scheme:     mgr.GetScheme(),
+			Recorder:   mgr.GetEventRecorderFor("argocd-metrics-controller"), //nolint:staticcheck // SA1019: core events are used by the operator
+			Prometheus: prometheusConfig,
+		}).SetupWithManager(mgr)
+	}); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Argo CD metrics")
+		os.Exit(1)
+	}
+
+	if apiDiscoveryInterval > 0 {
+		if err := mgr.Add(apiDiscovery); err != nil {
+			setupLog.Error(err, "unable to set up API discovery")
 			os.Exit(1)
 		}
-	} else {
-		setupLog.Info("Monitoring API not found, skipping Argo CD metrics controller setup")
 	}
 
 	// Check the label selector format eg. "foo=bar"
@@ -418,6 +452,13 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// The pod only receives the webhook requests once the webhook server is serving
+	if strings.EqualFold(os.Getenv("ENABLE_CONVERSION_WEBHOOK"), "true") {
+		if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+			setupLog.Error(err, "unable to set up webhook ready check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
@@ -23,10 +23,22 @@ import (
 const (
 	// ArgoCDInstanceName is the default Argo CD instance name
 	ArgoCDInstanceName = "openshift-gitops"
+	// ArgoCDDefaultNamespace is the namespace of the default Argo CD instance
+	ArgoCDDefaultNamespace = "openshift-gitops"
+	// GitopsServiceName is the name of the only GitopsService reconciled by the operator
+	GitopsServiceName = "cluster"
 	// DisableDefaultInstallEnvVar is an env variable to disable the default instance
 	DisableDefaultInstallEnvVar = "DISABLE_DEFAULT_ARGOCD_INSTANCE"
 	// DisableDefaultArgoCDConsoleLink is an env variable to disable the default Argo CD ConsoleLink
 	DisableDefaultArgoCDConsoleLink = "DISABLE_DEFAULT_ARGOCD_CONSOLELINK"
+	// PrometheusServiceAccountEnvVar is an env variable to set the ServiceAccount of the Prometheus scraping the Argo CD metrics
+	PrometheusServiceAccountEnvVar = "PROMETHEUS_SERVICE_ACCOUNT"
+	// PrometheusNamespaceEnvVar is an env variable to set the namespace of the Prometheus scraping the Argo CD metrics
+	PrometheusNamespaceEnvVar = "PROMETHEUS_NAMESPACE"
+	// ServiceMonitorLabelsEnvVar is an env variable to set the labels of the ServiceMonitors of the Argo CD instances
+	ServiceMonitorLabelsEnvVar = "SERVICE_MONITOR_LABELS"
+	// NamespaceMonitoringEnvVar is an env variable to select the monitoring label of the namespaces of the Argo CD instances
+	NamespaceMonitoringEnvVar = "NAMESPACE_MONITORING"
 	// InfraNodeLabelSelector is a nodeSelector for infrastructure nodes in Openshift
 	InfraNodeLabelSelector = "node-role.kubernetes.io/infra"
 	// Default console plugin image
@@ -21,6 +21,7 @@ import (
 
 	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	argoappController "github.com/argoproj-labs/argocd-operator/controllers/argocd"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	"github.com/redhat-developer/gitops-operator/controllers/util"
 	v1 "k8s.io/api/core/v1"
 	resourcev1 "k8s.io/apimachinery/pkg/api/resource"
@@ -42,58 +43,186 @@ var (
 
 // resource exclusions for the ArgoCD CR.
 type resource struct {
-	APIGroups []string `json:"apiGroups"`
-	Kinds     []string `json:"kinds"`
-	Clusters  []string `json:"clusters"`
+	APIGroups []string `json:"apiGroups,omitempty"`
+	Kinds     []string `json:"kinds,omitempty"`
+	Clusters  []string `json:"clusters,omitempty"`
 }
 
-func getArgoApplicationSetSpec() *argoapp.ArgoCDApplicationSet {
-	return &argoapp.ArgoCDApplicationSet{
-		Resources: &v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("512Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("250m"),
-			},
-			Limits: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("1024Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("2000m"),
-			},
+// defaultResourceExclusions returns the resources excluded by default from the Argo CD instances created by the operator
+func defaultResourceExclusions() []resource {
+	return []resource{
+		{
+			APIGroups: []string{"", "discovery.k8s.io"},
+			Kinds:     []string{"Endpoints", "EndpointSlice"},
+			Clusters:  []string{"*"},
+		},
+		{
+			APIGroups: []string{"apiregistration.k8s.io"},
+			Kinds:     []string{"APIService"},
+			Clusters:  []string{"*"},
+		},
+		{
+			APIGroups: []string{"coordination.k8s.io"},
+			Kinds:     []string{"Lease"},
+			Clusters:  []string{"*"},
+		},
+		{
+			APIGroups: []string{"authentication.k8s.io", "authorization.k8s.io"},
+			Kinds:     []string{"SelfSubjectReview", "TokenReview", "LocalSubjectAccessReview", "SelfSubjectAccessReview", "SelfSubjectRulesReview", "SubjectAccessReview"},
+			Clusters:  []string{"*"},
+		},
+		{
+			APIGroups: []string{"certificates.k8s.io"},
+			Kinds:     []string{"CertificateSigningRequest"},
+			Clusters:  []string{"*"},
+		},
+		{
+			APIGroups: []string{"cert-manager.io"},
+			Kinds:     []string{"CertificateRequest"},
+			Clusters:  []string{"*"},
+		},
+		{
+			APIGroups: []string{"cilium.io"},
+			Kinds:     []string{"CiliumIdentity", "CiliumEndpoint", "CiliumEndpointSlice"},
+			Clusters:  []string{"*"},
+		},
+		{
+			APIGroups: []string{"kyverno.io", "reports.kyverno.io", "wgpolicyk8s.io"},
+			Kinds:     []string{"PolicyReport", "ClusterPolicyReport", "EphemeralReport", "ClusterEphemeralReport", "AdmissionReport", "ClusterAdmissionReport", "BackgroundScanReport", "ClusterBackgroundScanReport", "UpdateRequest"},
+			Clusters:  []string{"*"},
+		},
+		{
+			APIGroups: []string{"tekton.dev"},
+			Kinds:     []string{"TaskRun", "PipelineRun"},
+			Clusters:  []string{"*"},
 		},
 	}
 }
 
-func getArgoControllerSpec() argoapp.ArgoCDApplicationControllerSpec {
-	return argoapp.ArgoCDApplicationControllerSpec{
-		Resources: &v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("1024Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("250m"),
-			},
-			Limits: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("2048Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("2000m"),
-			},
+// ManagedFieldsUpgradedAnnotation records on the Argo CD instances applied by the operator that the fields set by
+// previous versions of the operator, without server-side apply, were transferred to the apply field manager
+const ManagedFieldsUpgradedAnnotation = "pipelines.openshift.io/managed-fields-upgraded"
+
+// ClusterConfigAnnotation records on the Argo CD instances whether their namespace is granted the cluster
+// configuration privileges. It is updated when the privileges are granted or revoked, so that the instances are
+// reconciled again.
+const ClusterConfigAnnotation = "pipelines.openshift.io/cluster-config"
+
+// ConflictingFieldsAnnotation lists the fields of the default Argo CD instance that are owned by another field manager
+// and are therefore left unchanged by the operator, along with the manager owning them
+const ConflictingFieldsAnnotation = "pipelines.openshift.io/conflicting-fields"
+
+// componentSizing defines the resource requests and limits of an Argo CD component
+type componentSizing struct {
+	requestMemory, requestCPU, limitMemory, limitCPU string
+}
+
+func (c componentSizing) resources() *v1.ResourceRequirements {
+	return &v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceMemory: resourcev1.MustParse(c.requestMemory),
+			v1.ResourceCPU:    resourcev1.MustParse(c.requestCPU),
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceMemory: resourcev1.MustParse(c.limitMemory),
+			v1.ResourceCPU:    resourcev1.MustParse(c.limitCPU),
 		},
 	}
 }
 
-func getArgoDexSpec() *argoapp.ArgoCDDexSpec {
+// profileSizing defines the sizing of every Argo CD component for a profile
+type profileSizing struct {
+	applicationSet, controller, dex, grafana, ha, redis, repo, server componentSizing
+	// haEnabled turns on HA mode
+	haEnabled bool
+	// controllerShards enables application controller sharding with this many replicas, if greater than one
+	controllerShards int32
+	// repoReplicas is the number of repo-server replicas, left to the operator default if zero
+	repoReplicas int32
+}
+
+var profiles = map[pipelinesv1beta1.DefaultArgoCDProfile]profileSizing{
+	// small fits edge and single node OpenShift clusters managing a handful of applications
+	pipelinesv1beta1.DefaultArgoCDProfileSmall: {
+		applicationSet: componentSizing{"256Mi", "125m", "512Mi", "1000m"},
+		controller:     componentSizing{"512Mi", "125m", "1024Mi", "1000m"},
+		dex:            componentSizing{"64Mi", "125m", "128Mi", "250m"},
+		grafana:        componentSizing{"64Mi", "125m", "128Mi", "250m"},
+		ha:             componentSizing{"64Mi", "125m", "128Mi", "250m"},
+		redis:          componentSizing{"64Mi", "125m", "128Mi", "250m"},
+		repo:           componentSizing{"128Mi", "125m", "512Mi", "500m"},
+		server:         componentSizing{"64Mi", "50m", "128Mi", "250m"},
+	},
+	// medium is the historical sizing of the default instance, used when no profile is set
+	pipelinesv1beta1.DefaultArgoCDProfileMedium: {
+		applicationSet: componentSizing{"512Mi", "250m", "1024Mi", "2000m"},
+		controller:     componentSizing{"1024Mi", "250m", "2048Mi", "2000m"},
+		dex:            componentSizing{"128Mi", "250m", "256Mi", "500m"},
+		grafana:        componentSizing{"128Mi", "250m", "256Mi", "500m"},
+		ha:             componentSizing{"128Mi", "250m", "256Mi", "500m"},
+		redis:          componentSizing{"128Mi", "250m", "256Mi", "500m"},
+		repo:           componentSizing{"256Mi", "250m", "1024Mi", "1000m"},
+		server:         componentSizing{"128Mi", "125m", "256Mi", "500m"},
+	},
+	// large fits fleets of thousands of applications
+	pipelinesv1beta1.DefaultArgoCDProfileLarge: {
+		applicationSet:   componentSizing{"1024Mi", "500m", "2048Mi", "4000m"},
+		controller:       componentSizing{"2048Mi", "1000m", "8192Mi", "4000m"},
+		dex:              componentSizing{"256Mi", "250m", "512Mi", "1000m"},
+		grafana:          componentSizing{"256Mi", "250m", "512Mi", "1000m"},
+		ha:               componentSizing{"256Mi", "250m", "512Mi", "1000m"},
+		redis:            componentSizing{"256Mi", "250m", "1024Mi", "1000m"},
+		repo:             componentSizing{"512Mi", "500m", "2048Mi", "2000m"},
+		server:           componentSizing{"256Mi", "250m", "512Mi", "1000m"},
+		haEnabled:        true,
+		controllerShards: 3,
+		repoReplicas:     3,
+	},
+}
+
+// EffectiveProfile returns the sizing profile used for the given profile, defaulting to medium when it is not set
+func EffectiveProfile(profile pipelinesv1beta1.DefaultArgoCDProfile) pipelinesv1beta1.DefaultArgoCDProfile {
+	if _, ok := profiles[profile]; ok {
+		return profile
+	}
+	return pipelinesv1beta1.DefaultArgoCDProfileMedium
+}
+
+func getProfileSizing(profile pipelinesv1beta1.DefaultArgoCDProfile) profileSizing {
+	return profiles[EffectiveProfile(profile)]
+}
+
+func getArgoApplicationSetSpec(sizing profileSizing) *argoapp.ArgoCDApplicationSet {
+	return &argoapp.ArgoCDApplicationSet{
+		Resources: sizing.applicationSet.resources(),
+	}
+}
+
+func getArgoControllerSpec(sizing profileSizing) argoapp.ArgoCDApplicationControllerSpec {
+	return argoapp.ArgoCDApplicationControllerSpec{
+		Resources: sizing.controller.resources(),
+		Sharding:  getArgoControllerShardSpec(sizing),
+	}
+}
+
+func getArgoControllerShardSpec(sizing profileSizing) argoapp.ArgoCDApplicationControllerShardSpec {
+	if sizing.controllerShards <= 1 {
+		return argoapp.ArgoCDApplicationControllerShardSpec{}
+	}
+	return argoapp.ArgoCDApplicationControllerShardSpec{
+		Enabled:  true,
+		Replicas: sizing.controllerShards,
+	}
+}
+
+func getArgoDexSpec(sizing profileSizing) *argoapp.ArgoCDDexSpec {
 	return &argoapp.ArgoCDDexSpec{
 		OpenShiftOAuth: true,
-		Resources: &v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("128Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("250m"),
-			},
-			Limits: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("256Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("500m"),
-			},
-		},
+		Resources:      sizing.dex.resources(),
 	}
 }
 
-func getArgoSSOSpec(client client.Client) *argoapp.ArgoCDSSOSpec {
+func getArgoSSOSpec(client client.Client, sizing profileSizing) *argoapp.ArgoCDSSOSpec {
 	if !util.IsOpenShiftCluster() {
 		log.Info("non-OpenShift cluster detected, skipping SSO/Dex configuration")
 		return nil
@@ -104,85 +233,48 @@ func getArgoSSOSpec(client client.Client) *argoapp.ArgoCDSSOSpec {
 	}
 	return &argoapp.ArgoCDSSOSpec{
 		Provider: argoapp.SSOProviderTypeDex,
-		Dex:      getArgoDexSpec(),
+		Dex:      getArgoDexSpec(sizing),
 	}
 }
 
-func getArgoGrafanaSpec() argoapp.ArgoCDGrafanaSpec {
+func getArgoGrafanaSpec(sizing profileSizing) argoapp.ArgoCDGrafanaSpec {
 	return argoapp.ArgoCDGrafanaSpec{
-		Resources: &v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("128Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("250m"),
-			},
-			Limits: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("256Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("500m"),
-			},
-		},
+		Resources: sizing.grafana.resources(),
 	}
 }
 
-func getArgoHASpec() argoapp.ArgoCDHASpec {
+func getArgoHASpec(sizing profileSizing) argoapp.ArgoCDHASpec {
 	return argoapp.ArgoCDHASpec{
-		Enabled: false,
-		Resources: &v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("128Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("250m"),
-			},
-			Limits: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("256Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("500m"),
-			},
-		},
+		Enabled:   sizing.haEnabled,
+		Resources: sizing.ha.resources(),
 	}
 }
 
-func getArgoRedisSpec() argoapp.ArgoCDRedisSpec {
+func getArgoRedisSpec(sizing profileSizing) argoapp.ArgoCDRedisSpec {
 	return argoapp.ArgoCDRedisSpec{
-		Resources: &v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("128Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("250m"),
-			},
-			Limits: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("256Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("500m"),
-			},
-		},
+		Resources: sizing.redis.resources(),
 	}
 }
 
-func getArgoRepoServerSpec() argoapp.ArgoCDRepoSpec {
+func getArgoRepoServerSpec(sizing profileSizing) argoapp.ArgoCDRepoSpec {
 	return argoapp.ArgoCDRepoSpec{
-		Resources: &v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("256Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("250m"),
-			},
-			Limits: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("1024Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("1000m"),
-			},
-		},
+		Replicas:  getReplicas(sizing.repoReplicas),
+		Resources: sizing.repo.resources(),
 	}
 }
 
-func getArgoServerSpec() argoapp.ArgoCDServerSpec {
+func getArgoServerSpec(sizing profileSizing) argoapp.ArgoCDServerSpec {
 	return argoapp.ArgoCDServerSpec{
-		Route: argoapp.ArgoCDRouteSpec{Enabled: true},
-		Resources: &v1.ResourceRequirements{
-			Requests: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("128Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("125m"),
-			},
-			Limits: v1.ResourceList{
-				v1.ResourceMemory: resourcev1.MustParse("256Mi"),
-				v1.ResourceCPU:    resourcev1.MustParse("500m"),
-			},
-		},
+		Route:     argoapp.ArgoCDRouteSpec{Enabled: true},
+		Resources: sizing.server.resources(),
+	}
+}
+
+func getReplicas(replicas int32) *int32 {
+	if replicas <= 0 {
+		return nil
 	}
+	return &replicas
 }
 
 func getDefaultRBAC() argoapp.ArgoCDRBACSpec {
@@ -196,53 +288,14 @@ func getDefaultRBAC() argoapp.ArgoCDRBACSpec {
 // NewCR returns an ArgoCD reference optimized for use in OpenShift
 // with comprehensive default resource exclusions
 func NewCR(name, ns string, client client.Client) (*argoapp.ArgoCD, error) {
-	b, err := yaml.Marshal([]resource{
-		{
-			APIGroups: []string{"", "discovery.k8s.io"},
-			Kinds:     []string{"Endpoints", "EndpointSlice"},
-			Clusters:  []string{"*"},
-		},
-		{
-			APIGroups: []string{"apiregistration.k8s.io"},
-			Kinds:     []string{"APIService"},
-			Clusters:  []string{"*"},
-		},
-		{
-			APIGroups: []string{"coordination.k8s.io"},
-			Kinds:     []string{"Lease"},
-			Clusters:  []string{"*"},
-		},
-		{
-			APIGroups: []string{"authentication.k8s.io", "authorization.k8s.io"},
-			Kinds:     []string{"SelfSubjectReview", "TokenReview", "LocalSubjectAccessReview", "SelfSubjectAccessReview", "SelfSubjectRulesReview", "SubjectAccessReview"},
-			Clusters:  []string{"*"},
-		},
-		{
-			APIGroups: []string{"certificates.k8s.io"},
-			Kinds:     []string{"CertificateSigningRequest"},
-			Clusters:  []string{"*"},
-		},
-		{
-			APIGroups: []string{"cert-manager.io"},
-			Kinds:     []string{"CertificateRequest"},
-			Clusters:  []string{"*"},
-		},
-		{
-			APIGroups: []string{"cilium.io"},
-			Kinds:     []string{"CiliumIdentity", "CiliumEndpoint", "CiliumEndpointSlice"},
-			Clusters:  []string{"*"},
-		},
-		{
-			APIGroups: []string{"kyverno.io", "reports.kyverno.io", "wgpolicyk8s.io"},
-			Kinds:     []string{"PolicyReport", "ClusterPolicyReport", "EphemeralReport", "ClusterEphemeralReport", "AdmissionReport", "ClusterAdmissionReport", "BackgroundScanReport", "ClusterBackgroundScanReport", "UpdateRequest"},
-			Clusters:  []string{"*"},
-		},
-		{
-			APIGroups: []string{"tekton.dev"},
-			Kinds:     []string{"TaskRun", "PipelineRun"},
-			Clusters:  []string{"*"},
-		},
-	})
+	return NewCRForProfile(name, ns, "", client)
+}
+
+// NewCRForProfile returns an ArgoCD reference like NewCR, sized according to the given profile
+func NewCRForProfile(name, ns string, profile pipelinesv1beta1.DefaultArgoCDProfile, client client.Client) (*argoapp.ArgoCD, error) {
+	sizing := getProfileSizing(profile)
+
+	b, err := yaml.Marshal(defaultResourceExclusions())
 	if err != nil {
 		return nil, err
 	}
@@ -252,14 +305,14 @@ func NewCR(name, ns string, client client.Client) (*argoapp.ArgoCD, error) {
 			Namespace: ns,
 		},
 		Spec: argoapp.ArgoCDSpec{
-			ApplicationSet:     getArgoApplicationSetSpec(),
-			Controller:         getArgoControllerSpec(),
-			SSO:                getArgoSSOSpec(client),
-			Grafana:            getArgoGrafanaSpec(),
-			HA:                 getArgoHASpec(),
-			Redis:              getArgoRedisSpec(),
-			Repo:               getArgoRepoServerSpec(),
-			Server:             getArgoServerSpec(),
+			ApplicationSet:     getArgoApplicationSetSpec(sizing),
+			Controller:         getArgoControllerSpec(sizing),
+			SSO:                getArgoSSOSpec(client, sizing),
+			Grafana:            getArgoGrafanaSpec(sizing),
+			HA:                 getArgoHASpec(sizing),
+			Redis:              getArgoRedisSpec(sizing),
+			Repo:               getArgoRepoServerSpec(sizing),
+			Server:             getArgoServerSpec(sizing),
 			RBAC:               getDefaultRBAC(),
 			ResourceExclusions: string(b),
 		},
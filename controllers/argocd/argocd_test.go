@@ -22,6 +22,7 @@ import (
 
 	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	configv1 "github.com/openshift/api/config/v1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	"github.com/redhat-developer/gitops-operator/controllers/util"
 	"gotest.tools/assert"
 	v1 "k8s.io/api/core/v1"
@@ -202,6 +203,37 @@ func TestArgoCD(t *testing.T) {
 	}
 }
 
+func TestArgoCDProfiles(t *testing.T) {
+	util.SetConfigAPIFound(true)
+	defer util.SetConfigAPIFound(false)
+
+	scheme := runtime.NewScheme()
+	_ = argoapp.AddToScheme(scheme)
+	_ = configv1.AddToScheme(scheme)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	// No profile keeps the historical sizing
+	defaultArgoCD, _ := NewCR("openshift-gitops", "openshift-gitops", fakeClient)
+	mediumArgoCD, _ := NewCRForProfile("openshift-gitops", "openshift-gitops", pipelinesv1beta1.DefaultArgoCDProfileMedium, fakeClient)
+	assert.DeepEqual(t, defaultArgoCD.Spec, mediumArgoCD.Spec)
+
+	smallArgoCD, _ := NewCRForProfile("openshift-gitops", "openshift-gitops", pipelinesv1beta1.DefaultArgoCDProfileSmall, fakeClient)
+	assert.DeepEqual(t, smallArgoCD.Spec.Controller.Resources.Limits[v1.ResourceMemory], resourcev1.MustParse("1024Mi"))
+	assert.DeepEqual(t, smallArgoCD.Spec.Repo.Resources.Requests[v1.ResourceMemory], resourcev1.MustParse("128Mi"))
+	assert.Equal(t, smallArgoCD.Spec.HA.Enabled, false)
+	assert.Equal(t, smallArgoCD.Spec.Controller.Sharding.Enabled, false)
+	assert.Assert(t, smallArgoCD.Spec.Repo.Replicas == nil)
+
+	largeArgoCD, _ := NewCRForProfile("openshift-gitops", "openshift-gitops", pipelinesv1beta1.DefaultArgoCDProfileLarge, fakeClient)
+	assert.DeepEqual(t, largeArgoCD.Spec.Controller.Resources.Limits[v1.ResourceMemory], resourcev1.MustParse("8192Mi"))
+	assert.DeepEqual(t, largeArgoCD.Spec.SSO.Dex.Resources.Limits[v1.ResourceCPU], resourcev1.MustParse("1000m"))
+	assert.Equal(t, largeArgoCD.Spec.HA.Enabled, true)
+	assert.DeepEqual(t, largeArgoCD.Spec.Controller.Sharding, argoapp.ArgoCDApplicationControllerShardSpec{Enabled: true, Replicas: 3})
+	assert.Equal(t, *largeArgoCD.Spec.Repo.Replicas, int32(3))
+	assert.Equal(t, largeArgoCD.Spec.Server.Route.Enabled, true)
+}
+
 func TestDexConfiguration(t *testing.T) {
 	util.SetConfigAPIFound(true)
 	defer util.SetConfigAPIFound(false)
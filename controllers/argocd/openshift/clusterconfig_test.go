@@ -2,6 +2,7 @@ package openshift
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -15,6 +16,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/redhat-developer/gitops-operator/controllers/util"
 )
 
 const (
@@ -57,7 +59,9 @@ func makeTestArgoCD() *argoapp.ArgoCD {
 }
 
 func setClusterConfigNamespaces(t *testing.T) {
-	t.Setenv("ARGOCD_CLUSTER_CONFIG_NAMESPACES", "argocd,foo,bar")
+	configured := strings.Join(util.ClusterConfigNamespaces(), ",")
+	t.Cleanup(func() { util.SetConfiguredClusterConfigNamespaces(configured) })
+	util.SetConfiguredClusterConfigNamespaces("argocd,foo,bar")
 }
 
 func makeTestClusterRole() *rbacv1.ClusterRole {
@@ -0,0 +1,154 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argocd
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// ManagedResourceExclusionsAnnotation records on the default Argo CD instance the resource exclusions last configured
+// by the operator, so that they can be told apart from the entries added by users
+const ManagedResourceExclusionsAnnotation = "pipelines.openshift.io/managed-resource-exclusions"
+
+// managedResourceExclusions returns the default resource exclusions with the changes requested in the GitopsService applied
+func managedResourceExclusions(spec *pipelinesv1beta1.ResourceExclusionsSpec) []resource {
+	exclusions := defaultResourceExclusions()
+	if spec == nil {
+		return exclusions
+	}
+	for _, removed := range spec.Remove {
+		exclusions = removeExcludedKinds(exclusions, removed)
+	}
+	for _, added := range spec.Add {
+		exclusion := resource{APIGroups: added.APIGroups, Kinds: added.Kinds, Clusters: added.Clusters}
+		if !containsResource(exclusions, exclusion) {
+			exclusions = append(exclusions, exclusion)
+		}
+	}
+	return exclusions
+}
+
+// removeExcludedKinds removes the kinds of removed from the exclusions sharing one of its API groups,
+// or from all the exclusions if removed has no API group. Exclusions left without kinds are dropped.
+func removeExcludedKinds(exclusions []resource, removed pipelinesv1beta1.ResourceExclusion) []resource {
+	result := []resource{}
+	for _, exclusion := range exclusions {
+		if len(removed.APIGroups) == 0 || containsAny(exclusion.APIGroups, removed.APIGroups) {
+			var kinds []string
+			for _, kind := range exclusion.Kinds {
+				if !containsAny([]string{kind}, removed.Kinds) {
+					kinds = append(kinds, kind)
+				}
+			}
+			if len(kinds) == 0 {
+				continue
+			}
+			exclusion.Kinds = kinds
+		}
+		result = append(result, exclusion)
+	}
+	return result
+}
+
+// SetResourceExclusions updates the resource exclusions of the given Argo CD instance to the defaults merged with the
+// changes requested in the GitopsService. Entries that were not configured by the operator are kept.
+// It returns true if the instance was changed.
+func SetResourceExclusions(argoCD *argoapp.ArgoCD, spec *pipelinesv1beta1.ResourceExclusionsSpec) (bool, error) {
+	managed := managedResourceExclusions(spec)
+
+	// Instances created before the managed exclusions were recorded only contain the defaults
+	previous := defaultResourceExclusions()
+	if value, ok := argoCD.Annotations[ManagedResourceExclusionsAnnotation]; ok {
+		previous = nil
+		if err := json.Unmarshal([]byte(value), &previous); err != nil {
+			return false, fmt.Errorf("unable to parse annotation %s of Argo CD %s: %w", ManagedResourceExclusionsAnnotation, argoCD.Name, err)
+		}
+	}
+
+	var existing []resource
+	if argoCD.Spec.ResourceExclusions != "" {
+		if err := yaml.Unmarshal([]byte(argoCD.Spec.ResourceExclusions), &existing); err != nil {
+			return false, fmt.Errorf("unable to parse resource exclusions of Argo CD %s: %w", argoCD.Name, err)
+		}
+	}
+
+	var exclusions []resource
+	for _, exclusion := range existing {
+		// Drop the entries the operator configured but no longer manages
+		if containsResource(previous, exclusion) && !containsResource(managed, exclusion) {
+			continue
+		}
+		exclusions = append(exclusions, exclusion)
+	}
+	for _, exclusion := range managed {
+		if !containsResource(exclusions, exclusion) {
+			exclusions = append(exclusions, exclusion)
+		}
+	}
+
+	changed := false
+	if !reflect.DeepEqual(existing, exclusions) {
+		argoCD.Spec.ResourceExclusions = ""
+		if len(exclusions) > 0 {
+			b, err := yaml.Marshal(exclusions)
+			if err != nil {
+				return false, err
+			}
+			argoCD.Spec.ResourceExclusions = string(b)
+		}
+		changed = true
+	}
+
+	b, err := json.Marshal(managed)
+	if err != nil {
+		return false, err
+	}
+	if argoCD.Annotations[ManagedResourceExclusionsAnnotation] != string(b) {
+		if argoCD.Annotations == nil {
+			argoCD.Annotations = map[string]string{}
+		}
+		argoCD.Annotations[ManagedResourceExclusionsAnnotation] = string(b)
+		changed = true
+	}
+	return changed, nil
+}
+
+func containsResource(resources []resource, r resource) bool {
+	for _, candidate := range resources {
+		if reflect.DeepEqual(candidate, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
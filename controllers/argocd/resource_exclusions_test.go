@@ -0,0 +1,122 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package argocd
+
+import (
+	"testing"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	"gotest.tools/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func parseResourceExclusions(t *testing.T, argoCD *argoapp.ArgoCD) []resource {
+	t.Helper()
+	var exclusions []resource
+	assert.NilError(t, yaml.Unmarshal([]byte(argoCD.Spec.ResourceExclusions), &exclusions))
+	return exclusions
+}
+
+func TestManagedResourceExclusions(t *testing.T) {
+	assert.DeepEqual(t, managedResourceExclusions(nil), defaultResourceExclusions())
+
+	exclusions := managedResourceExclusions(&pipelinesv1beta1.ResourceExclusionsSpec{
+		Add: []pipelinesv1beta1.ResourceExclusion{
+			{APIGroups: []string{"example.com"}, Kinds: []string{"Report"}, Clusters: []string{"*"}},
+		},
+		Remove: []pipelinesv1beta1.ResourceExclusion{
+			// Removes the only kind of the Tekton entry
+			{APIGroups: []string{"tekton.dev"}, Kinds: []string{"TaskRun", "PipelineRun"}},
+			// Removes a single kind of the Endpoints entry
+			{APIGroups: []string{"discovery.k8s.io"}, Kinds: []string{"EndpointSlice"}},
+			// No API group removes the kind from any entry
+			{Kinds: []string{"Lease"}},
+		},
+	})
+
+	assert.Assert(t, containsResource(exclusions, resource{
+		APIGroups: []string{"", "discovery.k8s.io"},
+		Kinds:     []string{"Endpoints"},
+		Clusters:  []string{"*"},
+	}))
+	assert.Assert(t, containsResource(exclusions, resource{
+		APIGroups: []string{"example.com"},
+		Kinds:     []string{"Report"},
+		Clusters:  []string{"*"},
+	}))
+	for _, exclusion := range exclusions {
+		assert.Assert(t, !containsAny(exclusion.APIGroups, []string{"tekton.dev", "coordination.k8s.io"}),
+			"unexpected exclusion %v", exclusion)
+	}
+	assert.Equal(t, len(exclusions), len(defaultResourceExclusions())-1)
+}
+
+func TestSetResourceExclusions(t *testing.T) {
+	userExclusion := resource{APIGroups: []string{"user.example.com"}, Kinds: []string{"Widget"}}
+
+	// An instance created by a previous operator version, with a user-authored entry
+	existing := append(defaultResourceExclusions()[1:], userExclusion)
+	b, err := yaml.Marshal(existing)
+	assert.NilError(t, err)
+	argoCD := &argoapp.ArgoCD{Spec: argoapp.ArgoCDSpec{ResourceExclusions: string(b)}}
+
+	changed, err := SetResourceExclusions(argoCD, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, changed)
+	exclusions := parseResourceExclusions(t, argoCD)
+	assert.Assert(t, containsResource(exclusions, userExclusion), "user-authored entries must be kept")
+	for _, exclusion := range defaultResourceExclusions() {
+		assert.Assert(t, containsResource(exclusions, exclusion), "missing default exclusion %v", exclusion)
+	}
+	assert.Assert(t, argoCD.Annotations[ManagedResourceExclusionsAnnotation] != "")
+
+	// Reconciling again is a no-op
+	changed, err = SetResourceExclusions(argoCD, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, !changed)
+
+	// Entries removed through the GitopsService are dropped, user-authored entries are kept
+	spec := &pipelinesv1beta1.ResourceExclusionsSpec{
+		Remove: []pipelinesv1beta1.ResourceExclusion{{APIGroups: []string{"tekton.dev"}, Kinds: []string{"TaskRun", "PipelineRun"}}},
+		Add:    []pipelinesv1beta1.ResourceExclusion{{APIGroups: []string{"example.com"}, Kinds: []string{"Report"}}},
+	}
+	changed, err = SetResourceExclusions(argoCD, spec)
+	assert.NilError(t, err)
+	assert.Assert(t, changed)
+	exclusions = parseResourceExclusions(t, argoCD)
+	assert.Assert(t, containsResource(exclusions, userExclusion))
+	assert.Assert(t, containsResource(exclusions, resource{APIGroups: []string{"example.com"}, Kinds: []string{"Report"}}))
+	assert.Assert(t, !containsResource(exclusions, defaultResourceExclusions()[8]))
+
+	// Entries added through the GitopsService are dropped once they are no longer requested
+	changed, err = SetResourceExclusions(argoCD, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, changed)
+	exclusions = parseResourceExclusions(t, argoCD)
+	assert.Assert(t, !containsResource(exclusions, resource{APIGroups: []string{"example.com"}, Kinds: []string{"Report"}}))
+	assert.Assert(t, containsResource(exclusions, defaultResourceExclusions()[8]))
+	assert.Assert(t, containsResource(exclusions, userExclusion))
+}
+
+func TestSetResourceExclusions_InvalidExclusions(t *testing.T) {
+	argoCD := &argoapp.ArgoCD{Spec: argoapp.ArgoCDSpec{ResourceExclusions: "not: [a list"}}
+	argoCD.Name = "openshift-gitops"
+
+	_, err := SetResourceExclusions(argoCD, nil)
+	assert.ErrorContains(t, err, "unable to parse resource exclusions of Argo CD openshift-gitops")
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/go-logr/logr"
 	console "github.com/openshift/api/console/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	"github.com/redhat-developer/gitops-operator/common"
 	"github.com/redhat-developer/gitops-operator/controllers/util"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -39,6 +40,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -64,9 +66,21 @@ func init() {
 	encodedArgoImage = imageDataURL(base64.StdEncoding.EncodeToString(argoImage))
 }
 
-// if DISABLE_DEFAULT_ARGOCD_CONSOLELINK env variable is true, Argo CD ConsoleLink will be deleted
-func isConsoleLinkDisabled() bool {
-	return strings.ToLower(os.Getenv(common.DisableDefaultArgoCDConsoleLink)) == "true"
+// if DISABLE_DEFAULT_ARGOCD_CONSOLELINK env variable is true, or the ConsoleLink is disabled in the
+// GitopsService, Argo CD ConsoleLink will be deleted
+func (r *ReconcileArgoCDRoute) isConsoleLinkDisabled(ctx context.Context) (bool, error) {
+	if strings.ToLower(os.Getenv(common.DisableDefaultArgoCDConsoleLink)) == "true" {
+		return true, nil
+	}
+	gitopsService := &pipelinesv1beta1.GitopsService{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: serviceName}, gitopsService)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return gitopsService.Spec.ConsoleLink != nil && gitopsService.Spec.ConsoleLink.Disabled, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -77,6 +91,10 @@ func (r *ReconcileArgoCDRoute) SetupWithManager(mgr ctrl.Manager) error {
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&routev1.Route{}, builder.WithPredicates(filterPredicate(filterArgoCDRoute))).
+		Watches(&pipelinesv1beta1.GitopsService{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: argocdRouteName, Namespace: argocdNS}}}
+			})).
 		Complete(r)
 }
 
@@ -143,7 +161,11 @@ func (r *ReconcileArgoCDRoute) Reconcile(ctx context.Context, request reconcile.
 
 	consoleLink := newConsoleLink(argoCDRouteURL, "Cluster Argo CD")
 
-	if isConsoleLinkDisabled() {
+	consoleLinkDisabled, err := r.isConsoleLinkDisabled(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if consoleLinkDisabled {
 		return reconcile.Result{}, r.deleteConsoleLinkIfPresent(ctx, reqLogger)
 	} else {
 		found := &console.ConsoleLink{}
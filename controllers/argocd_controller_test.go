@@ -26,6 +26,7 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	console "github.com/openshift/api/console/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	"github.com/redhat-developer/gitops-operator/controllers/util"
 	"gotest.tools/assert"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -163,6 +164,20 @@ func TestReconcile_delete_consolelink(t *testing.T) {
 
 }
 
+func TestReconcile_delete_consolelink_disabledInGitopsService(t *testing.T) {
+	defer util.SetConsoleAPIFound(util.IsConsoleAPIFound())
+	util.SetConsoleAPIFound(true)
+
+	gitopsService := newGitopsService()
+	gitopsService.Spec.ConsoleLink = &pipelinesv1beta1.ConsoleLinkSpec{Disabled: true}
+	reconcileArgoCD, fakeClient := newFakeReconcileArgoCD(argoCDRoute, gitopsService)
+	err := fakeClient.Create(context.TODO(), newConsoleLink("https://test.com", "Cluster Argo CD"))
+	assertNoError(t, err)
+
+	result, err := reconcileArgoCD.Reconcile(context.TODO(), newRequest(argocdNS, argocdInstanceName))
+	assertConsoleLinkDeletion(t, fakeClient, reconcileResult{result, err})
+}
+
 func TestReconcile_update_consolelink(t *testing.T) {
 	defer util.SetConsoleAPIFound(util.IsConsoleAPIFound())
 	util.SetConsoleAPIFound(true)
@@ -200,6 +215,7 @@ func newFakeReconcileArgoCD(objs ...runtime.Object) (*ReconcileArgoCDRoute, clie
 	s.AddKnownTypes(routev1.GroupVersion, &routev1.Route{})
 	s.AddKnownTypes(console.GroupVersion, &console.ConsoleLink{})
 	s.AddKnownTypes(configv1.GroupVersion, &configv1.ClusterVersion{})
+	s.AddKnownTypes(pipelinesv1beta1.GroupVersion, &pipelinesv1beta1.GitopsService{})
 	fakeClient := fake.NewFakeClient(objs...)
 	return &ReconcileArgoCDRoute{
 		Client: fakeClient,
@@ -0,0 +1,478 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/go-logr/logr"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const alertRuleGroupName = "GitOpsOperatorArgoCD"
+
+// alertSeverities are the severities accepted in the monitoring configuration of an alert
+var alertSeverities = []string{"critical", "warning", "info"}
+
+// alert is an alert of the catalogue installed in the PrometheusRule of an ArgoCD instance
+type alert struct {
+	// name of the alert, also used in the monitoring configuration
+	name     string
+	summary  string
+	desc     string
+	for_     monitoringv1.Duration
+	severity string
+	// application is true if the alert fires for an Argo CD application, with the labels name and namespace of
+	// the application, so that the configured Application labels can be added to it
+	application bool
+	// expr returns the expression of the alert for the ArgoCD instance
+	expr func(argocd *argoapp.ArgoCD) string
+}
+
+// alerts is the catalogue of the alerts of an ArgoCD instance, all enabled by default.
+//
+// The namespace of the Argo CD application metrics (argocd_app_*) is not the namespace of the running
+// application, it is the namespace that the corresponding Argo CD application metadata was created in. All the
+// metrics are labelled with the namespace and the Service of their scrape target, which scope the alerts to the
+// ArgoCD instance when several instances share a namespace.
+var alerts = []alert{
+	{
+		name:        "ArgoCDSyncAlert",
+		summary:     "Argo CD application is out of sync",
+		desc:        "Argo CD application {{ $labels.name }} is out of sync. Check ArgoCDSyncAlert status, this alert is designed to notify that an application managed by Argo CD is out of sync.",
+		for_:        "5m",
+		severity:    "warning",
+		application: true,
+		expr: func(argocd *argoapp.ArgoCD) string {
+			return fmt.Sprintf(`argocd_app_info{namespace="%s",service="%s-metrics",sync_status="OutOfSync"} > 0`,
+				argocd.Namespace, argocd.Name)
+		},
+	},
+	{
+		name:        "ArgoCDApplicationDegraded",
+		summary:     "Argo CD application is degraded",
+		desc:        "Argo CD application {{ $labels.name }} has been degraded for more than 15 minutes, the health of some of its resources is degraded.",
+		for_:        "15m",
+		severity:    "warning",
+		application: true,
+		expr: func(argocd *argoapp.ArgoCD) string {
+			return fmt.Sprintf(`argocd_app_info{namespace="%s",service="%s-metrics",health_status="Degraded"} > 0`,
+				argocd.Namespace, argocd.Name)
+		},
+	},
+	{
+		name:        "ArgoCDSyncFailed",
+		summary:     "Argo CD application sync failed",
+		desc:        "The sync of Argo CD application {{ $labels.name }} failed with phase {{ $labels.phase }} in the last 10 minutes.",
+		for_:        "1m",
+		severity:    "warning",
+		application: true,
+		expr: func(argocd *argoapp.ArgoCD) string {
+			return fmt.Sprintf(`sum by (namespace, name, phase) (increase(argocd_app_sync_total{namespace="%s",service="%s-metrics",phase=~"Error|Failed"}[10m])) > 0`,
+				argocd.Namespace, argocd.Name)
+		},
+	},
+	{
+		name:     "ArgoCDRepoServerGitErrors",
+		summary:  "Argo CD repo server fails to fetch Git repositories",
+		desc:     "The repo server of Argo CD instance {{ $labels.namespace }} failed to fetch Git repository {{ $labels.repo }} in the last 10 minutes.",
+		for_:     "10m",
+		severity: "warning",
+		expr: func(argocd *argoapp.ArgoCD) string {
+			return fmt.Sprintf(`sum by (namespace, repo) (increase(argocd_git_fetch_fail_total{namespace="%s",service="%s-repo-server"}[10m])) > 0`,
+				argocd.Namespace, argocd.Name)
+		},
+	},
+	{
+		name:     "ArgoCDControllerQueueDepth",
+		summary:  "Argo CD application controller queue is growing",
+		desc:     "The {{ $labels.name }} queue of the application controller of Argo CD instance {{ $labels.namespace }} holds more than 100 items, the applications are reconciled with delay.",
+		for_:     "15m",
+		severity: "warning",
+		expr: func(argocd *argoapp.ArgoCD) string {
+			return fmt.Sprintf(`sum by (namespace, name) (workqueue_depth{namespace="%s",service="%s-metrics",name=~"app_reconciliation_queue|app_operation_processing_queue"}) > 100`,
+				argocd.Namespace, argocd.Name)
+		},
+	},
+	{
+		name:     "ArgoCDClusterConnectionError",
+		summary:  "Argo CD cannot connect to a cluster",
+		desc:     "The application controller of Argo CD instance {{ $labels.namespace }} cannot connect to cluster {{ $labels.server }}.",
+		for_:     "5m",
+		severity: "critical",
+		expr: func(argocd *argoapp.ArgoCD) string {
+			return fmt.Sprintf(`argocd_cluster_connection_status{namespace="%s",service="%s-metrics"} == 0`, argocd.Namespace, argocd.Name)
+		},
+	},
+	{
+		name:     "ArgoCDRedisDown",
+		summary:  "Argo CD Redis is unavailable",
+		desc:     "No Redis replica of Argo CD instance {{ $labels.namespace }} is available, the Argo CD components cannot use their cache.",
+		for_:     "5m",
+		severity: "critical",
+		expr: func(argocd *argoapp.ArgoCD) string {
+			return fmt.Sprintf(`kube_deployment_status_replicas_available{namespace="%[1]s",deployment="%[2]s-redis"} == 0 or kube_statefulset_status_replicas_ready{namespace="%[1]s",statefulset="%[2]s-redis-ha-server"} == 0`,
+				argocd.Namespace, argocd.Name)
+		},
+	},
+}
+
+// alertConfig configures an alert of the catalogue
+type alertConfig struct {
+	// Enabled selects if the alert is installed, true by default
+	Enabled *bool `json:"enabled,omitempty"`
+	// For is the time the alert condition must hold before the alert fires
+	For monitoringv1.Duration `json:"for,omitempty"`
+	// Severity is the severity label of the alert, one of critical, warning or info
+	Severity string `json:"severity,omitempty"`
+}
+
+func isAlert(name string) bool {
+	for _, alert := range alerts {
+		if alert.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validate returns an error if the configuration of the alert cannot be used
+func (c alertConfig) validate() error {
+	if c.Severity == "" {
+		return nil
+	}
+	for _, severity := range alertSeverities {
+		if c.Severity == severity {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q, expected one of %v", c.Severity, alertSeverities)
+}
+
+// applicationLabelName returns the name of the label of the argocd_app_labels metric holding the value of the
+// Application label of the given key
+func applicationLabelName(key string) string {
+	return "label_" + invalidLabelNameChars.ReplaceAllString(key, "_")
+}
+
+var invalidLabelNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// withApplicationLabels joins the expression of an application alert with the argocd_app_labels metric, so that
+// the alert is labelled with the given Application labels and can be routed by Alertmanager
+func withApplicationLabels(expr string, argocd *argoapp.ArgoCD, keys []string) string {
+	if len(keys) == 0 {
+		return expr
+	}
+	labels := make([]string, len(keys))
+	for i, key := range keys {
+		labels[i] = applicationLabelName(key)
+	}
+	return fmt.Sprintf(`(%s) * on (namespace, name) group_left(%s) argocd_app_labels{namespace="%s",service="%s-metrics"}`,
+		expr, strings.Join(labels, ", "), argocd.Namespace, argocd.Name)
+}
+
+// rule returns the Prometheus rule of the alert for the ArgoCD instance, as configured. The application alerts are
+// labelled with the given Application labels.
+func (a alert) rule(argocd *argoapp.ArgoCD, config alertConfig, applicationLabels []string) monitoringv1.Rule {
+	for_, severity, expr := a.for_, a.severity, a.expr(argocd)
+	if a.application {
+		expr = withApplicationLabels(expr, argocd, applicationLabels)
+	}
+	if config.For != "" {
+		for_ = config.For
+	}
+	if config.Severity != "" {
+		severity = config.Severity
+	}
+	return monitoringv1.Rule{
+		Alert: a.name,
+		Annotations: map[string]string{
+			"summary":     a.summary,
+			"description": a.desc,
+		},
+		Expr: intstr.FromString(expr),
+		For:  ptr.To(for_),
+		Labels: map[string]string{
+			"severity": severity,
+		},
+	}
+}
+
+// newPrometheusRule returns the PrometheusRule of the alerts enabled in the monitoring configuration of the ArgoCD
+// instance, or nil when all the alerts are disabled
+func newPrometheusRule(argocd *argoapp.ArgoCD, config *argoCDMonitoringConfig) *monitoringv1.PrometheusRule {
+	rules := []monitoringv1.Rule{}
+	for _, alert := range alerts {
+		alertConfig := config.Alerts[alert.name]
+		if alertConfig.Enabled != nil && !*alertConfig.Enabled {
+			continue
+		}
+		rules = append(rules, alert.rule(argocd, alertConfig, config.ApplicationLabels))
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      fmt.Sprintf(alertRuleNameFormat, argocd.Name),
+		Namespace: argocd.Namespace,
+	}
+	spec := monitoringv1.PrometheusRuleSpec{
+		Groups: []monitoringv1.RuleGroup{
+			{
+				Name:  alertRuleGroupName,
+				Rules: rules,
+			},
+		},
+	}
+	return &monitoringv1.PrometheusRule{
+		ObjectMeta: objectMeta,
+		Spec:       spec,
+	}
+}
+
+// reconcilePrometheusRule creates or updates the PrometheusRule of the alerts enabled for the ArgoCD instance, and
+// deletes it when all the alerts are disabled. The rules of an existing PrometheusRule are replaced, so that the
+// alerts of an upgraded operator are installed, and a PrometheusRule without owner is adopted. An invalid configuration leaves the PrometheusRule unchanged, it is
+// reported by reconcileServiceMonitors.
+func (r *ArgoCDMetricsReconciler) reconcilePrometheusRule(argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	config, err := monitoringConfig(argocd)
+	if err != nil {
+		return nil
+	}
+
+	// The alert rule was shared by the instances of the namespace in previous versions of the operator
+	if err := r.deleteLegacyPrometheusRule(argocd, reqLogger); err != nil {
+		return err
+	}
+
+	alertRule := newPrometheusRule(argocd, config)
+	ruleName := fmt.Sprintf(alertRuleNameFormat, argocd.Name)
+	existingAlertRule := &monitoringv1.PrometheusRule{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: ruleName, Namespace: argocd.Namespace}, existingAlertRule)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Error querying for existing alert rule",
+				"Namespace", argocd.Namespace, "Name", ruleName)
+			return err
+		}
+		if alertRule == nil {
+			return nil
+		}
+
+		reqLogger.Info("Creating new alert rule",
+			"Namespace", alertRule.Namespace, "Name", alertRule.Name)
+		// Set the ArgoCD instance as the owner and controller
+		if err := controllerutil.SetControllerReference(argocd, alertRule, r.Scheme); err != nil {
+			reqLogger.Error(err, "Error setting alert rule owner ref",
+				"Namespace", alertRule.Namespace, "Name", alertRule.Name, "ArgoCD Name", argocd.Name)
+			return err
+		}
+		err = recordResourceEvent(r.Recorder, argocd, resourceCreate, alertRule, r.Client.Create(context.TODO(), alertRule))
+		if err != nil {
+			reqLogger.Error(err, "Error creating a new alert rule",
+				"Namespace", alertRule.Namespace, "Name", alertRule.Name)
+		}
+		return err
+	}
+
+	if alertRule == nil {
+		if !metav1.IsControlledBy(existingAlertRule, argocd) {
+			return nil
+		}
+		reqLogger.Info("Deleting alert rule, all the alerts are disabled",
+			"Namespace", existingAlertRule.Namespace, "Name", existingAlertRule.Name)
+		return recordResourceEvent(r.Recorder, argocd, resourceDelete, existingAlertRule, r.Client.Delete(context.TODO(), existingAlertRule))
+	}
+
+	// Adopt the alert rule created without an owner
+	adopt := metav1.GetControllerOf(existingAlertRule) == nil
+	if !adopt && equality.Semantic.DeepEqual(existingAlertRule.Spec, alertRule.Spec) {
+		return nil
+	}
+	reqLogger.Info("Reconciling existing alert rule",
+		"Namespace", existingAlertRule.Namespace, "Name", existingAlertRule.Name)
+	if adopt {
+		if err := controllerutil.SetControllerReference(argocd, existingAlertRule, r.Scheme); err != nil {
+			return err
+		}
+	}
+	existingAlertRule.Spec = alertRule.Spec
+	err = recordResourceEvent(r.Recorder, argocd, resourceUpdate, existingAlertRule, r.Client.Update(context.TODO(), existingAlertRule))
+	if err != nil {
+		reqLogger.Error(err, "Error updating alert rule",
+			"Namespace", existingAlertRule.Namespace, "Name", existingAlertRule.Name)
+	}
+	return err
+}
+
+// deleteLegacyPrometheusRule deletes the alert rule shared by the instances of the namespace in previous versions of
+// the operator, if controlled by the ArgoCD instance or without owner. Each instance now has its own alert rule.
+func (r *ArgoCDMetricsReconciler) deleteLegacyPrometheusRule(argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	legacyAlertRule := &monitoringv1.PrometheusRule{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: legacyAlertRuleName, Namespace: argocd.Namespace}, legacyAlertRule)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		reqLogger.Error(err, "Error querying for legacy alert rule",
+			"Namespace", argocd.Namespace, "Name", legacyAlertRuleName)
+		return err
+	}
+	if owner := metav1.GetControllerOf(legacyAlertRule); owner != nil && owner.UID != argocd.UID {
+		return nil
+	}
+	reqLogger.Info("Deleting legacy alert rule",
+		"Namespace", legacyAlertRule.Namespace, "Name", legacyAlertRule.Name)
+	err = r.Client.Delete(context.TODO(), legacyAlertRule)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return recordResourceEvent(r.Recorder, argocd, resourceDelete, legacyAlertRule, err)
+}
+
+// metricsApplicationLabelsFlag is the flag of the Argo CD application controller exporting Application labels in
+// the argocd_app_labels metric
+const metricsApplicationLabelsFlag = "--metrics-application-labels"
+
+// metricsApplicationLabels returns the keys of the Application labels exported by the given application controller
+// arguments
+func metricsApplicationLabels(args []string) []string {
+	var keys []string
+	for i, arg := range args {
+		var value string
+		switch {
+		case arg == metricsApplicationLabelsFlag && i+1 < len(args):
+			value = args[i+1]
+		case strings.HasPrefix(arg, metricsApplicationLabelsFlag+"="):
+			value = strings.TrimPrefix(arg, metricsApplicationLabelsFlag+"=")
+		default:
+			continue
+		}
+		keys = append(keys, strings.Split(value, ",")...)
+	}
+	return keys
+}
+
+// metricsApplicationLabelsAnnotation records on an ArgoCD instance the keys of the Application labels the operator
+// added to the --metrics-application-labels arguments of its application controller
+const metricsApplicationLabelsAnnotation = "pipelines.openshift.io/metrics-application-labels"
+
+// metricsFieldManager is the field manager used to server-side apply the application controller arguments exporting
+// the Application labels of the alerts
+const metricsFieldManager = "gitops-operator-argocd-metrics"
+
+// withMetricsApplicationLabels returns the given application controller arguments exporting the given Application
+// label keys, and the keys added by the operator. The keys previously added by the operator are removed first, the
+// keys exported by the user are kept.
+func withMetricsApplicationLabels(args []string, added string, keys []string) ([]string, string) {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		if added != "" && args[i] == metricsApplicationLabelsFlag && i+1 < len(args) && args[i+1] == added {
+			i++
+			continue
+		}
+		result = append(result, args[i])
+	}
+
+	exported := metricsApplicationLabels(result)
+	var missing []string
+	for _, key := range keys {
+		if !slices.Contains(exported, key) && !slices.Contains(missing, key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return result, ""
+	}
+	added = strings.Join(missing, ",")
+	return append(result, metricsApplicationLabelsFlag, added), added
+}
+
+// metricsApplicationLabelsConfig returns the keys of the Application labels to export for the alerts of the ArgoCD
+// instance. The keys added by the operator are kept while the monitoring configuration is invalid.
+func metricsApplicationLabelsConfig(argocd *argoapp.ArgoCD) ([]string, error) {
+	if !metricsEnabled(argocd) {
+		return nil, nil
+	}
+	config, err := monitoringConfig(argocd)
+	if err != nil {
+		if added := argocd.Annotations[metricsApplicationLabelsAnnotation]; added != "" {
+			return strings.Split(added, ","), err
+		}
+		return nil, err
+	}
+	return config.ApplicationLabels, nil
+}
+
+// reconcileMetricsApplicationLabels keeps the --metrics-application-labels arguments of the application controller of
+// the ArgoCD instance in sync with the Application labels configured for its alerts, so that they are exported in the
+// argocd_app_labels metric. Only the arguments are server-side applied, with their own field manager, and the labels
+// set by the user are kept. The arguments of the instances applied by the GitopsService are set by its controller.
+func (r *ArgoCDMetricsReconciler) reconcileMetricsApplicationLabels(argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	if owner := metav1.GetControllerOf(argocd); owner != nil && owner.Kind == "GitopsService" {
+		return nil
+	}
+	keys, err := metricsApplicationLabelsConfig(argocd)
+	if err != nil {
+		// The invalid configuration is reported by reconcileServiceMonitors
+		reqLogger.Info("Invalid monitoring configuration, keeping the exported Application labels", "error", err.Error())
+	}
+
+	added := argocd.Annotations[metricsApplicationLabelsAnnotation]
+	args, newAdded := withMetricsApplicationLabels(argocd.Spec.Controller.ExtraCommandArgs, added, keys)
+	if slices.Equal(args, argocd.Spec.Controller.ExtraCommandArgs) && newAdded == added {
+		return nil
+	}
+
+	reqLogger.Info("Exporting Application labels in the application controller metrics", "Labels", newAdded)
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(argoapp.GroupVersion.WithKind("ArgoCD"))
+	obj.SetNamespace(argocd.Namespace)
+	obj.SetName(argocd.Name)
+	if newAdded != "" {
+		obj.SetAnnotations(map[string]string{metricsApplicationLabelsAnnotation: newAdded})
+	}
+	if len(args) > 0 {
+		if err := unstructured.SetNestedStringSlice(obj.Object, args, "spec", "controller", "extraCommandArgs"); err != nil {
+			return err
+		}
+	}
+	// The arguments are a single field, the values of the other field managers are kept in the applied list
+	err = r.Client.Apply(context.TODO(), client.ApplyConfigurationFromUnstructured(obj),
+		client.FieldOwner(metricsFieldManager), client.ForceOwnership)
+	err = recordResourceEvent(r.Recorder, argocd, resourceUpdate, argocd, err)
+	if err != nil {
+		reqLogger.Error(err, "Error updating the application controller arguments",
+			"Namespace", argocd.Namespace, "Name", argocd.Name)
+	}
+	return err
+}
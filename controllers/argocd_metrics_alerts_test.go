@@ -0,0 +1,271 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestMonitoringConfig_alerts(t *testing.T) {
+	argocd := &argoapp.ArgoCD{}
+	argocd.Annotations = map[string]string{monitoringConfigAnnotation: `
+alerts:
+  ArgoCDRedisDown:
+    enabled: false
+  ArgoCDSyncAlert:
+    for: 15m
+    severity: critical
+`}
+	_, err := monitoringConfig(argocd)
+	assert.NilError(t, err)
+
+	argocd.Annotations[monitoringConfigAnnotation] = `{"alerts": {"ArgoCDDown": {"enabled": false}}}`
+	_, err = monitoringConfig(argocd)
+	assert.ErrorContains(t, err, `unknown alert "ArgoCDDown"`)
+
+	argocd.Annotations[monitoringConfigAnnotation] = `{"alerts": {"ArgoCDSyncAlert": {"severity": "page"}}}`
+	_, err = monitoringConfig(argocd)
+	assert.ErrorContains(t, err, `alert ArgoCDSyncAlert: unknown severity "page"`)
+
+	argocd.Annotations[monitoringConfigAnnotation] = `{"applicationLabels": ["team name"]}`
+	_, err = monitoringConfig(argocd)
+	assert.ErrorContains(t, err, `application label "team name"`)
+}
+
+func TestReconcile_prometheus_rule_config(t *testing.T) {
+	r := newMetricsReconciler(t, "namespace-two", "instance-two", nil)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	name := types.NamespacedName{Name: "instance-two", Namespace: "namespace-two"}
+	setConfig := func(config string) {
+		t.Helper()
+		argocd := &argoapp.ArgoCD{}
+		assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+		argocd.Annotations = map[string]string{monitoringConfigAnnotation: config}
+		assert.NilError(t, r.Client.Update(context.TODO(), argocd))
+		_, err := r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+		assert.NilError(t, err)
+	}
+	getRules := func() map[string]monitoringv1.Rule {
+		t.Helper()
+		rule := &monitoringv1.PrometheusRule{}
+		assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "instance-two-alerts", Namespace: name.Namespace}, rule))
+		assert.Equal(t, len(rule.Spec.Groups), 1)
+		rules := map[string]monitoringv1.Rule{}
+		for _, rule := range rule.Spec.Groups[0].Rules {
+			rules[rule.Alert] = rule
+		}
+		return rules
+	}
+
+	// the alert rule created by a previous version of the operator is upgraded to the whole catalogue
+	argocd := &argoapp.ArgoCD{}
+	assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+	previous := newPrometheusRule(argocd, &argoCDMonitoringConfig{})
+	previous.Spec.Groups[0].Rules = previous.Spec.Groups[0].Rules[:1]
+	assert.NilError(t, r.Client.Create(context.TODO(), previous))
+
+	_, err := r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+	assert.NilError(t, err)
+	rules := getRules()
+	assert.Equal(t, len(rules), len(alerts))
+	assert.Equal(t, rules["ArgoCDClusterConnectionError"].Expr.StrVal,
+		`argocd_cluster_connection_status{namespace="namespace-two",service="instance-two-metrics"} == 0`)
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated"), []string{
+		"Normal Updated Updated Namespace namespace-two",
+		"Normal Created Created Role namespace-two/namespace-two-read",
+		"Normal Created Created RoleBinding namespace-two/namespace-two-prometheus-k8s-read-binding",
+		"Normal Created Created ServiceMonitor namespace-two/instance-two",
+		"Normal Created Created ServiceMonitor namespace-two/instance-two-server",
+		"Normal Created Created ServiceMonitor namespace-two/instance-two-repo-server",
+		"Normal Updated Updated PrometheusRule namespace-two/instance-two-alerts",
+	})
+
+	setConfig(`
+alerts:
+  ArgoCDRedisDown:
+    enabled: false
+  ArgoCDSyncAlert:
+    for: 15m
+    severity: critical
+`)
+	rules = getRules()
+	assert.Equal(t, len(rules), len(alerts)-1)
+	_, ok := rules["ArgoCDRedisDown"]
+	assert.Assert(t, !ok)
+	assert.Equal(t, *rules["ArgoCDSyncAlert"].For, monitoringv1.Duration("15m"))
+	assert.Equal(t, rules["ArgoCDSyncAlert"].Labels["severity"], "critical")
+	assert.Equal(t, *rules["ArgoCDApplicationDegraded"].For, monitoringv1.Duration("15m"))
+	assert.Equal(t, rules["ArgoCDApplicationDegraded"].Labels["severity"], "warning")
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated", "Deleted"), []string{
+		"Normal Updated Updated PrometheusRule namespace-two/instance-two-alerts",
+	})
+
+	// an invalid configuration leaves the alert rule unchanged
+	setConfig(`{"alerts": {"ArgoCDSyncAlert": {"severity": "page"}}}`)
+	assert.Equal(t, getRules()["ArgoCDSyncAlert"].Labels["severity"], "critical")
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated", "Deleted"), []string(nil))
+
+	// disabling all the alerts deletes the alert rule
+	config := "alerts:\n"
+	for _, alert := range alerts {
+		config += "  " + alert.name + ":\n    enabled: false\n"
+	}
+	setConfig(config)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "instance-two-alerts", Namespace: name.Namespace}, &monitoringv1.PrometheusRule{})
+	assert.Assert(t, errors.IsNotFound(err))
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated", "Deleted"), []string{
+		"Normal Deleted Deleted PrometheusRule namespace-two/instance-two-alerts",
+	})
+}
+
+func TestReconcile_legacy_prometheus_rule(t *testing.T) {
+	r := newMetricsReconciler(t, "namespace-two", "instance-two", nil)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	other := &argoapp.ArgoCD{ObjectMeta: v1.ObjectMeta{Name: "instance-three", Namespace: "namespace-two", UID: "instance-three"}}
+	assert.NilError(t, r.Client.Create(context.TODO(), other))
+
+	// the alert rule shared by the instances of the namespace in previous versions of the operator
+	argocd := &argoapp.ArgoCD{}
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "instance-two", Namespace: "namespace-two"}, argocd))
+	legacy := newPrometheusRule(argocd, &argoCDMonitoringConfig{})
+	legacy.Name = legacyAlertRuleName
+	assert.NilError(t, controllerutil.SetControllerReference(other, legacy, r.Scheme))
+	assert.NilError(t, r.Client.Create(context.TODO(), legacy))
+	legacyName := types.NamespacedName{Name: legacyAlertRuleName, Namespace: "namespace-two"}
+
+	// each instance has its own alert rule, the legacy alert rule is deleted by its owner
+	_, err := r.Reconcile(context.TODO(), newRequest("namespace-two", "instance-two"))
+	assert.NilError(t, err)
+	assert.NilError(t, r.Client.Get(context.TODO(), legacyName, &monitoringv1.PrometheusRule{}))
+	_, err = r.Reconcile(context.TODO(), newRequest("namespace-two", "instance-three"))
+	assert.NilError(t, err)
+	err = r.Client.Get(context.TODO(), legacyName, &monitoringv1.PrometheusRule{})
+	assert.Assert(t, errors.IsNotFound(err))
+	assert.DeepEqual(t, recordedEvents(recorder, "Deleted"), []string{
+		"Normal Deleted Deleted PrometheusRule namespace-two/gitops-operator-argocd-alerts",
+	})
+
+	for _, name := range []string{"instance-two-alerts", "instance-three-alerts"} {
+		rule := &monitoringv1.PrometheusRule{}
+		assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "namespace-two"}, rule))
+		assert.Equal(t, len(rule.Spec.Groups[0].Rules), len(alerts))
+	}
+}
+
+func TestMetricsApplicationLabels(t *testing.T) {
+	assert.DeepEqual(t, metricsApplicationLabels(nil), []string(nil))
+	assert.DeepEqual(t, metricsApplicationLabels([]string{
+		"--metrics-application-labels", "team,env",
+		"--status-processors", "20",
+		"--metrics-application-labels=app.kubernetes.io/part-of",
+	}), []string{"team", "env", "app.kubernetes.io/part-of"})
+}
+
+func TestReconcile_prometheus_rule_application_labels(t *testing.T) {
+	r := newMetricsReconciler(t, "namespace-two", "instance-two", nil)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	name := types.NamespacedName{Name: "instance-two", Namespace: "namespace-two"}
+
+	argocd := &argoapp.ArgoCD{}
+	assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+	argocd.Annotations = map[string]string{monitoringConfigAnnotation: `
+applicationLabels: [team, app.kubernetes.io/part-of]
+`}
+	argocd.Spec.Controller.ExtraCommandArgs = []string{"--metrics-application-labels=team"}
+	assert.NilError(t, r.Client.Update(context.TODO(), argocd))
+
+	_, err := r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+	assert.NilError(t, err)
+
+	// the labels of the alerts are exported by the application controller, the labels set by the user are kept
+	assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+	assert.DeepEqual(t, argocd.Spec.Controller.ExtraCommandArgs, []string{
+		"--metrics-application-labels=team",
+		"--metrics-application-labels", "app.kubernetes.io/part-of",
+	})
+	assert.DeepEqual(t, recordedEvents(recorder, "Updated"), []string{
+		"Normal Updated Updated Namespace namespace-two",
+		"Normal Updated Updated ArgoCD namespace-two/instance-two",
+	})
+
+	rule := &monitoringv1.PrometheusRule{}
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "instance-two-alerts", Namespace: name.Namespace}, rule))
+	rules := map[string]string{}
+	for _, rule := range rule.Spec.Groups[0].Rules {
+		rules[rule.Alert] = rule.Expr.StrVal
+	}
+	assert.Equal(t, rules["ArgoCDSyncAlert"],
+		`(argocd_app_info{namespace="namespace-two",service="instance-two-metrics",sync_status="OutOfSync"} > 0) * on (namespace, name) `+
+			`group_left(label_team, label_app_kubernetes_io_part_of) argocd_app_labels{namespace="namespace-two",service="instance-two-metrics"}`)
+	assert.Equal(t, rules["ArgoCDApplicationDegraded"],
+		`(argocd_app_info{namespace="namespace-two",service="instance-two-metrics",health_status="Degraded"} > 0) * on (namespace, name) `+
+			`group_left(label_team, label_app_kubernetes_io_part_of) argocd_app_labels{namespace="namespace-two",service="instance-two-metrics"}`)
+	// the alerts of the Argo CD components are not joined with the Application labels
+	assert.Equal(t, rules["ArgoCDClusterConnectionError"],
+		`argocd_cluster_connection_status{namespace="namespace-two",service="instance-two-metrics"} == 0`)
+
+	// the arguments are updated once
+	_, err = r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, recordedEvents(recorder, "Updated"), []string(nil))
+
+	// an invalid configuration keeps the labels added by the operator
+	assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+	assert.Equal(t, argocd.Annotations[metricsApplicationLabelsAnnotation], "app.kubernetes.io/part-of")
+	argocd.Annotations[monitoringConfigAnnotation] = `{"applicationLabels": "team"}`
+	assert.NilError(t, r.Client.Update(context.TODO(), argocd))
+	_, err = r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+	assert.NilError(t, err)
+	assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+	assert.Equal(t, len(argocd.Spec.Controller.ExtraCommandArgs), 3)
+
+	// the labels removed from the configuration are removed from the arguments
+	argocd.Annotations[monitoringConfigAnnotation] = `{"applicationLabels": ["team"]}`
+	assert.NilError(t, r.Client.Update(context.TODO(), argocd))
+	_, err = r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+	assert.NilError(t, err)
+	assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+	assert.DeepEqual(t, argocd.Spec.Controller.ExtraCommandArgs, []string{"--metrics-application-labels=team"})
+	_, found := argocd.Annotations[metricsApplicationLabelsAnnotation]
+	assert.Assert(t, !found)
+}
+
+func TestWithMetricsApplicationLabels(t *testing.T) {
+	args, added := withMetricsApplicationLabels([]string{"--status-processors", "20"}, "", []string{"team", "env", "team"})
+	assert.DeepEqual(t, args, []string{"--status-processors", "20", "--metrics-application-labels", "team,env"})
+	assert.Equal(t, added, "team,env")
+
+	// the keys previously added are replaced, the keys exported by the user are not added again
+	args, added = withMetricsApplicationLabels([]string{"--metrics-application-labels", "team,env", "--metrics-application-labels=env"},
+		"team,env", []string{"team", "env"})
+	assert.DeepEqual(t, args, []string{"--metrics-application-labels=env", "--metrics-application-labels", "team"})
+	assert.Equal(t, added, "team")
+
+	args, added = withMetricsApplicationLabels([]string{"--metrics-application-labels", "team"}, "team", nil)
+	assert.DeepEqual(t, args, []string(nil))
+	assert.Equal(t, added, "")
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	argocdutil "github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+	"github.com/go-logr/logr"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// monitoringConfigAnnotation holds the monitoring configuration of an ArgoCD instance, as YAML or JSON
+	monitoringConfigAnnotation = "pipelines.openshift.io/monitoring"
+	// eventReasonInvalidMonitoringConfig is the reason of the events recorded when the monitoring configuration
+	// of an ArgoCD instance cannot be used
+	eventReasonInvalidMonitoringConfig = "InvalidMonitoringConfig"
+)
+
+// metricsComponent is an Argo CD component exposing metrics, scraped by a ServiceMonitor
+type metricsComponent struct {
+	// name of the component in the monitoring configuration
+	name string
+	// monitorSuffix and serviceSuffix are appended to the name of the ArgoCD instance to get the name of the
+	// ServiceMonitor and the app.kubernetes.io/name label of the Service exposing the metrics
+	monitorSuffix string
+	serviceSuffix string
+	// enabledByDefault is true if the component is scraped when the monitoring configuration does not set it
+	enabledByDefault bool
+}
+
+// metricsComponents are the Argo CD components that can be scraped. The application controller, server and repo
+// server are scraped by default.
+var metricsComponents = []metricsComponent{
+	{name: "applicationController", monitorSuffix: "", serviceSuffix: "-metrics", enabledByDefault: true},
+	{name: "server", monitorSuffix: "-server", serviceSuffix: "-server-metrics", enabledByDefault: true},
+	{name: "repoServer", monitorSuffix: "-repo-server", serviceSuffix: "-repo-server", enabledByDefault: true},
+	{name: "applicationSetController", monitorSuffix: "-applicationset-controller", serviceSuffix: "-applicationset-controller"},
+	{name: "notificationsController", monitorSuffix: "-notifications-controller", serviceSuffix: "-notifications-controller-metrics"},
+	{name: "dex", monitorSuffix: "-dex-server", serviceSuffix: "-dex-server"},
+}
+
+// argoCDMonitoringConfig is the monitoring configuration of an ArgoCD instance
+type argoCDMonitoringConfig struct {
+	// Components configures the ServiceMonitors of the Argo CD components, by component name
+	Components map[string]componentMonitoringConfig `json:"components,omitempty"`
+	// Alerts configures the alerts of the PrometheusRule of the instance, by alert name
+	Alerts map[string]alertConfig `json:"alerts,omitempty"`
+	// ApplicationLabels are the keys of the Application labels added to the application alerts
+	ApplicationLabels []string `json:"applicationLabels,omitempty"`
+	// InstanceDashboards adds dashboards of the Argo CD components restricted to the instance to the console
+	InstanceDashboards bool `json:"instanceDashboards,omitempty"`
+}
+
+// componentMonitoringConfig configures the ServiceMonitor of an Argo CD component
+type componentMonitoringConfig struct {
+	// Enabled selects if the component is scraped, the default depends on the component
+	Enabled *bool `json:"enabled,omitempty"`
+	// Port is the name of the Service port exposing the metrics, metrics by default
+	Port string `json:"port,omitempty"`
+	// Interval at which the metrics are scraped, the interval of Prometheus by default
+	Interval monitoringv1.Duration `json:"interval,omitempty"`
+	// ScrapeTimeout is the timeout of a scrape, the timeout of Prometheus by default
+	ScrapeTimeout monitoringv1.Duration `json:"scrapeTimeout,omitempty"`
+	// SampleLimit is the number of samples accepted per scrape
+	SampleLimit *uint64 `json:"sampleLimit,omitempty"`
+	// Relabelings are applied to the targets before scraping
+	Relabelings []monitoringv1.RelabelConfig `json:"relabelings,omitempty"`
+	// MetricRelabelings are applied to the samples before ingestion
+	MetricRelabelings []monitoringv1.RelabelConfig `json:"metricRelabelings,omitempty"`
+}
+
+// monitoringConfig returns the monitoring configuration of the ArgoCD instance, set in its
+// pipelines.openshift.io/monitoring annotation
+func monitoringConfig(argocd *argoapp.ArgoCD) (*argoCDMonitoringConfig, error) {
+	config := &argoCDMonitoringConfig{}
+	value, ok := argocd.Annotations[monitoringConfigAnnotation]
+	if !ok {
+		return config, nil
+	}
+	if err := yaml.UnmarshalStrict([]byte(value), config); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", monitoringConfigAnnotation, err)
+	}
+	for name := range config.Components {
+		if !isMetricsComponent(name) {
+			return nil, fmt.Errorf("invalid %s annotation: unknown component %q", monitoringConfigAnnotation, name)
+		}
+	}
+	for name, alertConfig := range config.Alerts {
+		if !isAlert(name) {
+			return nil, fmt.Errorf("invalid %s annotation: unknown alert %q", monitoringConfigAnnotation, name)
+		}
+		if err := alertConfig.validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: alert %s: %w", monitoringConfigAnnotation, name, err)
+		}
+	}
+	for _, key := range config.ApplicationLabels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %s annotation: application label %q: %s", monitoringConfigAnnotation, key, strings.Join(errs, ", "))
+		}
+	}
+	return config, nil
+}
+
+func isMetricsComponent(name string) bool {
+	for _, component := range metricsComponents {
+		if component.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnabled returns true if the component is scraped
+func (c componentMonitoringConfig) isEnabled(component metricsComponent) bool {
+	if c.Enabled == nil {
+		return component.enabledByDefault
+	}
+	return *c.Enabled
+}
+
+// apply sets the configuration of the component on its ServiceMonitor
+func (c componentMonitoringConfig) apply(serviceMonitor *monitoringv1.ServiceMonitor) {
+	endpoint := &serviceMonitor.Spec.Endpoints[0]
+	if c.Port != "" {
+		endpoint.Port = c.Port
+	}
+	endpoint.Interval = c.Interval
+	endpoint.ScrapeTimeout = c.ScrapeTimeout
+	endpoint.RelabelConfigs = c.Relabelings
+	endpoint.MetricRelabelConfigs = c.MetricRelabelings
+	serviceMonitor.Spec.SampleLimit = c.SampleLimit
+}
+
+// reconcileServiceMonitors creates or updates the ServiceMonitors of the Argo CD components enabled in the monitoring
+// configuration of the instance, and deletes the ones of the disabled components. An invalid configuration is
+// reported as a Warning event and leaves the ServiceMonitors and the PrometheusRule unchanged.
+func (r *ArgoCDMetricsReconciler) reconcileServiceMonitors(argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	config, err := monitoringConfig(argocd)
+	if err != nil {
+		reqLogger.Error(err, "Invalid monitoring configuration, the ServiceMonitors and alerts are not reconciled")
+		r.Recorder.Eventf(argocd, corev1.EventTypeWarning, eventReasonInvalidMonitoringConfig, "ServiceMonitors and alerts are not reconciled: %v", err)
+		return nil
+	}
+
+	for _, component := range metricsComponents {
+		componentConfig := config.Components[component.name]
+		name := argocd.Name + component.monitorSuffix
+		if !componentConfig.isEnabled(component) {
+			if err := r.deleteOwnedServiceMonitor(argocd, name, reqLogger); err != nil {
+				return err
+			}
+			continue
+		}
+		serviceMonitor := newServiceMonitor(argocd.Namespace, name, argocd.Name+component.serviceSuffix, r.Prometheus.serviceMonitorLabels())
+		componentConfig.apply(serviceMonitor)
+		if err := r.reconcileServiceMonitor(argocd, serviceMonitor, reqLogger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileServiceMonitor creates or updates the given ServiceMonitor, owned by the ArgoCD instance
+func (r *ArgoCDMetricsReconciler) reconcileServiceMonitor(argocd *argoapp.ArgoCD, serviceMonitor *monitoringv1.ServiceMonitor,
+	reqLogger logr.Logger) error {
+	if err := controllerutil.SetControllerReference(argocd, serviceMonitor, r.Scheme); err != nil {
+		return err
+	}
+
+	existing := &monitoringv1.ServiceMonitor{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: serviceMonitor.Name, Namespace: serviceMonitor.Namespace}, existing)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Error querying for ServiceMonitor", "Namespace", serviceMonitor.Namespace, "Name", serviceMonitor.Name)
+			return err
+		}
+		reqLogger.Info("Creating a new ServiceMonitor instance", "Namespace", serviceMonitor.Namespace, "Name", serviceMonitor.Name)
+		return recordResourceEvent(r.Recorder, argocd, resourceCreate, serviceMonitor, r.Client.Create(context.TODO(), serviceMonitor))
+	}
+
+	if containsStringMap(existing.Labels, serviceMonitor.Labels) && equality.Semantic.DeepEqual(existing.Spec, serviceMonitor.Spec) {
+		return nil
+	}
+	reqLogger.Info("Reconciling existing ServiceMonitor", "Namespace", existing.Namespace, "Name", existing.Name)
+	existing.Labels = argocdutil.AppendStringMap(existing.Labels, serviceMonitor.Labels)
+	existing.Spec = serviceMonitor.Spec
+	return recordResourceEvent(r.Recorder, argocd, resourceUpdate, existing, r.Client.Update(context.TODO(), existing))
+}
+
+// deleteOwnedServiceMonitor deletes the ServiceMonitor of the given name if it is controlled by the ArgoCD instance
+func (r *ArgoCDMetricsReconciler) deleteOwnedServiceMonitor(argocd *argoapp.ArgoCD, name string, reqLogger logr.Logger) error {
+	existing := &monitoringv1.ServiceMonitor{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: argocd.Namespace}, existing)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !metav1.IsControlledBy(existing, argocd) {
+		return nil
+	}
+	reqLogger.Info("Deleting ServiceMonitor of a disabled component", "Namespace", existing.Namespace, "Name", existing.Name)
+	return recordResourceEvent(r.Recorder, argocd, resourceDelete, existing, r.Client.Delete(context.TODO(), existing))
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+)
+
+func TestMonitoringConfig(t *testing.T) {
+	argocd := &argoapp.ArgoCD{}
+	config, err := monitoringConfig(argocd)
+	assert.NilError(t, err)
+	assert.Equal(t, len(config.Components), 0)
+
+	argocd.Annotations = map[string]string{monitoringConfigAnnotation: `
+components:
+  dex:
+    enabled: true
+    interval: 1m
+    sampleLimit: 1000
+`}
+	config, err = monitoringConfig(argocd)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, config.Components["dex"], componentMonitoringConfig{
+		Enabled:     ptr.To(true),
+		Interval:    "1m",
+		SampleLimit: ptr.To(uint64(1000)),
+	})
+
+	argocd.Annotations[monitoringConfigAnnotation] = `{"components": {"grafana": {"enabled": true}}}`
+	_, err = monitoringConfig(argocd)
+	assert.ErrorContains(t, err, `unknown component "grafana"`)
+
+	argocd.Annotations[monitoringConfigAnnotation] = `{"components": {"dex": {"enable": true}}}`
+	_, err = monitoringConfig(argocd)
+	assert.ErrorContains(t, err, `unknown field "enable"`)
+}
+
+func TestReconcile_service_monitors_config(t *testing.T) {
+	r := newMetricsReconciler(t, "namespace-two", "instance-two", nil)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	name := types.NamespacedName{Name: "instance-two", Namespace: "namespace-two"}
+	setConfig := func(config string) {
+		t.Helper()
+		argocd := &argoapp.ArgoCD{}
+		assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+		argocd.Annotations = map[string]string{monitoringConfigAnnotation: config}
+		assert.NilError(t, r.Client.Update(context.TODO(), argocd))
+		_, err := r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+		assert.NilError(t, err)
+	}
+	getServiceMonitor := func(name string) (*monitoringv1.ServiceMonitor, error) {
+		serviceMonitor := &monitoringv1.ServiceMonitor{}
+		return serviceMonitor, r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "namespace-two"}, serviceMonitor)
+	}
+
+	_, err := r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+	assert.NilError(t, err)
+	recordedEvents(recorder)
+
+	setConfig(`
+components:
+  repoServer:
+    enabled: false
+  server:
+    interval: 30s
+    scrapeTimeout: 10s
+    metricRelabelings:
+    - action: drop
+      sourceLabels: [__name__]
+      regex: grpc_.*
+  dex:
+    enabled: true
+    port: http-metrics
+`)
+	_, err = getServiceMonitor("instance-two-repo-server")
+	assert.Assert(t, errors.IsNotFound(err))
+
+	serviceMonitor, err := getServiceMonitor("instance-two-server")
+	assert.NilError(t, err)
+	endpoint := serviceMonitor.Spec.Endpoints[0]
+	assert.Equal(t, endpoint.Port, "metrics")
+	assert.Equal(t, endpoint.Interval, monitoringv1.Duration("30s"))
+	assert.Equal(t, endpoint.ScrapeTimeout, monitoringv1.Duration("10s"))
+	assert.DeepEqual(t, endpoint.MetricRelabelConfigs, []monitoringv1.RelabelConfig{
+		{Action: "drop", SourceLabels: []monitoringv1.LabelName{"__name__"}, Regex: "grpc_.*"},
+	})
+
+	serviceMonitor, err = getServiceMonitor("instance-two-dex-server")
+	assert.NilError(t, err)
+	assert.Equal(t, serviceMonitor.Spec.Selector.MatchLabels["app.kubernetes.io/name"], "instance-two-dex-server")
+	assert.Equal(t, serviceMonitor.Spec.Endpoints[0].Port, "http-metrics")
+	assert.Equal(t, serviceMonitor.OwnerReferences[0].Name, "instance-two")
+
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated", "Deleted"), []string{
+		"Normal Updated Updated ServiceMonitor namespace-two/instance-two-server",
+		"Normal Deleted Deleted ServiceMonitor namespace-two/instance-two-repo-server",
+		"Normal Created Created ServiceMonitor namespace-two/instance-two-dex-server",
+	})
+
+	// an invalid configuration leaves the ServiceMonitors unchanged
+	setConfig(`{"components": {"dex": {"enabled": "yes"}}}`)
+	_, err = getServiceMonitor("instance-two-dex-server")
+	assert.NilError(t, err)
+	events := recordedEvents(recorder, eventReasonInvalidMonitoringConfig, "Created", "Updated", "Deleted")
+	assert.Equal(t, len(events), 1)
+	assert.Assert(t, strings.HasPrefix(events[0], "Warning InvalidMonitoringConfig"))
+}
@@ -28,25 +28,30 @@ import (
 	argocdutil "github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 	"github.com/go-logr/logr"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/utils/ptr"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
 	readRoleNameFormat         = "%s-read"
 	readRoleBindingNameFormat  = "%s-prometheus-k8s-read-binding"
-	alertRuleName              = "gitops-operator-argocd-alerts"
+	alertRuleNameFormat        = "%s-alerts"
+	legacyAlertRuleName        = "gitops-operator-argocd-alerts"
 	dashboardNamespace         = "openshift-config-managed"
 	dashboardFolder            = "dashboards"
 	operatorMetricsServiceName = "openshift-gitops-operator-metrics-service"
@@ -58,6 +63,13 @@ type ArgoCDMetricsReconciler struct {
 	// that reads objects from the cache and writes to the apiserver
 	Client client.Client
 	Scheme *runtime.Scheme
+	// Recorder records the events reported on the ArgoCD instances
+	Recorder record.EventRecorder
+	// Prometheus configures the Prometheus stack scraping the metrics of the ArgoCD instances
+	Prometheus PrometheusConfig
+
+	// skipEvents reports the dashboards that are skipped once, not on every reconcile
+	skipEvents *skipEvents
 }
 
 // embed json dashboards
@@ -71,8 +83,17 @@ var _ reconcile.Reconciler = &ArgoCDMetricsReconciler{}
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ArgoCDMetricsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.skipEvents = newSkipEvents()
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&argoapp.ArgoCD{}).
+		Owns(&monitoringv1.ServiceMonitor{}).
+		Owns(&monitoringv1.PrometheusRule{}).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.argoCDRequestsForDashboardSource),
+			builder.WithPredicates(dashboardSourcePredicate)).
+		Watches(&pipelinesv1beta1.GitopsService{},
+			handler.EnqueueRequestsFromMapFunc(r.argoCDRequestsForGitopsService),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
 
@@ -88,9 +109,9 @@ func (r *ArgoCDMetricsReconciler) Reconcile(ctx context.Context, request reconci
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Namespace not found, could have been deleted after reconcile request.
-			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Owned objects are automatically garbage collected, the dashboards are deleted once unused.
 			// Return and don't requeue
-			return reconcile.Result{}, nil
+			return reconcile.Result{}, r.deleteUnusedDashboards(deletedArgoCD(request), reqLogger)
 		}
 		reqLogger.Error(err, "Error getting namespace",
 			"Namespace", request.Namespace)
@@ -102,153 +123,78 @@ func (r *ArgoCDMetricsReconciler) Reconcile(ctx context.Context, request reconci
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// ArgoCD not found, could have been deleted after reconcile request.
-			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Owned objects are automatically garbage collected, the monitoring label of the namespace
+			// and the dashboards are reverted and deleted once unused.
 			// Return and don't requeue
-			return reconcile.Result{}, nil
+			if err := r.reconcileMonitoringLabel(ctx, &namespace, deletedArgoCD(request), false, reqLogger); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{}, r.deleteUnusedDashboards(deletedArgoCD(request), reqLogger)
 		}
 		reqLogger.Error(err, "Error getting ArgoCD instsance")
 		return reconcile.Result{}, err
 	}
 
-	const clusterMonitoringLabel = "openshift.io/cluster-monitoring"
-	const userDefinedMonitoringLabel = "openshift.io/user-monitoring"
-	var labelVal, monitoringLabel string
-	var exists bool
-	if strings.HasPrefix(namespace.Name, "openshift-") {
-		labelVal, exists = namespace.Labels[clusterMonitoringLabel]
-		monitoringLabel = clusterMonitoringLabel
-	} else {
-		labelVal, exists = namespace.Labels[userDefinedMonitoringLabel]
-		monitoringLabel = userDefinedMonitoringLabel
-	}
-
-	if argocd.Spec.Monitoring.DisableMetrics == nil || !*argocd.Spec.Monitoring.DisableMetrics {
-		if !exists || labelVal != "true" {
-			if namespace.Labels == nil {
-				namespace.Labels = make(map[string]string)
-			}
-			namespace.Labels[monitoringLabel] = "true"
-			err = r.Client.Update(ctx, &namespace)
-			if err != nil {
-				reqLogger.Error(err, "Error updating namespace",
-					"Namespace", namespace.Name)
-				return reconcile.Result{}, err
-			}
-		}
-
-		// Create role to grant read permission to the openshift metrics stack
-		err = r.createReadRoleIfAbsent(request.Namespace, argocd, reqLogger)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
-
-		// Create role binding to grant read permission to the openshift metrics stack
-		err = r.createReadRoleBindingIfAbsent(request.Namespace, argocd, reqLogger)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
-
-		// Create ServiceMonitor for ArgoCD application metrics
-		serviceMonitorLabel := fmt.Sprintf("%s-metrics", request.Name)
-		serviceMonitorName := request.Name
-		err = r.createServiceMonitorIfAbsent(request.Namespace, argocd, serviceMonitorName, serviceMonitorLabel, reqLogger)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
-
-		// Create ServiceMonitor for ArgoCD API server metrics
-		serviceMonitorLabel = fmt.Sprintf("%s-server-metrics", request.Name)
-		serviceMonitorName = fmt.Sprintf("%s-server", request.Name)
-		err = r.createServiceMonitorIfAbsent(request.Namespace, argocd, serviceMonitorName, serviceMonitorLabel, reqLogger)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
-
-		// Create ServiceMonitor for ArgoCD repo server metrics
-		serviceMonitorLabel = fmt.Sprintf("%s-repo-server", request.Name)
-		serviceMonitorName = fmt.Sprintf("%s-repo-server", request.Name)
-		err = r.createServiceMonitorIfAbsent(request.Namespace, argocd, serviceMonitorName, serviceMonitorLabel, reqLogger)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
-
-		// Create alert rule
-		err = r.createPrometheusRuleIfAbsent(request.Namespace, argocd, reqLogger)
+	if !metricsEnabled(argocd) {
+		// Delete the monitoring resources of the instance once deleted or its metrics disabled, then let the
+		// deletion proceed
+		err = r.deleteMetricsResources(ctx, &namespace, argocd, reqLogger)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
+		return reconcile.Result{}, r.removeMetricsFinalizer(ctx, argocd, reqLogger)
+	}
 
-		err = r.reconcileDashboards(reqLogger)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
+	// Delete the monitoring resources along with the instance
+	err = r.addMetricsFinalizer(ctx, argocd, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
-		err = r.reconcileOperatorMetricsServiceMonitor(reqLogger)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
-	} else {
-		if exists {
-			namespace.Labels[monitoringLabel] = "false"
-			err = r.Client.Update(ctx, &namespace)
-			if err != nil {
-				reqLogger.Error(err, "Error updating namespace",
-					"Namespace", namespace.Name)
-				return reconcile.Result{}, err
-			}
+	// Set the monitoring label of the namespace, unless already set
+	err = r.reconcileMonitoringLabel(ctx, &namespace, argocd, true, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
-			// Delete role to grant read permission to the openshift metrics stack
-			err = r.Client.Delete(context.TODO(), &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: request.Namespace, Name: fmt.Sprintf(readRoleNameFormat, request.Namespace)}})
-			if err != nil {
-				if !errors.IsNotFound(err) {
-					reqLogger.Error(err, "Error deleting role in ",
-						"Namespace", request.Namespace)
-					return reconcile.Result{}, err
-				}
-			}
+	// Create role to grant read permission to the openshift metrics stack
+	err = r.createReadRoleIfAbsent(request.Namespace, argocd, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
-			// Delete role binding to grant read permission to the openshift metrics stack
-			err = r.Client.Delete(context.TODO(), &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: request.Namespace, Name: fmt.Sprintf(readRoleBindingNameFormat, request.Namespace)}})
-			if err != nil {
-				if !errors.IsNotFound(err) {
-					reqLogger.Error(err, "Error deleting rolebinding in ",
-						"Namespace", request.Namespace)
-					return reconcile.Result{}, err
-				}
-			}
+	// Create role binding to grant read permission to the openshift metrics stack
+	err = r.reconcileReadRoleBinding(request.Namespace, argocd, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
-			// Delete ServiceMonitor for ArgoCD application metrics
-			serviceMonitorName := request.Name
-			err = r.deleteServiceMonitor(serviceMonitorName, request.Namespace, reqLogger)
-			if err != nil {
-				return reconcile.Result{}, err
-			}
+	// Create or update the ServiceMonitors of the ArgoCD components, as configured for the instance
+	err = r.reconcileServiceMonitors(argocd, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
-			// Delete ServiceMonitor for ArgoCD API server metrics
-			serviceMonitorName = fmt.Sprintf("%s-server", request.Name)
-			err = r.deleteServiceMonitor(serviceMonitorName, request.Namespace, reqLogger)
-			if err != nil {
-				return reconcile.Result{}, err
-			}
+	// Export the Application labels of the alerts in the metrics of the application controller
+	err = r.reconcileMetricsApplicationLabels(argocd, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
-			// Delete ServiceMonitor for ArgoCD repo server metrics
-			serviceMonitorName = fmt.Sprintf("%s-repo-server", request.Name)
-			err = r.deleteServiceMonitor(serviceMonitorName, request.Namespace, reqLogger)
-			if err != nil {
-				return reconcile.Result{}, err
-			}
+	// Create or update the alert rule, as configured for the instance
+	err = r.reconcilePrometheusRule(argocd, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
-			// Delete alert rule
-			err = r.Client.Delete(context.TODO(), &monitoringv1.PrometheusRule{ObjectMeta: metav1.ObjectMeta{Namespace: request.Namespace, Name: alertRuleName}})
-			if err != nil {
-				if !errors.IsNotFound(err) {
-					reqLogger.Error(err, "Error deleting prometheus in ",
-						"Namespace", request.Namespace)
-					return reconcile.Result{}, err
-				}
-			}
+	err = r.reconcileDashboards(argocd, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
 
-		}
+	err = r.reconcileOperatorMetricsServiceMonitor(argocd, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
 
 	return reconcile.Result{}, nil
@@ -274,7 +220,7 @@ func (r *ArgoCDMetricsReconciler) createReadRoleIfAbsent(namespace string, argoc
 			return err
 		}
 
-		err = r.Client.Create(context.TODO(), readRole)
+		err = recordResourceEvent(r.Recorder, argocd, resourceCreate, readRole, r.Client.Create(context.TODO(), readRole))
 		if err != nil {
 			reqLogger.Error(err, "Error creating a new read role",
 				"Namespace", readRole.Namespace, "Name", readRole.Name)
@@ -288,14 +234,24 @@ func (r *ArgoCDMetricsReconciler) createReadRoleIfAbsent(namespace string, argoc
 	return err
 }
 
-func (r *ArgoCDMetricsReconciler) createReadRoleBindingIfAbsent(namespace string, argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
-	readRoleBinding := newReadRoleBinding(namespace)
+func (r *ArgoCDMetricsReconciler) reconcileReadRoleBinding(namespace string, argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	readRoleBinding := newReadRoleBinding(namespace, r.Prometheus.serviceAccount(), r.Prometheus.namespace())
 	existingReadRoleBinding := &rbacv1.RoleBinding{}
 	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: readRoleBinding.Name, Namespace: readRoleBinding.Namespace}, existingReadRoleBinding)
 	if err == nil {
-		reqLogger.Info("Read role binding already exists",
-			"Namespace", readRoleBinding.Namespace, "Name", readRoleBinding.Name)
-		return nil
+		if equality.Semantic.DeepEqual(existingReadRoleBinding.Subjects, readRoleBinding.Subjects) {
+			reqLogger.Info("Read role binding already exists",
+				"Namespace", readRoleBinding.Namespace, "Name", readRoleBinding.Name)
+			return nil
+		}
+		// Grant the read permission to the configured Prometheus, the role is left unchanged
+		existingReadRoleBinding.Subjects = readRoleBinding.Subjects
+		err = recordResourceEvent(r.Recorder, argocd, resourceUpdate, existingReadRoleBinding, r.Client.Update(context.TODO(), existingReadRoleBinding))
+		if err != nil {
+			reqLogger.Error(err, "Error updating read role binding",
+				"Namespace", readRoleBinding.Namespace, "Name", readRoleBinding.Name)
+		}
+		return err
 	}
 	if errors.IsNotFound(err) {
 		reqLogger.Info("Creating new read role binding",
@@ -308,7 +264,7 @@ func (r *ArgoCDMetricsReconciler) createReadRoleBindingIfAbsent(namespace string
 			return err
 		}
 
-		err = r.Client.Create(context.TODO(), readRoleBinding)
+		err = recordResourceEvent(r.Recorder, argocd, resourceCreate, readRoleBinding, r.Client.Create(context.TODO(), readRoleBinding))
 		if err != nil {
 			reqLogger.Error(err, "Error creating a new read role binding",
 				"Namespace", readRoleBinding.Namespace, "Name", readRoleBinding.Name)
@@ -322,44 +278,12 @@ func (r *ArgoCDMetricsReconciler) createReadRoleBindingIfAbsent(namespace string
 	return err
 }
 
-func (r *ArgoCDMetricsReconciler) createServiceMonitorIfAbsent(namespace string, argocd *argoapp.ArgoCD, name, serviceMonitorLabel string, reqLogger logr.Logger) error {
-	existingServiceMonitor := &monitoringv1.ServiceMonitor{}
-	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, existingServiceMonitor)
-	if err == nil {
-		reqLogger.Info("A ServiceMonitor instance already exists",
-			"Namespace", existingServiceMonitor.Namespace, "Name", existingServiceMonitor.Name)
-		return nil
-	}
-	if errors.IsNotFound(err) {
-		serviceMonitor := newServiceMonitor(namespace, name, serviceMonitorLabel)
-		reqLogger.Info("Creating a new ServiceMonitor instance",
-			"Namespace", serviceMonitor.Namespace, "Name", serviceMonitor.Name)
+func (r *ArgoCDMetricsReconciler) deleteServiceMonitor(name string, namespace string, argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
 
-		// Set the ArgoCD instance as the owner and controller
-		if err := controllerutil.SetControllerReference(argocd, serviceMonitor, r.Scheme); err != nil {
-			reqLogger.Error(err, "Error setting read role owner ref",
-				"Namespace", serviceMonitor.Namespace, "Name", serviceMonitor.Name, "ArgoCD Name", argocd.Name)
-			return err
-		}
-
-		err = r.Client.Create(context.TODO(), serviceMonitor)
-		if err != nil {
-			reqLogger.Error(err, "Error creating a new ServiceMonitor instance",
-				"Namespace", serviceMonitor.Namespace, "Name", serviceMonitor.Name)
-			return err
-		}
-
-		return nil
-	}
-	reqLogger.Error(err, "Error querying for ServiceMonitor", "Namespace", namespace, "Name", name)
-	return err
-}
-
-func (r *ArgoCDMetricsReconciler) deleteServiceMonitor(name string, namespace string, reqLogger logr.Logger) error {
-
-	err := r.Client.Delete(context.TODO(), &monitoringv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}})
-	if err != nil {
-		if !errors.IsNotFound(err) {
+	serviceMonitor := &monitoringv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	err := r.Client.Delete(context.TODO(), serviceMonitor)
+	if !errors.IsNotFound(err) {
+		if err = recordResourceEvent(r.Recorder, argocd, resourceDelete, serviceMonitor, err); err != nil {
 			reqLogger.Error(err, "Error deleting servicemonitor",
 				"Namespace", namespace)
 			return err
@@ -369,7 +293,7 @@ func (r *ArgoCDMetricsReconciler) deleteServiceMonitor(name string, namespace st
 
 }
 
-func (r *ArgoCDMetricsReconciler) reconcileOperatorMetricsServiceMonitor(reqLogger logr.Logger) error {
+func (r *ArgoCDMetricsReconciler) reconcileOperatorMetricsServiceMonitor(argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
 
 	data, err := os.ReadFile(operatorPodNamespacePath)
 	if err != nil {
@@ -400,53 +324,27 @@ func (r *ArgoCDMetricsReconciler) reconcileOperatorMetricsServiceMonitor(reqLogg
 	currentServerName := existingServiceMonitor.Spec.Endpoints[0].TLSConfig.ServerName
 	if currentServerName == nil || *currentServerName != desiredMetricsServerName {
 		existingServiceMonitor.Spec.Endpoints[0].TLSConfig.ServerName = &desiredMetricsServerName
-		return r.Client.Update(context.TODO(), existingServiceMonitor)
+		return recordResourceEvent(r.Recorder, argocd, resourceUpdate, existingServiceMonitor, r.Client.Update(context.TODO(), existingServiceMonitor))
 	}
 
 	return nil
 }
 
-func (r *ArgoCDMetricsReconciler) createPrometheusRuleIfAbsent(namespace string, argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
-	alertRule := newPrometheusRule(namespace)
-	existingAlertRule := &monitoringv1.PrometheusRule{}
-	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: alertRule.Name, Namespace: alertRule.Namespace}, existingAlertRule)
-	if err == nil {
-		reqLogger.Info("An alert rule instance already exists",
-			"Namespace", existingAlertRule.Namespace, "Name", existingAlertRule.Name)
-		return nil
-	}
-	if errors.IsNotFound(err) {
-		reqLogger.Info("Creating new alert rule",
-			"Namespace", alertRule.Namespace, "Name", alertRule.Name)
-
-		// Set the ArgoCD instance as the owner and controller
-		if err := controllerutil.SetControllerReference(argocd, alertRule, r.Scheme); err != nil {
-			reqLogger.Error(err, "Error setting read role owner ref",
-				"Namespace", alertRule.Namespace, "Name", alertRule.Name, "ArgoCD Name", argocd.Name)
-			return err
-		}
-
-		err := r.Client.Create(context.TODO(), alertRule)
-		if err != nil {
-			reqLogger.Error(err, "Error creating a new alert rule",
-				"Namespace", alertRule.Namespace, "Name", alertRule.Name)
-			return err
-		}
-
-		return nil
-	}
-	reqLogger.Error(err, "Error querying for existing alert rule",
-		"Namespace", namespace, "Name", alertRuleName)
-	return err
-}
-
-func (r *ArgoCDMetricsReconciler) reconcileDashboards(reqLogger logr.Logger) error {
+func (r *ArgoCDMetricsReconciler) reconcileDashboards(argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
 	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: dashboardNamespace}, &corev1.Namespace{})
 	if err != nil {
 		reqLogger.Info("Monitoring dashboards are not supported on this cluster, skipping dashboard installation",
 			"Namespace", dashboardNamespace)
+		r.skipEvents.record(r.Recorder, argocd, "dashboards", "Monitoring dashboards are not installed: namespace %s not found", dashboardNamespace)
 		return nil
 	}
+	r.skipEvents.reconciled(argocd, "dashboards")
+
+	states, err := r.dashboardStates(context.TODO())
+	if err != nil {
+		reqLogger.Error(err, "Error getting the state of the dashboards")
+		return err
+	}
 
 	entries, err := dashboards.ReadDir(dashboardFolder)
 	if err != nil {
@@ -458,6 +356,17 @@ func (r *ArgoCDMetricsReconciler) reconcileDashboards(reqLogger logr.Logger) err
 		reqLogger.Info("Processing dashboard", "Namespace", dashboardNamespace, "Name", entry.Name())
 
 		if !entry.IsDir() {
+			switch name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())); states[name] {
+			case pipelinesv1beta1.DashboardUnmanaged:
+				reqLogger.Info("Dashboard is unmanaged, skipping", "Namespace", dashboardNamespace, "Name", name)
+				continue
+			case pipelinesv1beta1.DashboardAbsent:
+				if err := r.deleteEmbeddedDashboard(argocd, name, reqLogger); err != nil {
+					return err
+				}
+				continue
+			}
+
 			dashboard, err := newDashboardConfigMap(entry.Name(), dashboardNamespace)
 			if err != nil {
 				reqLogger.Info("There was an error creating dashboard ", "Namespace", dashboardNamespace, "Name", entry.Name())
@@ -473,11 +382,12 @@ func (r *ArgoCDMetricsReconciler) reconcileDashboards(reqLogger logr.Logger) err
 
 				// See if we need to reconcile based on dashboard data only to allow users
 				// to disable dashboard via label if so desired. Note that disabling it
-				// will be reset if dashboard changes in newer version of operator.
+				// will be reset if dashboard changes in newer version of operator, the
+				// Unmanaged state of the dashboard in the GitopsService keeps the changes.
 				if existingDashboard.Data[entry.Name()] != dashboard.Data[entry.Name()] {
 					reqLogger.Info("Dashboard data does not match expectation, reconciling",
 						"Namespace", dashboard.Namespace, "Name", dashboard.Name)
-					err := r.Client.Update(context.TODO(), dashboard)
+					err := recordResourceEvent(r.Recorder, argocd, resourceUpdate, dashboard, r.Client.Update(context.TODO(), dashboard))
 					if err != nil {
 						reqLogger.Error(err, "Error updating dashboard",
 							"Namespace", dashboard.Namespace, "Name", dashboard.Name)
@@ -489,7 +399,7 @@ func (r *ArgoCDMetricsReconciler) reconcileDashboards(reqLogger logr.Logger) err
 			if errors.IsNotFound(err) {
 				reqLogger.Info("Creating new dashboard",
 					"Namespace", dashboard.Namespace, "Name", dashboard.Name)
-				err := r.Client.Create(context.TODO(), dashboard)
+				err := recordResourceEvent(r.Recorder, argocd, resourceCreate, dashboard, r.Client.Create(context.TODO(), dashboard))
 				if err != nil {
 					reqLogger.Error(err, "Error creating a new dashboard",
 						"Namespace", dashboard.Namespace, "Name", dashboard.Name)
@@ -497,7 +407,12 @@ func (r *ArgoCDMetricsReconciler) reconcileDashboards(reqLogger logr.Logger) err
 			}
 		}
 	}
-	return nil
+	return r.reconcileGeneratedDashboards(argocd, reqLogger)
+}
+
+// deletedArgoCD returns the ArgoCD instance of the request that was deleted, to clean up after it
+func deletedArgoCD(request reconcile.Request) *argoapp.ArgoCD {
+	return &argoapp.ArgoCD{ObjectMeta: metav1.ObjectMeta{Name: request.Name, Namespace: request.Namespace}}
 }
 
 func newDashboardConfigMap(filename string, namespace string) (*corev1.ConfigMap, error) {
@@ -508,7 +423,7 @@ func newDashboardConfigMap(filename string, namespace string) (*corev1.ConfigMap
 		Name:      name,
 		Namespace: namespace,
 		Labels: map[string]string{
-			"console.openshift.io/dashboard": "true",
+			consoleDashboardLabel: "true",
 		},
 	}
 	argocdutil.AddTrackedByOperatorLabel(&objectMeta)
@@ -544,7 +459,7 @@ func newReadRole(namespace string) *rbacv1.Role {
 	}
 }
 
-func newReadRoleBinding(namespace string) *rbacv1.RoleBinding {
+func newReadRoleBinding(namespace, prometheusServiceAccount, prometheusNamespace string) *rbacv1.RoleBinding {
 	objectMeta := metav1.ObjectMeta{
 		Name:      fmt.Sprintf(readRoleBindingNameFormat, namespace),
 		Namespace: namespace,
@@ -557,8 +472,8 @@ func newReadRoleBinding(namespace string) *rbacv1.RoleBinding {
 	subjects := []rbacv1.Subject{
 		{
 			Kind:      "ServiceAccount",
-			Name:      "prometheus-k8s",
-			Namespace: "openshift-monitoring",
+			Name:      prometheusServiceAccount,
+			Namespace: prometheusNamespace,
 		},
 	}
 	return &rbacv1.RoleBinding{
@@ -568,13 +483,11 @@ func newReadRoleBinding(namespace string) *rbacv1.RoleBinding {
 	}
 }
 
-func newServiceMonitor(namespace, name, matchLabel string) *monitoringv1.ServiceMonitor {
+func newServiceMonitor(namespace, name, matchLabel string, labels map[string]string) *monitoringv1.ServiceMonitor {
 	objectMeta := metav1.ObjectMeta{
 		Name:      name,
 		Namespace: namespace,
-		Labels: map[string]string{
-			"release": "prometheus-operator",
-		},
+		Labels:    labels,
 	}
 	spec := monitoringv1.ServiceMonitorSpec{
 		Selector: metav1.LabelSelector{
@@ -593,45 +506,3 @@ func newServiceMonitor(namespace, name, matchLabel string) *monitoringv1.Service
 		Spec:       spec,
 	}
 }
-
-func newPrometheusRule(namespace string) *monitoringv1.PrometheusRule {
-	// The namespace used in the alert rule is not the namespace of the
-	// running application, it is the namespace that the corresponding
-	// ArgoCD application metadata was created in.  This is needed to
-	// scope this alert rule to only fire for applications managed
-	// by the ArgoCD instance installed in this namespace.
-	expr := fmt.Sprintf("argocd_app_info{namespace=\"%s\",sync_status=\"OutOfSync\"} > 0", namespace)
-
-	objectMeta := metav1.ObjectMeta{
-		Name:      alertRuleName,
-		Namespace: namespace,
-	}
-	spec := monitoringv1.PrometheusRuleSpec{
-		Groups: []monitoringv1.RuleGroup{
-			{
-				Name: "GitOpsOperatorArgoCD",
-				Rules: []monitoringv1.Rule{
-					{
-						Alert: "ArgoCDSyncAlert",
-						Annotations: map[string]string{
-							"summary":     "Argo CD application is out of sync",
-							"description": "Argo CD application {{ $labels.name }} is out of sync. Check ArgoCDSyncAlert status, this alert is designed to notify that an application managed by Argo CD is out of sync.",
-						},
-						Expr: intstr.IntOrString{
-							Type:   intstr.String,
-							StrVal: expr,
-						},
-						For: ptr.To((monitoringv1.Duration)("5m")),
-						Labels: map[string]string{
-							"severity": "warning",
-						},
-					},
-				},
-			},
-		},
-	}
-	return &monitoringv1.PrometheusRule{
-		ObjectMeta: objectMeta,
-		Spec:       spec,
-	}
-}
@@ -25,6 +25,7 @@ import (
 
 	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	"gotest.tools/assert"
 	is "gotest.tools/assert/cmp"
 	corev1 "k8s.io/api/core/v1"
@@ -33,16 +34,19 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func newScheme() *runtime.Scheme {
 	s := scheme.Scheme
-	s.AddKnownTypes(argoapp.GroupVersion, &argoapp.ArgoCD{})
+	s.AddKnownTypes(argoapp.GroupVersion, &argoapp.ArgoCD{}, &argoapp.ArgoCDList{})
+	s.AddKnownTypes(pipelinesv1beta1.GroupVersion, &pipelinesv1beta1.GitopsService{})
 	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Namespace{})
-	s.AddKnownTypes(monitoringv1.SchemeGroupVersion, &monitoringv1.ServiceMonitor{})
-	s.AddKnownTypes(monitoringv1.SchemeGroupVersion, &monitoringv1.PrometheusRule{})
+	s.AddKnownTypes(monitoringv1.SchemeGroupVersion, &monitoringv1.ServiceMonitor{}, &monitoringv1.ServiceMonitorList{})
+	s.AddKnownTypes(monitoringv1.SchemeGroupVersion, &monitoringv1.PrometheusRule{}, &monitoringv1.PrometheusRuleList{})
 	return s
 }
 
@@ -75,7 +79,7 @@ func newMetricsReconciler(t *testing.T, namespace, name string, disableMetrics *
 	t.Helper()
 	s := newScheme()
 	c := newClient(s, namespace, name, disableMetrics)
-	r := ArgoCDMetricsReconciler{Client: c, Scheme: s}
+	r := ArgoCDMetricsReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(100), skipEvents: newSkipEvents()}
 	return r
 }
 
@@ -323,7 +327,7 @@ func TestReconciler_add_prometheus_rule(t *testing.T) {
 		assert.NilError(t, err)
 
 		rule := monitoringv1.PrometheusRule{}
-		err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "gitops-operator-argocd-alerts", Namespace: tc.namespace}, &rule)
+		err = r.Client.Get(context.TODO(), types.NamespacedName{Name: tc.instanceName + "-alerts", Namespace: tc.namespace}, &rule)
 		assert.NilError(t, err)
 
 		assert.Assert(t, is.Len(rule.OwnerReferences, 1))
@@ -335,7 +339,7 @@ func TestReconciler_add_prometheus_rule(t *testing.T) {
 		assert.Assert(t, rule.Spec.Groups[0].Rules[0].Annotations["description"] != "")
 		assert.Assert(t, rule.Spec.Groups[0].Rules[0].Labels["severity"] != "")
 		assert.Equal(t, string(*rule.Spec.Groups[0].Rules[0].For), "5m")
-		expr := fmt.Sprintf("argocd_app_info{namespace=\"%s\",sync_status=\"OutOfSync\"} > 0", tc.namespace)
+		expr := fmt.Sprintf("argocd_app_info{namespace=\"%s\",service=\"%s-metrics\",sync_status=\"OutOfSync\"} > 0", tc.namespace, tc.instanceName)
 		assert.Equal(t, rule.Spec.Groups[0].Rules[0].Expr.StrVal, expr)
 	}
 }
@@ -395,3 +399,45 @@ func TestReconciler_add_dashboard(t *testing.T) {
 		}
 	}
 }
+
+func TestReconcile_metrics_events(t *testing.T) {
+	r := newMetricsReconciler(t, "namespace-two", "instance-two", nil)
+	recorder := r.Recorder.(*record.FakeRecorder)
+
+	_, err := r.Reconcile(context.TODO(), newRequest("namespace-two", "instance-two"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated", "Skipped"), []string{
+		"Normal Updated Updated Namespace namespace-two",
+		"Normal Created Created Role namespace-two/namespace-two-read",
+		"Normal Created Created RoleBinding namespace-two/namespace-two-prometheus-k8s-read-binding",
+		"Normal Created Created ServiceMonitor namespace-two/instance-two",
+		"Normal Created Created ServiceMonitor namespace-two/instance-two-server",
+		"Normal Created Created ServiceMonitor namespace-two/instance-two-repo-server",
+		"Normal Created Created PrometheusRule namespace-two/instance-two-alerts",
+		"Normal Skipped Monitoring dashboards are not installed: namespace openshift-config-managed not found",
+	})
+
+	// The dashboards still skipped are not reported again
+	_, err = r.Reconcile(context.TODO(), newRequest("namespace-two", "instance-two"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, recordedEvents(recorder, "Skipped"), []string(nil))
+
+	// Disabling the metrics deletes the resources created for the instance
+	argocd := &argoapp.ArgoCD{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "instance-two", Namespace: "namespace-two"}, argocd)
+	assert.NilError(t, err)
+	argocd.Spec.Monitoring.DisableMetrics = ptr.To(true)
+	err = r.Client.Update(context.TODO(), argocd)
+	assert.NilError(t, err)
+
+	_, err = r.Reconcile(context.TODO(), newRequest("namespace-two", "instance-two"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, recordedEvents(recorder, "Deleted"), []string{
+		"Normal Deleted Deleted Role namespace-two/namespace-two-read",
+		"Normal Deleted Deleted RoleBinding namespace-two/namespace-two-prometheus-k8s-read-binding",
+		"Normal Deleted Deleted ServiceMonitor namespace-two/instance-two",
+		"Normal Deleted Deleted ServiceMonitor namespace-two/instance-two-server",
+		"Normal Deleted Deleted ServiceMonitor namespace-two/instance-two-repo-server",
+		"Normal Deleted Deleted PrometheusRule namespace-two/instance-two-alerts",
+	})
+}
@@ -0,0 +1,488 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	argocommon "github.com/argoproj-labs/argocd-operator/common"
+	argocdutil "github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+	"github.com/go-logr/logr"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// consoleDashboardLabel selects the ConfigMaps of openshift-config-managed displayed as dashboards by the console
+	consoleDashboardLabel = "console.openshift.io/dashboard"
+	// dashboardSourceLabel selects the ConfigMaps of the namespace of an ArgoCD instance holding extra dashboards,
+	// copied by the operator to openshift-config-managed
+	dashboardSourceLabel = "pipelines.openshift.io/dashboard"
+	// dashboardKindLabel is set on the dashboards generated by the operator for an ArgoCD instance or for the extra
+	// dashboards of a namespace, and dashboardNamespaceLabel and dashboardInstanceLabel identify their source
+	dashboardKindLabel      = "pipelines.openshift.io/dashboard-kind"
+	dashboardNamespaceLabel = "pipelines.openshift.io/dashboard-namespace"
+	dashboardInstanceLabel  = "pipelines.openshift.io/dashboard-instance"
+
+	dashboardKindInstance = "instance"
+	dashboardKindExtra    = "extra"
+)
+
+// instanceDashboardFiles are the embedded dashboards of the Argo CD components, generated for each ArgoCD instance
+// with instanceDashboards enabled in its monitoring configuration
+var instanceDashboardFiles = []string{"gitops-components.json", "gitops-grpc.json", "gitops-overview.json"}
+
+// metricsEnabled returns true if the metrics of the ArgoCD instance are enabled
+func metricsEnabled(argocd *argoapp.ArgoCD) bool {
+	return argocd.DeletionTimestamp == nil &&
+		(argocd.Spec.Monitoring.DisableMetrics == nil || !*argocd.Spec.Monitoring.DisableMetrics)
+}
+
+// metricsUsedByOthers reports whether other ArgoCD instances have metrics enabled, in the namespace of the given
+// instance and in the cluster
+func (r *ArgoCDMetricsReconciler) metricsUsedByOthers(argocd *argoapp.ArgoCD, reqLogger logr.Logger) (bool, bool, error) {
+	argocds := &argoapp.ArgoCDList{}
+	if err := r.Client.List(context.TODO(), argocds); err != nil {
+		reqLogger.Error(err, "Error listing ArgoCD instances")
+		return false, false, err
+	}
+	usedInNamespace, used := false, false
+	for i := range argocds.Items {
+		instance := &argocds.Items[i]
+		if (instance.Namespace == argocd.Namespace && instance.Name == argocd.Name) || !metricsEnabled(instance) {
+			continue
+		}
+		used = true
+		usedInNamespace = usedInNamespace || instance.Namespace == argocd.Namespace
+	}
+	return usedInNamespace, used, nil
+}
+
+// dashboardSourcePredicate selects the ConfigMaps holding extra dashboards, before or after their update
+var dashboardSourcePredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return e.Object.GetLabels()[dashboardSourceLabel] == "true"
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return e.ObjectOld.GetLabels()[dashboardSourceLabel] == "true" || e.ObjectNew.GetLabels()[dashboardSourceLabel] == "true"
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return e.Object.GetLabels()[dashboardSourceLabel] == "true"
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return e.Object.GetLabels()[dashboardSourceLabel] == "true"
+	},
+}
+
+// argoCDRequestsForDashboardSource maps a ConfigMap holding extra dashboards to the ArgoCD instances of its namespace
+func (r *ArgoCDMetricsReconciler) argoCDRequestsForDashboardSource(ctx context.Context, obj client.Object) []reconcile.Request {
+	argocds := &argoapp.ArgoCDList{}
+	if err := r.Client.List(ctx, argocds, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, argocd := range argocds.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: argocd.Name, Namespace: argocd.Namespace}})
+	}
+	return requests
+}
+
+// reconcileGeneratedDashboards creates or updates the dashboards of the ArgoCD instance, when enabled, and the extra
+// dashboards of its namespace, and deletes the ones that are no longer wanted
+func (r *ArgoCDMetricsReconciler) reconcileGeneratedDashboards(argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	// An invalid configuration is reported by reconcileServiceMonitors and leaves the instance dashboards unchanged
+	if config, err := monitoringConfig(argocd); err == nil {
+		if err := r.reconcileInstanceDashboards(argocd, config.InstanceDashboards, reqLogger); err != nil {
+			return err
+		}
+	}
+	return r.reconcileExtraDashboards(argocd, reqLogger)
+}
+
+func (r *ArgoCDMetricsReconciler) reconcileInstanceDashboards(argocd *argoapp.ArgoCD, enabled bool, reqLogger logr.Logger) error {
+	desired := map[string]bool{}
+	if enabled {
+		for _, filename := range instanceDashboardFiles {
+			dashboard, err := newInstanceDashboardConfigMap(filename, argocd)
+			if err != nil {
+				reqLogger.Error(err, "Error generating instance dashboard", "Name", filename)
+				return err
+			}
+			desired[dashboard.Name] = true
+			if err := r.reconcileDashboard(argocd, dashboard, reqLogger); err != nil {
+				return err
+			}
+		}
+	}
+	return r.deleteDashboards(argocd, map[string]string{
+		dashboardKindLabel:      dashboardKindInstance,
+		dashboardNamespaceLabel: argocd.Namespace,
+		dashboardInstanceLabel:  dashboardLabelValue(argocd.Name),
+	}, desired, reqLogger)
+}
+
+// reconcileExtraDashboards copies the extra dashboards of the namespace of the ArgoCD instance to the console, when
+// the namespace is allowed to publish dashboards, and deletes the copies that are no longer wanted
+func (r *ArgoCDMetricsReconciler) reconcileExtraDashboards(argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	sources := &corev1.ConfigMapList{}
+	err := r.Client.List(context.TODO(), sources, client.InNamespace(argocd.Namespace), client.MatchingLabels{dashboardSourceLabel: "true"})
+	if err != nil {
+		reqLogger.Error(err, "Error listing extra dashboards", "Namespace", argocd.Namespace)
+		return err
+	}
+	allowed, err := r.extraDashboardsAllowed(context.TODO(), argocd.Namespace)
+	if err != nil {
+		reqLogger.Error(err, "Error getting the namespaces allowed to publish extra dashboards")
+		return err
+	}
+	if !allowed && len(sources.Items) > 0 {
+		reqLogger.Info("The namespace is not allowed to publish extra dashboards, skipping", "Namespace", argocd.Namespace)
+		r.skipEvents.record(r.Recorder, argocd, "extra dashboards",
+			"Extra dashboards of namespace %s are not installed: the namespace is not listed in the extraDashboardNamespaces of the GitopsService",
+			argocd.Namespace)
+		sources.Items = nil
+	} else {
+		r.skipEvents.reconciled(argocd, "extra dashboards")
+	}
+
+	desired := map[string]bool{}
+	for _, item := range sources.Items {
+		// The ConfigMaps are read one by one, the data of the ConfigMaps that are not tracked by the operator
+		// is not cached
+		source := &corev1.ConfigMap{}
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: item.Name, Namespace: item.Namespace}, source); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		dashboard := newExtraDashboardConfigMap(source)
+		desired[dashboard.Name] = true
+		if err := r.reconcileDashboard(argocd, dashboard, reqLogger); err != nil {
+			return err
+		}
+	}
+	return r.deleteDashboards(argocd, map[string]string{
+		dashboardKindLabel:      dashboardKindExtra,
+		dashboardNamespaceLabel: argocd.Namespace,
+	}, desired, reqLogger)
+}
+
+// reconcileDashboard creates or updates the given dashboard generated by the operator. A ConfigMap of the same name
+// that was not generated for the same source is left unchanged.
+func (r *ArgoCDMetricsReconciler) reconcileDashboard(argocd *argoapp.ArgoCD, dashboard *corev1.ConfigMap, reqLogger logr.Logger) error {
+	existing := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: dashboard.Name, Namespace: dashboard.Namespace}, existing)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Error querying for dashboard", "Namespace", dashboard.Namespace, "Name", dashboard.Name)
+			return err
+		}
+		reqLogger.Info("Creating new dashboard", "Namespace", dashboard.Namespace, "Name", dashboard.Name)
+		return recordResourceEvent(r.Recorder, argocd, resourceCreate, dashboard, r.Client.Create(context.TODO(), dashboard))
+	}
+
+	for _, label := range []string{dashboardKindLabel, dashboardNamespaceLabel, dashboardInstanceLabel} {
+		if existing.Labels[label] != dashboard.Labels[label] {
+			reqLogger.Info("A dashboard of the same name is not managed for this source, skipping",
+				"Namespace", existing.Namespace, "Name", existing.Name)
+			r.skipEvents.record(r.Recorder, argocd, "dashboard "+existing.Name,
+				"Dashboard %s/%s is not installed: a ConfigMap of the same name already exists", existing.Namespace, existing.Name)
+			return nil
+		}
+	}
+	r.skipEvents.reconciled(argocd, "dashboard "+existing.Name)
+	if containsStringMap(existing.Labels, dashboard.Labels) && equality.Semantic.DeepEqual(existing.Data, dashboard.Data) {
+		return nil
+	}
+	reqLogger.Info("Reconciling existing dashboard", "Namespace", existing.Namespace, "Name", existing.Name)
+	existing.Labels = argocdutil.AppendStringMap(existing.Labels, dashboard.Labels)
+	existing.Data = dashboard.Data
+	return recordResourceEvent(r.Recorder, argocd, resourceUpdate, existing, r.Client.Update(context.TODO(), existing))
+}
+
+// deleteDashboards deletes the dashboards generated by the operator matching the given labels, except the kept ones
+func (r *ArgoCDMetricsReconciler) deleteDashboards(argocd *argoapp.ArgoCD, labels map[string]string, keep map[string]bool,
+	reqLogger logr.Logger) error {
+	existing := &corev1.ConfigMapList{}
+	err := r.Client.List(context.TODO(), existing, client.InNamespace(dashboardNamespace), client.MatchingLabels(labels))
+	if err != nil {
+		reqLogger.Error(err, "Error listing dashboards", "Namespace", dashboardNamespace)
+		return err
+	}
+	for i := range existing.Items {
+		dashboard := &existing.Items[i]
+		if keep[dashboard.Name] {
+			continue
+		}
+		reqLogger.Info("Deleting dashboard", "Namespace", dashboard.Namespace, "Name", dashboard.Name)
+		err := r.Client.Delete(context.TODO(), dashboard)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err := recordResourceEvent(r.Recorder, argocd, resourceDelete, dashboard, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteUnusedDashboards deletes the dashboards that are no longer used once the metrics of the ArgoCD instance are
+// disabled, or the instance is deleted: the dashboards of the instance, the extra dashboards of its namespace when
+// no other instance of the namespace has metrics enabled, and the embedded dashboards when no instance has metrics
+// enabled on the cluster.
+func (r *ArgoCDMetricsReconciler) deleteUnusedDashboards(argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	err := r.deleteDashboards(argocd, map[string]string{
+		dashboardKindLabel:      dashboardKindInstance,
+		dashboardNamespaceLabel: argocd.Namespace,
+		dashboardInstanceLabel:  dashboardLabelValue(argocd.Name),
+	}, nil, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	usedInNamespace, used, err := r.metricsUsedByOthers(argocd, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	if !usedInNamespace {
+		err := r.deleteDashboards(argocd, map[string]string{
+			dashboardKindLabel:      dashboardKindExtra,
+			dashboardNamespaceLabel: argocd.Namespace,
+		}, nil, reqLogger)
+		if err != nil {
+			return err
+		}
+	}
+	if used {
+		return nil
+	}
+
+	states, err := r.dashboardStates(context.TODO())
+	if err != nil {
+		reqLogger.Error(err, "Error getting the state of the dashboards")
+		return err
+	}
+	entries, err := dashboards.ReadDir(dashboardFolder)
+	if err != nil {
+		reqLogger.Error(err, "Could not read list of embedded dashboards")
+		return err
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if entry.IsDir() || states[name] == pipelinesv1beta1.DashboardUnmanaged {
+			continue
+		}
+		if err := r.deleteEmbeddedDashboard(argocd, name, reqLogger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteEmbeddedDashboard deletes the embedded dashboard of the given name, if it was installed by the operator
+func (r *ArgoCDMetricsReconciler) deleteEmbeddedDashboard(argocd *argoapp.ArgoCD, name string, reqLogger logr.Logger) error {
+	dashboard := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: dashboardNamespace}, dashboard)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if _, ok := dashboard.Labels[argocommon.ArgoCDTrackedByOperatorLabel]; !ok {
+		return nil
+	}
+	reqLogger.Info("Deleting dashboard", "Namespace", dashboard.Namespace, "Name", dashboard.Name)
+	err = r.Client.Delete(context.TODO(), dashboard)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return recordResourceEvent(r.Recorder, argocd, resourceDelete, dashboard, err)
+}
+
+// monitoringSpec returns the monitoring configuration of the GitopsService, nil when it is not set
+func (r *ArgoCDMetricsReconciler) monitoringSpec(ctx context.Context) (*pipelinesv1beta1.MonitoringSpec, error) {
+	gitopsService := &pipelinesv1beta1.GitopsService{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: serviceName}, gitopsService)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return gitopsService.Spec.Monitoring, nil
+}
+
+// dashboardStates returns the state of the embedded dashboards set in the GitopsService, by dashboard name
+func (r *ArgoCDMetricsReconciler) dashboardStates(ctx context.Context) (map[string]pipelinesv1beta1.DashboardState, error) {
+	monitoring, err := r.monitoringSpec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	states := map[string]pipelinesv1beta1.DashboardState{}
+	if monitoring != nil {
+		for _, dashboard := range monitoring.Dashboards {
+			states[dashboard.Name] = dashboard.State
+		}
+	}
+	return states, nil
+}
+
+// extraDashboardsAllowed reports whether the extra dashboards of the given namespace are published in the console: the
+// dashboards are visible cluster-wide, only the default namespace and the namespaces listed in the GitopsService are
+// allowed to publish them
+func (r *ArgoCDMetricsReconciler) extraDashboardsAllowed(ctx context.Context, namespace string) (bool, error) {
+	if namespace == serviceNamespace {
+		return true, nil
+	}
+	monitoring, err := r.monitoringSpec(ctx)
+	if err != nil || monitoring == nil {
+		return false, err
+	}
+	return slices.Contains(monitoring.ExtraDashboardNamespaces, namespace), nil
+}
+
+// argoCDRequestsForGitopsService maps the GitopsService to an ArgoCD instance with metrics enabled of each namespace,
+// to reconcile the embedded dashboards and the extra dashboards of the namespaces. The dashboards are shared by the
+// instances of a namespace, reconciling one of them is enough.
+func (r *ArgoCDMetricsReconciler) argoCDRequestsForGitopsService(ctx context.Context, obj client.Object) []reconcile.Request {
+	argocds := &argoapp.ArgoCDList{}
+	if err := r.Client.List(ctx, argocds); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	namespaces := map[string]bool{}
+	for i := range argocds.Items {
+		if argocd := &argocds.Items[i]; metricsEnabled(argocd) && !namespaces[argocd.Namespace] {
+			namespaces[argocd.Namespace] = true
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: argocd.Name, Namespace: argocd.Namespace}})
+		}
+	}
+	return requests
+}
+
+// newInstanceDashboardConfigMap returns the variant of the embedded dashboard for the ArgoCD instance, where the
+// namespace variable is set to the namespace of the instance
+func newInstanceDashboardConfigMap(filename string, argocd *argoapp.ArgoCD) (*corev1.ConfigMap, error) {
+	content, err := dashboards.ReadFile(dashboardFolder + "/" + filename)
+	if err != nil {
+		return nil, err
+	}
+	dashboard := map[string]interface{}{}
+	if err := json.Unmarshal(content, &dashboard); err != nil {
+		return nil, err
+	}
+
+	// The uid of the dashboard is unique for the instance, and its title names the instance
+	dashboard["uid"] = fmt.Sprintf("%v-%x", dashboard["uid"], sha256.Sum256([]byte(argocd.Namespace+"/"+argocd.Name)))[:20]
+	dashboard["title"] = fmt.Sprintf("%v (%s/%s)", dashboard["title"], argocd.Namespace, argocd.Name)
+	if templating, ok := dashboard["templating"].(map[string]interface{}); ok {
+		variables, _ := templating["list"].([]interface{})
+		for i, variable := range variables {
+			if variable, ok := variable.(map[string]interface{}); ok && variable["name"] == "namespace" {
+				variables[i] = map[string]interface{}{
+					"name":    "namespace",
+					"type":    "constant",
+					"query":   argocd.Namespace,
+					"hide":    2,
+					"current": map[string]interface{}{"text": argocd.Namespace, "value": argocd.Namespace},
+				}
+			}
+		}
+	}
+	content, err = json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s-%s-%s", strings.TrimSuffix(filename, filepath.Ext(filename)), argocd.Namespace, argocd.Name)
+	objectMeta := metav1.ObjectMeta{
+		Name:      name,
+		Namespace: dashboardNamespace,
+		Labels: map[string]string{
+			consoleDashboardLabel:   "true",
+			dashboardKindLabel:      dashboardKindInstance,
+			dashboardNamespaceLabel: argocd.Namespace,
+			dashboardInstanceLabel:  dashboardLabelValue(argocd.Name),
+		},
+	}
+	argocdutil.AddTrackedByOperatorLabel(&objectMeta)
+	return &corev1.ConfigMap{
+		ObjectMeta: objectMeta,
+		Data: map[string]string{
+			name + ".json": string(content),
+		},
+	}, nil
+}
+
+// newExtraDashboardConfigMap returns the copy of the extra dashboards of the given ConfigMap displayed by the console.
+// The name of the copy is suffixed by a hash of the namespace and name of the source, so that the copies of different
+// sources never collide.
+func newExtraDashboardConfigMap(source *corev1.ConfigMap) *corev1.ConfigMap {
+	name := source.Namespace + "-" + source.Name
+	suffix := shortHash(source.Namespace + "/" + source.Name)
+	if maxLength := validation.DNS1123SubdomainMaxLength - len(suffix) - 1; len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-.")
+	}
+	objectMeta := metav1.ObjectMeta{
+		Name:      name + "-" + suffix,
+		Namespace: dashboardNamespace,
+		Labels: map[string]string{
+			consoleDashboardLabel:   "true",
+			dashboardKindLabel:      dashboardKindExtra,
+			dashboardNamespaceLabel: source.Namespace,
+		},
+	}
+	argocdutil.AddTrackedByOperatorLabel(&objectMeta)
+	return &corev1.ConfigMap{
+		ObjectMeta: objectMeta,
+		Data:       source.Data,
+	}
+}
+
+// dashboardLabelValue returns the given value as a label value, truncated and suffixed by its hash when it exceeds the
+// maximum length of a label value
+func dashboardLabelValue(value string) string {
+	if len(value) <= validation.LabelValueMaxLength {
+		return value
+	}
+	suffix := shortHash(value)
+	return strings.TrimRight(value[:validation.LabelValueMaxLength-len(suffix)-1], "-_.") + "-" + suffix
+}
+
+// shortHash returns the first characters of the hex encoded SHA-256 hash of the given value
+func shortHash(value string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(value)))[:8]
+}
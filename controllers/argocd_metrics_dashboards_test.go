@@ -0,0 +1,315 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newDashboardsReconciler(t *testing.T) ArgoCDMetricsReconciler {
+	t.Helper()
+	r := newMetricsReconciler(t, "namespace-two", "instance-two", nil)
+	assert.NilError(t, r.Client.Create(context.TODO(), &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: dashboardNamespace}}))
+	return r
+}
+
+func reconcileInstanceTwo(t *testing.T, r ArgoCDMetricsReconciler, update func(argocd *argoapp.ArgoCD)) {
+	t.Helper()
+	name := types.NamespacedName{Name: "instance-two", Namespace: "namespace-two"}
+	if update != nil {
+		argocd := &argoapp.ArgoCD{}
+		assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+		update(argocd)
+		assert.NilError(t, r.Client.Update(context.TODO(), argocd))
+	}
+	_, err := r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+	assert.NilError(t, err)
+}
+
+func dashboardNames(t *testing.T, r ArgoCDMetricsReconciler) []string {
+	t.Helper()
+	list := &corev1.ConfigMapList{}
+	assert.NilError(t, r.Client.List(context.TODO(), list, client.InNamespace(dashboardNamespace)))
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+func TestReconcile_instance_dashboards(t *testing.T) {
+	r := newDashboardsReconciler(t)
+	recorder := r.Recorder.(*record.FakeRecorder)
+
+	reconcileInstanceTwo(t, r, func(argocd *argoapp.ArgoCD) {
+		argocd.Annotations = map[string]string{monitoringConfigAnnotation: "instanceDashboards: true"}
+	})
+	assert.DeepEqual(t, dashboardNames(t, r), []string{
+		"gitops-components",
+		"gitops-components-namespace-two-instance-two",
+		"gitops-grpc",
+		"gitops-grpc-namespace-two-instance-two",
+		"gitops-overview",
+		"gitops-overview-namespace-two-instance-two",
+		"gitops-rollouts",
+	})
+
+	dashboard := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "gitops-overview-namespace-two-instance-two", Namespace: dashboardNamespace}, dashboard)
+	assert.NilError(t, err)
+	assert.Equal(t, dashboard.Labels[consoleDashboardLabel], "true")
+	assert.Equal(t, dashboard.Labels[dashboardKindLabel], dashboardKindInstance)
+	content := struct {
+		UID        string `json:"uid"`
+		Title      string `json:"title"`
+		Templating struct {
+			List []map[string]interface{} `json:"list"`
+		} `json:"templating"`
+	}{}
+	assert.NilError(t, json.Unmarshal([]byte(dashboard.Data["gitops-overview-namespace-two-instance-two.json"]), &content))
+	assert.Equal(t, len(content.UID), 20)
+	assert.Equal(t, content.Title, "GitOps (Overview) (namespace-two/instance-two)")
+	assert.Equal(t, content.Templating.List[0]["name"], "namespace")
+	assert.Equal(t, content.Templating.List[0]["type"], "constant")
+	assert.Equal(t, content.Templating.List[0]["query"], "namespace-two")
+
+	// a reconcile without changes leaves the dashboards unchanged
+	recordedEvents(recorder)
+	reconcileInstanceTwo(t, r, nil)
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated", "Deleted"), []string(nil))
+
+	reconcileInstanceTwo(t, r, func(argocd *argoapp.ArgoCD) {
+		argocd.Annotations = nil
+	})
+	assert.DeepEqual(t, dashboardNames(t, r), []string{"gitops-components", "gitops-grpc", "gitops-overview", "gitops-rollouts"})
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated", "Deleted"), []string{
+		"Normal Deleted Deleted ConfigMap openshift-config-managed/gitops-components-namespace-two-instance-two",
+		"Normal Deleted Deleted ConfigMap openshift-config-managed/gitops-grpc-namespace-two-instance-two",
+		"Normal Deleted Deleted ConfigMap openshift-config-managed/gitops-overview-namespace-two-instance-two",
+	})
+}
+
+func TestReconcile_extra_dashboards(t *testing.T) {
+	r := newDashboardsReconciler(t)
+	recorder := r.Recorder.(*record.FakeRecorder)
+
+	source := &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "team-dashboard",
+			Namespace: "namespace-two",
+			Labels:    map[string]string{dashboardSourceLabel: "true"},
+		},
+		Data: map[string]string{"team.json": `{"title": "Team"}`},
+	}
+	assert.NilError(t, r.Client.Create(context.TODO(), source))
+	// a ConfigMap of the same name that is not managed by the operator is left unchanged
+	unmanaged := &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "other-dashboard",
+			Namespace: "namespace-two",
+			Labels:    map[string]string{dashboardSourceLabel: "true"},
+		},
+	}
+	assert.NilError(t, r.Client.Create(context.TODO(), unmanaged))
+	assert.NilError(t, r.Client.Create(context.TODO(), &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{Name: "namespace-two-other-dashboard-feeba660", Namespace: dashboardNamespace},
+	}))
+
+	// the extra dashboards of a namespace that is not allowed to publish them are not installed
+	reconcileInstanceTwo(t, r, nil)
+	dashboard := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two-team-dashboard-ce32d79a", Namespace: dashboardNamespace}, dashboard)
+	assert.Assert(t, errors.IsNotFound(err))
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Skipped"), []string{
+		"Normal Created Created Role namespace-two/namespace-two-read",
+		"Normal Created Created RoleBinding namespace-two/namespace-two-prometheus-k8s-read-binding",
+		"Normal Created Created ServiceMonitor namespace-two/instance-two",
+		"Normal Created Created ServiceMonitor namespace-two/instance-two-server",
+		"Normal Created Created ServiceMonitor namespace-two/instance-two-repo-server",
+		"Normal Created Created PrometheusRule namespace-two/instance-two-alerts",
+		"Normal Created Created ConfigMap openshift-config-managed/gitops-components",
+		"Normal Created Created ConfigMap openshift-config-managed/gitops-grpc",
+		"Normal Created Created ConfigMap openshift-config-managed/gitops-overview",
+		"Normal Created Created ConfigMap openshift-config-managed/gitops-rollouts",
+		"Normal Skipped Extra dashboards of namespace namespace-two are not installed: the namespace is not listed in the extraDashboardNamespaces of the GitopsService",
+	})
+
+	gitopsService := &pipelinesv1beta1.GitopsService{
+		ObjectMeta: v1.ObjectMeta{Name: serviceName},
+		Spec: pipelinesv1beta1.GitopsServiceSpec{
+			Monitoring: &pipelinesv1beta1.MonitoringSpec{ExtraDashboardNamespaces: []string{"namespace-two"}},
+		},
+	}
+	assert.NilError(t, r.Client.Create(context.TODO(), gitopsService))
+	reconcileInstanceTwo(t, r, nil)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two-team-dashboard-ce32d79a", Namespace: dashboardNamespace}, dashboard)
+	assert.NilError(t, err)
+	assert.Equal(t, dashboard.Labels[consoleDashboardLabel], "true")
+	assert.DeepEqual(t, dashboard.Data, source.Data)
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Skipped"), []string{
+		"Normal Skipped Dashboard openshift-config-managed/namespace-two-other-dashboard-feeba660 is not installed: a ConfigMap of the same name already exists",
+		"Normal Created Created ConfigMap openshift-config-managed/namespace-two-team-dashboard-ce32d79a",
+	})
+
+	// the changes of the source are copied
+	source.Data = map[string]string{"team.json": `{"title": "Team v2"}`}
+	assert.NilError(t, r.Client.Update(context.TODO(), source))
+	reconcileInstanceTwo(t, r, nil)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two-team-dashboard-ce32d79a", Namespace: dashboardNamespace}, dashboard)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, dashboard.Data, source.Data)
+
+	// the copy is deleted once the namespace is no longer allowed to publish dashboards
+	gitopsService.Spec.Monitoring = nil
+	assert.NilError(t, r.Client.Update(context.TODO(), gitopsService))
+	reconcileInstanceTwo(t, r, nil)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two-team-dashboard-ce32d79a", Namespace: dashboardNamespace}, dashboard)
+	assert.Assert(t, errors.IsNotFound(err))
+
+	// and with the label of the source
+	gitopsService.Spec.Monitoring = &pipelinesv1beta1.MonitoringSpec{ExtraDashboardNamespaces: []string{"namespace-two"}}
+	assert.NilError(t, r.Client.Update(context.TODO(), gitopsService))
+	reconcileInstanceTwo(t, r, nil)
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two-team-dashboard-ce32d79a", Namespace: dashboardNamespace}, dashboard))
+	source.Labels = nil
+	assert.NilError(t, r.Client.Update(context.TODO(), source))
+	reconcileInstanceTwo(t, r, nil)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two-team-dashboard-ce32d79a", Namespace: dashboardNamespace}, dashboard)
+	assert.Assert(t, errors.IsNotFound(err))
+
+	requests := r.argoCDRequestsForDashboardSource(context.TODO(), source)
+	assert.DeepEqual(t, requests, []reconcile.Request{newRequest("namespace-two", "instance-two")})
+}
+
+func TestNewExtraDashboardConfigMap(t *testing.T) {
+	// the names of the copies of different sources do not collide
+	first := newExtraDashboardConfigMap(&corev1.ConfigMap{ObjectMeta: v1.ObjectMeta{Name: "c", Namespace: "a-b"}})
+	second := newExtraDashboardConfigMap(&corev1.ConfigMap{ObjectMeta: v1.ObjectMeta{Name: "b-c", Namespace: "a"}})
+	assert.Equal(t, first.Name, "a-b-c-4e84717d")
+	assert.Equal(t, second.Name, "a-b-c-b88f83c8")
+
+	// and do not exceed the maximum length of a name
+	long := newExtraDashboardConfigMap(&corev1.ConfigMap{ObjectMeta: v1.ObjectMeta{Name: strings.Repeat("b", 253), Namespace: "a"}})
+	assert.Equal(t, len(long.Name), 253)
+	assert.Assert(t, strings.HasPrefix(long.Name, "a-bbb"))
+}
+
+func TestDashboardLabelValue(t *testing.T) {
+	assert.Equal(t, dashboardLabelValue("instance-two"), "instance-two")
+	value := dashboardLabelValue(strings.Repeat("a", 53) + "-" + strings.Repeat("b", 60))
+	assert.Assert(t, len(value) <= validation.LabelValueMaxLength)
+	assert.Assert(t, len(validation.IsValidLabelValue(value)) == 0)
+	assert.Equal(t, value, strings.Repeat("a", 53)+"-"+shortHash(strings.Repeat("a", 53)+"-"+strings.Repeat("b", 60)))
+}
+
+func TestReconcile_delete_unused_dashboards(t *testing.T) {
+	r := newDashboardsReconciler(t)
+	other := &argoapp.ArgoCD{ObjectMeta: v1.ObjectMeta{Name: "instance-three", Namespace: "namespace-three"}}
+	assert.NilError(t, r.Client.Create(context.TODO(), other))
+	source := &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "team-dashboard",
+			Namespace: "namespace-two",
+			Labels:    map[string]string{dashboardSourceLabel: "true"},
+		},
+	}
+	assert.NilError(t, r.Client.Create(context.TODO(), source))
+	assert.NilError(t, r.Client.Create(context.TODO(), &pipelinesv1beta1.GitopsService{
+		ObjectMeta: v1.ObjectMeta{Name: serviceName},
+		Spec: pipelinesv1beta1.GitopsServiceSpec{
+			Monitoring: &pipelinesv1beta1.MonitoringSpec{ExtraDashboardNamespaces: []string{"namespace-two"}},
+		},
+	}))
+
+	reconcileInstanceTwo(t, r, func(argocd *argoapp.ArgoCD) {
+		argocd.Annotations = map[string]string{monitoringConfigAnnotation: "instanceDashboards: true"}
+	})
+	assert.Equal(t, len(dashboardNames(t, r)), 8)
+
+	// the embedded dashboards are kept while another instance has metrics enabled
+	reconcileInstanceTwo(t, r, func(argocd *argoapp.ArgoCD) {
+		argocd.Spec.Monitoring.DisableMetrics = ptr.To(true)
+	})
+	assert.DeepEqual(t, dashboardNames(t, r), []string{"gitops-components", "gitops-grpc", "gitops-overview", "gitops-rollouts"})
+
+	// the embedded dashboards are deleted with the last instance with metrics enabled
+	assert.NilError(t, r.Client.Delete(context.TODO(), other))
+	_, err := r.Reconcile(context.TODO(), newRequest("namespace-three", "instance-three"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, dashboardNames(t, r), []string(nil))
+}
+
+func TestReconcile_dashboard_states(t *testing.T) {
+	r := newDashboardsReconciler(t)
+	recorder := r.Recorder.(*record.FakeRecorder)
+	reconcileInstanceTwo(t, r, nil)
+	recordedEvents(recorder)
+
+	// an unmanaged dashboard keeps the changes of the user
+	overview := &corev1.ConfigMap{}
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "gitops-overview", Namespace: dashboardNamespace}, overview))
+	overview.Labels[consoleDashboardLabel] = "false"
+	overview.Data = map[string]string{"gitops-overview.json": `{"title": "Custom"}`}
+	assert.NilError(t, r.Client.Update(context.TODO(), overview))
+
+	gitopsService := &pipelinesv1beta1.GitopsService{
+		ObjectMeta: v1.ObjectMeta{Name: serviceName},
+		Spec: pipelinesv1beta1.GitopsServiceSpec{
+			Monitoring: &pipelinesv1beta1.MonitoringSpec{
+				Dashboards: []pipelinesv1beta1.DashboardSpec{
+					{Name: "gitops-overview", State: pipelinesv1beta1.DashboardUnmanaged},
+					{Name: "gitops-rollouts", State: pipelinesv1beta1.DashboardAbsent},
+					{Name: "gitops-grpc", State: pipelinesv1beta1.DashboardManaged},
+				},
+			},
+		},
+	}
+	assert.NilError(t, r.Client.Create(context.TODO(), gitopsService))
+	requests := r.argoCDRequestsForGitopsService(context.TODO(), gitopsService)
+	assert.DeepEqual(t, requests, []reconcile.Request{newRequest("namespace-two", "instance-two")})
+
+	reconcileInstanceTwo(t, r, nil)
+	assert.DeepEqual(t, dashboardNames(t, r), []string{"gitops-components", "gitops-grpc", "gitops-overview"})
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "gitops-overview", Namespace: dashboardNamespace}, overview))
+	assert.Equal(t, overview.Labels[consoleDashboardLabel], "false")
+	assert.Equal(t, overview.Data["gitops-overview.json"], `{"title": "Custom"}`)
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated", "Deleted"), []string{
+		"Normal Deleted Deleted ConfigMap openshift-config-managed/gitops-rollouts",
+	})
+
+	// an unmanaged dashboard is not deleted with the last instance with metrics enabled
+	reconcileInstanceTwo(t, r, func(argocd *argoapp.ArgoCD) {
+		argocd.Spec.Monitoring.DisableMetrics = ptr.To(true)
+	})
+	assert.DeepEqual(t, dashboardNames(t, r), []string{"gitops-overview"})
+}
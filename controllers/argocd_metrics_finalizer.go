@@ -0,0 +1,135 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/go-logr/logr"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// metricsFinalizer is set on the ArgoCD instances with metrics enabled, so that their monitoring resources are
+// deleted before the instance
+const metricsFinalizer = "pipelines.openshift.io/metrics"
+
+// addMetricsFinalizer adds the metrics finalizer to the ArgoCD instance, unless already present
+func (r *ArgoCDMetricsReconciler) addMetricsFinalizer(ctx context.Context, argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	original := argocd.DeepCopy()
+	if !controllerutil.AddFinalizer(argocd, metricsFinalizer) {
+		return nil
+	}
+	reqLogger.Info("Adding metrics finalizer", "Namespace", argocd.Namespace, "Name", argocd.Name)
+	// The optimistic lock preserves the finalizers concurrently set by the argocd-operator
+	err := r.Client.Patch(ctx, argocd, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+	if err != nil {
+		reqLogger.Error(err, "Error adding metrics finalizer", "Namespace", argocd.Namespace, "Name", argocd.Name)
+	}
+	return err
+}
+
+// removeMetricsFinalizer removes the metrics finalizer from the ArgoCD instance, if present
+func (r *ArgoCDMetricsReconciler) removeMetricsFinalizer(ctx context.Context, argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	original := argocd.DeepCopy()
+	if !controllerutil.RemoveFinalizer(argocd, metricsFinalizer) {
+		return nil
+	}
+	reqLogger.Info("Removing metrics finalizer", "Namespace", argocd.Namespace, "Name", argocd.Name)
+	err := r.Client.Patch(ctx, argocd, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		reqLogger.Error(err, "Error removing metrics finalizer", "Namespace", argocd.Namespace, "Name", argocd.Name)
+	}
+	return err
+}
+
+// deleteMetricsResources deletes the monitoring resources created for the ArgoCD instance, whatever the labels of
+// its namespace. The read role and role binding shared by the instances of the namespace are kept while another one
+// has metrics enabled.
+func (r *ArgoCDMetricsReconciler) deleteMetricsResources(ctx context.Context, namespace *corev1.Namespace,
+	argocd *argoapp.ArgoCD, reqLogger logr.Logger) error {
+	// Revert the monitoring label of the namespace, if set by the operator and no longer used
+	err := r.reconcileMonitoringLabel(ctx, namespace, argocd, false, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	usedInNamespace, _, err := r.metricsUsedByOthers(argocd, reqLogger)
+	if err != nil {
+		return err
+	}
+	deleteResource := func(obj client.Object) error {
+		err := r.Client.Delete(ctx, obj)
+		if !errors.IsNotFound(err) {
+			if err = recordResourceEvent(r.Recorder, argocd, resourceDelete, obj, err); err != nil {
+				reqLogger.Error(err, "Error deleting monitoring resource",
+					"Namespace", obj.GetNamespace(), "Name", obj.GetName())
+				return err
+			}
+		}
+		return nil
+	}
+	deleteShared := func(obj client.Object) error {
+		if usedInNamespace {
+			return nil
+		}
+		return deleteResource(obj)
+	}
+
+	// Delete role to grant read permission to the openshift metrics stack
+	err = deleteShared(&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: argocd.Namespace, Name: fmt.Sprintf(readRoleNameFormat, argocd.Namespace)}})
+	if err != nil {
+		return err
+	}
+
+	// Delete role binding to grant read permission to the openshift metrics stack
+	err = deleteShared(&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: argocd.Namespace, Name: fmt.Sprintf(readRoleBindingNameFormat, argocd.Namespace)}})
+	if err != nil {
+		return err
+	}
+
+	// Delete the ServiceMonitors of the ArgoCD components
+	for _, component := range metricsComponents {
+		err = r.deleteServiceMonitor(argocd.Name+component.monitorSuffix, argocd.Namespace, argocd, reqLogger)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Delete the alert rule of the instance, and the alert rule shared by the instances of previous versions
+	err = deleteResource(&monitoringv1.PrometheusRule{ObjectMeta: metav1.ObjectMeta{Namespace: argocd.Namespace, Name: fmt.Sprintf(alertRuleNameFormat, argocd.Name)}})
+	if err != nil {
+		return err
+	}
+	err = r.deleteLegacyPrometheusRule(argocd, reqLogger)
+	if err != nil {
+		return err
+	}
+
+	// Delete the dashboards that are no longer used
+	return r.deleteUnusedDashboards(argocd, reqLogger)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// metricsResources returns the number of ServiceMonitors and alert rules of the namespace, and whether its read role
+// and read role binding exist
+func metricsResources(t *testing.T, r ArgoCDMetricsReconciler, namespace string) (int, int, bool) {
+	t.Helper()
+	serviceMonitors := &monitoringv1.ServiceMonitorList{}
+	assert.NilError(t, r.Client.List(context.TODO(), serviceMonitors, client.InNamespace(namespace)))
+	alertRules := &monitoringv1.PrometheusRuleList{}
+	assert.NilError(t, r.Client.List(context.TODO(), alertRules, client.InNamespace(namespace)))
+	found := 0
+	for name, obj := range map[string]client.Object{
+		namespace + "-read":                        &rbacv1.Role{},
+		namespace + "-prometheus-k8s-read-binding": &rbacv1.RoleBinding{},
+	} {
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, obj)
+		if err == nil {
+			found++
+		} else {
+			assert.Assert(t, errors.IsNotFound(err))
+		}
+	}
+	assert.Assert(t, found == 0 || found == 2)
+	return len(serviceMonitors.Items), len(alertRules.Items), found == 2
+}
+
+func TestReconcile_metrics_finalizer(t *testing.T) {
+	r := newMetricsReconciler(t, "namespace-two", "instance-two", nil)
+	name := types.NamespacedName{Name: "instance-two", Namespace: "namespace-two"}
+
+	// the monitoring label set by the user is left unchanged, the resources are deleted anyway
+	ns := &corev1.Namespace{}
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two"}, ns))
+	ns.Labels = map[string]string{userDefinedMonitoringLabel: "true"}
+	assert.NilError(t, r.Client.Update(context.TODO(), ns))
+
+	_, err := r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+	assert.NilError(t, err)
+	argocd := &argoapp.ArgoCD{}
+	assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+	assert.Assert(t, is.Contains(argocd.Finalizers, metricsFinalizer))
+	serviceMonitors, alertRules, shared := metricsResources(t, r, "namespace-two")
+	assert.Equal(t, serviceMonitors, 3)
+	assert.Equal(t, alertRules, 1)
+	assert.Assert(t, shared)
+
+	// the instance is kept until its monitoring resources are deleted
+	assert.NilError(t, r.Client.Delete(context.TODO(), argocd))
+	assert.NilError(t, r.Client.Get(context.TODO(), name, argocd))
+	assert.Assert(t, argocd.DeletionTimestamp != nil)
+
+	_, err = r.Reconcile(context.TODO(), newRequest(name.Namespace, name.Name))
+	assert.NilError(t, err)
+	assert.Assert(t, errors.IsNotFound(r.Client.Get(context.TODO(), name, argocd)))
+	serviceMonitors, alertRules, shared = metricsResources(t, r, "namespace-two")
+	assert.Equal(t, serviceMonitors, 0)
+	assert.Equal(t, alertRules, 0)
+	assert.Assert(t, !shared)
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two"}, ns))
+	assert.Equal(t, ns.Labels[userDefinedMonitoringLabel], "true")
+}
+
+func TestReconcile_metrics_finalizer_disabled(t *testing.T) {
+	r := newMetricsReconciler(t, "namespace-two", "instance-two", nil)
+	other := &argoapp.ArgoCD{ObjectMeta: v1.ObjectMeta{Name: "instance-three", Namespace: "namespace-two"}}
+	assert.NilError(t, r.Client.Create(context.TODO(), other))
+	for _, name := range []string{"instance-two", "instance-three"} {
+		_, err := r.Reconcile(context.TODO(), newRequest("namespace-two", name))
+		assert.NilError(t, err)
+	}
+	serviceMonitors, alertRules, _ := metricsResources(t, r, "namespace-two")
+	assert.Equal(t, serviceMonitors, 6)
+	assert.Equal(t, alertRules, 2)
+
+	// the resources shared by the instances of the namespace are kept while used
+	reconcileInstanceTwo(t, r, func(argocd *argoapp.ArgoCD) {
+		argocd.Spec.Monitoring.DisableMetrics = ptr.To(true)
+	})
+	argocd := &argoapp.ArgoCD{}
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "instance-two", Namespace: "namespace-two"}, argocd))
+	assert.Assert(t, !slices.Contains(argocd.Finalizers, metricsFinalizer))
+	serviceMonitors, alertRules, shared := metricsResources(t, r, "namespace-two")
+	assert.Equal(t, serviceMonitors, 3)
+	assert.Equal(t, alertRules, 1)
+	assert.Assert(t, shared)
+
+	assert.NilError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(other), other))
+	other.Spec.Monitoring.DisableMetrics = ptr.To(true)
+	assert.NilError(t, r.Client.Update(context.TODO(), other))
+	_, err := r.Reconcile(context.TODO(), newRequest("namespace-two", "instance-three"))
+	assert.NilError(t, err)
+	serviceMonitors, alertRules, shared = metricsResources(t, r, "namespace-two")
+	assert.Equal(t, serviceMonitors, 0)
+	assert.Equal(t, alertRules, 0)
+	assert.Assert(t, !shared)
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	clusterMonitoringLabel     = "openshift.io/cluster-monitoring"
+	userDefinedMonitoringLabel = "openshift.io/user-monitoring"
+
+	// monitoringLabelOwnersAnnotation is set on the namespaces where the operator added the monitoring label. It lists
+	// the ArgoCD instances of the namespace with metrics enabled, the label is reverted once the list is empty.
+	monitoringLabelOwnersAnnotation = "pipelines.openshift.io/monitoring-label-owners"
+	// monitoringLabelKeyAnnotation holds the key of the monitoring label set by the operator, so that it is reverted
+	// whatever the label selected by the current configuration
+	monitoringLabelKeyAnnotation = "pipelines.openshift.io/monitoring-label"
+	// monitoringLabelPreviousAnnotation holds the value of the monitoring label before the operator set it
+	monitoringLabelPreviousAnnotation = "pipelines.openshift.io/monitoring-label-previous"
+)
+
+func monitoringLabelOwners(namespace *corev1.Namespace) ([]string, bool) {
+	value, ok := namespace.Annotations[monitoringLabelOwnersAnnotation]
+	if !ok || value == "" {
+		return nil, ok
+	}
+	return strings.Split(value, ","), true
+}
+
+// ownedMonitoringLabel returns the key of the monitoring label set by the operator on the namespace, or the given
+// label when the namespace was labelled before the key was recorded
+func ownedMonitoringLabel(namespace *corev1.Namespace, label string) string {
+	if key := namespace.Annotations[monitoringLabelKeyAnnotation]; key != "" {
+		return key
+	}
+	return label
+}
+
+// acquireMonitoringLabel sets the given monitoring label of the namespace for the ArgoCD instance. A label already set to
+// true by someone else is left unchanged, it is not reverted by releaseMonitoringLabel. The label previously set by
+// the operator under another key is reverted, its owners are moved to the given label. Returns true if the namespace
+// changed.
+func acquireMonitoringLabel(namespace *corev1.Namespace, label, instance string) bool {
+	owners, owned := monitoringLabelOwners(namespace)
+	changed := false
+	if owned && ownedMonitoringLabel(namespace, label) != label {
+		changed = revertMonitoringLabel(namespace, label)
+		owned = false
+	}
+	if !owned && namespace.Labels[label] == "true" {
+		return changed
+	}
+	if owned && namespace.Labels[label] == "true" && slices.Contains(owners, instance) &&
+		namespace.Annotations[monitoringLabelKeyAnnotation] == label {
+		return changed
+	}
+
+	if namespace.Annotations == nil {
+		namespace.Annotations = map[string]string{}
+	}
+	if previous, ok := namespace.Labels[label]; ok && !owned {
+		namespace.Annotations[monitoringLabelPreviousAnnotation] = previous
+	}
+	if !slices.Contains(owners, instance) {
+		owners = append(owners, instance)
+	}
+	namespace.Annotations[monitoringLabelOwnersAnnotation] = strings.Join(owners, ",")
+	namespace.Annotations[monitoringLabelKeyAnnotation] = label
+	if namespace.Labels == nil {
+		namespace.Labels = map[string]string{}
+	}
+	namespace.Labels[label] = "true"
+	return true
+}
+
+// releaseMonitoringLabel removes the ArgoCD instance from the owners of the monitoring label of the namespace. The
+// label set by the operator is reverted to its previous value, or removed, when no other instance of the namespace
+// has metrics enabled. The given label is only used when the namespace does not record the key of the label set by
+// the operator. Returns true if the namespace changed.
+func releaseMonitoringLabel(namespace *corev1.Namespace, label, instance string) bool {
+	owners, owned := monitoringLabelOwners(namespace)
+	if !owned {
+		return false
+	}
+	owners = slices.DeleteFunc(owners, func(owner string) bool { return owner == instance })
+	if len(owners) > 0 {
+		value := strings.Join(owners, ",")
+		if value == namespace.Annotations[monitoringLabelOwnersAnnotation] {
+			return false
+		}
+		namespace.Annotations[monitoringLabelOwnersAnnotation] = value
+		return true
+	}
+	return revertMonitoringLabel(namespace, label)
+}
+
+// revertMonitoringLabel reverts the monitoring label set by the operator on the namespace to its previous value, or
+// removes it, whatever its owners. Returns true if the namespace changed.
+func revertMonitoringLabel(namespace *corev1.Namespace, label string) bool {
+	if _, owned := monitoringLabelOwners(namespace); !owned {
+		return false
+	}
+	if key := ownedMonitoringLabel(namespace, label); key != "" {
+		if previous, ok := namespace.Annotations[monitoringLabelPreviousAnnotation]; ok && namespace.Labels != nil {
+			namespace.Labels[key] = previous
+		} else {
+			delete(namespace.Labels, key)
+		}
+	}
+	delete(namespace.Annotations, monitoringLabelOwnersAnnotation)
+	delete(namespace.Annotations, monitoringLabelKeyAnnotation)
+	delete(namespace.Annotations, monitoringLabelPreviousAnnotation)
+	return true
+}
+
+// reconcileMonitoringLabel acquires or releases the monitoring label of the namespace for the ArgoCD instance, and
+// patches the namespace when it changed. The label set by the operator is reverted when the namespaces are no
+// longer labelled. The patch is rejected if the namespace changed since it was read, so that the owners concurrently
+// recorded by another instance are not lost, and retried on the latest namespace.
+func (r *ArgoCDMetricsReconciler) reconcileMonitoringLabel(ctx context.Context, namespace *corev1.Namespace,
+	argocd *argoapp.ArgoCD, enabled bool, reqLogger logr.Logger) error {
+	label := r.Prometheus.monitoringLabel(namespace.Name)
+	changed, refresh := false, false
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if refresh {
+			if err := r.Client.Get(ctx, client.ObjectKeyFromObject(namespace), namespace); err != nil {
+				return err
+			}
+		}
+		refresh = true
+		original := namespace.DeepCopy()
+		switch {
+		case enabled && label != "":
+			changed = acquireMonitoringLabel(namespace, label, argocd.Name)
+		case enabled:
+			changed = revertMonitoringLabel(namespace, label)
+		default:
+			changed = releaseMonitoringLabel(namespace, label, argocd.Name)
+		}
+		if !changed {
+			return nil
+		}
+		return r.Client.Patch(ctx, namespace, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+	})
+	if !changed && err == nil {
+		return nil
+	}
+	err = recordResourceEvent(r.Recorder, argocd, resourceUpdate, namespace, err)
+	if err != nil {
+		reqLogger.Error(err, "Error updating namespace",
+			"Namespace", namespace.Name)
+	}
+	return err
+}
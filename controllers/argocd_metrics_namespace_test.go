@@ -0,0 +1,231 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestMonitoringLabel(t *testing.T) {
+	testCases := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		acquire     []string
+		release     []string
+		// releaseLabel is the label selected by the configuration on release, the label acquired by default
+		releaseLabel        string
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:                "label added and removed",
+			acquire:             []string{"argocd"},
+			release:             []string{"argocd"},
+			expectedLabels:      map[string]string{},
+			expectedAnnotations: map[string]string{},
+		},
+		{
+			name:                "label set by the user is left unchanged",
+			labels:              map[string]string{userDefinedMonitoringLabel: "true"},
+			acquire:             []string{"argocd"},
+			release:             []string{"argocd"},
+			expectedLabels:      map[string]string{userDefinedMonitoringLabel: "true"},
+			expectedAnnotations: nil,
+		},
+		{
+			name:                "previous value restored",
+			labels:              map[string]string{userDefinedMonitoringLabel: "false"},
+			acquire:             []string{"argocd"},
+			release:             []string{"argocd"},
+			expectedLabels:      map[string]string{userDefinedMonitoringLabel: "false"},
+			expectedAnnotations: map[string]string{},
+		},
+		{
+			name:           "label kept while another instance has metrics enabled",
+			acquire:        []string{"argocd", "other"},
+			release:        []string{"argocd"},
+			expectedLabels: map[string]string{userDefinedMonitoringLabel: "true"},
+			expectedAnnotations: map[string]string{
+				monitoringLabelOwnersAnnotation: "other",
+				monitoringLabelKeyAnnotation:    userDefinedMonitoringLabel,
+			},
+		},
+		{
+			name:                "label removed with the last instance",
+			acquire:             []string{"argocd", "other"},
+			release:             []string{"other", "argocd"},
+			expectedLabels:      map[string]string{},
+			expectedAnnotations: map[string]string{},
+		},
+		{
+			name:                "label set by the operator on namespace creation",
+			labels:              map[string]string{userDefinedMonitoringLabel: "true"},
+			annotations:         map[string]string{monitoringLabelOwnersAnnotation: ""},
+			acquire:             []string{"argocd"},
+			release:             []string{"argocd"},
+			expectedLabels:      map[string]string{},
+			expectedAnnotations: map[string]string{},
+		},
+		{
+			name:                "label not owned by the instance",
+			labels:              map[string]string{userDefinedMonitoringLabel: "true"},
+			annotations:         map[string]string{monitoringLabelOwnersAnnotation: "other"},
+			release:             []string{"argocd"},
+			expectedLabels:      map[string]string{userDefinedMonitoringLabel: "true"},
+			expectedAnnotations: map[string]string{monitoringLabelOwnersAnnotation: "other"},
+		},
+		{
+			name:   "recorded label released whatever the configured label",
+			labels: map[string]string{clusterMonitoringLabel: "true", userDefinedMonitoringLabel: "false"},
+			annotations: map[string]string{
+				monitoringLabelOwnersAnnotation:   "argocd",
+				monitoringLabelKeyAnnotation:      clusterMonitoringLabel,
+				monitoringLabelPreviousAnnotation: "false",
+			},
+			release:             []string{"argocd"},
+			releaseLabel:        userDefinedMonitoringLabel,
+			expectedLabels:      map[string]string{clusterMonitoringLabel: "false", userDefinedMonitoringLabel: "false"},
+			expectedAnnotations: map[string]string{},
+		},
+		{
+			name:   "label of the previous configuration reverted",
+			labels: map[string]string{clusterMonitoringLabel: "true"},
+			annotations: map[string]string{
+				monitoringLabelOwnersAnnotation: "other",
+				monitoringLabelKeyAnnotation:    clusterMonitoringLabel,
+			},
+			acquire:        []string{"argocd"},
+			expectedLabels: map[string]string{userDefinedMonitoringLabel: "true"},
+			expectedAnnotations: map[string]string{
+				monitoringLabelOwnersAnnotation: "other,argocd",
+				monitoringLabelKeyAnnotation:    userDefinedMonitoringLabel,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ns := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "test", Labels: tc.labels, Annotations: tc.annotations}}
+			for _, instance := range tc.acquire {
+				acquireMonitoringLabel(ns, userDefinedMonitoringLabel, instance)
+			}
+			releaseLabel := tc.releaseLabel
+			if releaseLabel == "" {
+				releaseLabel = userDefinedMonitoringLabel
+			}
+			for _, instance := range tc.release {
+				releaseMonitoringLabel(ns, releaseLabel, instance)
+			}
+			assert.DeepEqual(t, ns.Labels, tc.expectedLabels)
+			assert.DeepEqual(t, ns.Annotations, tc.expectedAnnotations)
+		})
+	}
+}
+
+func TestReconcile_monitoring_label(t *testing.T) {
+	s := newScheme()
+	ns := &corev1.Namespace{
+		ObjectMeta: v1.ObjectMeta{Name: "namespace-two", Labels: map[string]string{userDefinedMonitoringLabel: "false"}},
+	}
+	instanceTwo := &argoapp.ArgoCD{ObjectMeta: v1.ObjectMeta{Name: "instance-two", Namespace: "namespace-two"}}
+	instanceThree := &argoapp.ArgoCD{ObjectMeta: v1.ObjectMeta{Name: "instance-three", Namespace: "namespace-two"}}
+	updates := 0
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(ns, instanceTwo, instanceThree).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if _, ok := obj.(*corev1.Namespace); ok {
+					updates++
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		}).Build()
+	r := ArgoCDMetricsReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(100)}
+
+	label := func() string {
+		ns := &corev1.Namespace{}
+		assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two"}, ns))
+		return ns.Labels[userDefinedMonitoringLabel]
+	}
+	reconcile := func(name string) {
+		_, err := r.Reconcile(context.TODO(), newRequest("namespace-two", name))
+		assert.NilError(t, err)
+	}
+
+	reconcile("instance-two")
+	reconcile("instance-three")
+	assert.Equal(t, label(), "true")
+
+	// the label is kept while another instance of the namespace has metrics enabled
+	assert.NilError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(instanceTwo), instanceTwo))
+	instanceTwo.Spec.Monitoring.DisableMetrics = ptr.To(true)
+	assert.NilError(t, r.Client.Update(context.TODO(), instanceTwo))
+	reconcile("instance-two")
+	assert.Equal(t, label(), "true")
+
+	// the previous value is restored once the last instance is deleted
+	assert.NilError(t, r.Client.Delete(context.TODO(), instanceThree))
+	reconcile("instance-three")
+	assert.Equal(t, label(), "false")
+
+	// the namespace is patched, never updated
+	assert.Equal(t, updates, 0)
+}
+
+func TestReconcile_monitoring_label_conflict(t *testing.T) {
+	s := newScheme()
+	ns := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "namespace-two"}}
+	instanceTwo := &argoapp.ArgoCD{ObjectMeta: v1.ObjectMeta{Name: "instance-two", Namespace: "namespace-two"}}
+	patches := 0
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(ns, instanceTwo).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if _, ok := obj.(*corev1.Namespace); ok {
+					patches++
+					if patches == 1 {
+						// another instance of the namespace acquires the label concurrently
+						concurrent := &corev1.Namespace{}
+						assert.NilError(t, c.Get(ctx, client.ObjectKeyFromObject(obj), concurrent))
+						acquireMonitoringLabel(concurrent, userDefinedMonitoringLabel, "instance-three")
+						assert.NilError(t, c.Update(ctx, concurrent))
+					}
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()
+	r := ArgoCDMetricsReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(100)}
+
+	_, err := r.Reconcile(context.TODO(), newRequest("namespace-two", "instance-two"))
+	assert.NilError(t, err)
+
+	// the patch based on the stale namespace is rejected, and retried on the latest one
+	assert.Equal(t, patches, 2)
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two"}, ns))
+	assert.Equal(t, ns.Labels[userDefinedMonitoringLabel], "true")
+	assert.Equal(t, ns.Annotations[monitoringLabelOwnersAnnotation], "instance-three,instance-two")
+}
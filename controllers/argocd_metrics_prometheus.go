@@ -0,0 +1,125 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// NamespaceMonitoring selects the label enabling the monitoring of the namespaces of the ArgoCD instances
+type NamespaceMonitoring string
+
+const (
+	// NamespaceMonitoringAuto enables the cluster monitoring of the openshift- namespaces, the user workload
+	// monitoring of the other namespaces
+	NamespaceMonitoringAuto NamespaceMonitoring = "auto"
+	// NamespaceMonitoringCluster enables the cluster monitoring of all the namespaces
+	NamespaceMonitoringCluster NamespaceMonitoring = "cluster"
+	// NamespaceMonitoringUser enables the user workload monitoring of all the namespaces
+	NamespaceMonitoringUser NamespaceMonitoring = "user"
+	// NamespaceMonitoringNone leaves the namespaces unlabelled, for a Prometheus selecting them otherwise
+	NamespaceMonitoringNone NamespaceMonitoring = "none"
+)
+
+const (
+	// DefaultPrometheusServiceAccount is the ServiceAccount of the OpenShift cluster monitoring Prometheus
+	DefaultPrometheusServiceAccount = "prometheus-k8s"
+	// DefaultPrometheusNamespace is the namespace of the OpenShift cluster monitoring Prometheus
+	DefaultPrometheusNamespace = "openshift-monitoring"
+	// DefaultServiceMonitorLabels are the labels set on the ServiceMonitors of the ArgoCD instances by default
+	DefaultServiceMonitorLabels = "release=prometheus-operator"
+)
+
+// PrometheusConfig configures the Prometheus stack scraping the metrics of the ArgoCD instances. The zero value
+// selects the OpenShift monitoring stack.
+type PrometheusConfig struct {
+	// ServiceAccount and Namespace of the Prometheus granted read access to the namespaces of the ArgoCD instances
+	ServiceAccount string
+	Namespace      string
+	// ServiceMonitorLabels are set on the ServiceMonitors of the ArgoCD instances, to match the ServiceMonitor
+	// selector of the Prometheus. Nil sets the default labels, empty sets none.
+	ServiceMonitorLabels map[string]string
+	// NamespaceMonitoring selects the monitoring label of the namespaces, auto by default
+	NamespaceMonitoring NamespaceMonitoring
+}
+
+// Validate returns an error if the configuration is invalid
+func (c PrometheusConfig) Validate() error {
+	switch c.NamespaceMonitoring {
+	case "", NamespaceMonitoringAuto, NamespaceMonitoringCluster, NamespaceMonitoringUser, NamespaceMonitoringNone:
+	default:
+		return fmt.Errorf("invalid namespace monitoring %q: must be one of %s, %s, %s or %s", c.NamespaceMonitoring,
+			NamespaceMonitoringAuto, NamespaceMonitoringCluster, NamespaceMonitoringUser, NamespaceMonitoringNone)
+	}
+	if msgs := validation.IsDNS1123Subdomain(c.serviceAccount()); len(msgs) > 0 {
+		return fmt.Errorf("invalid Prometheus ServiceAccount %q: %s", c.ServiceAccount, strings.Join(msgs, ", "))
+	}
+	if msgs := validation.IsDNS1123Label(c.namespace()); len(msgs) > 0 {
+		return fmt.Errorf("invalid Prometheus namespace %q: %s", c.Namespace, strings.Join(msgs, ", "))
+	}
+	for key, value := range c.ServiceMonitorLabels {
+		if msgs := validation.IsQualifiedName(key); len(msgs) > 0 {
+			return fmt.Errorf("invalid ServiceMonitor label %q: %s", key, strings.Join(msgs, ", "))
+		}
+		if msgs := validation.IsValidLabelValue(value); len(msgs) > 0 {
+			return fmt.Errorf("invalid value of ServiceMonitor label %q: %s", key, strings.Join(msgs, ", "))
+		}
+	}
+	return nil
+}
+
+func (c PrometheusConfig) serviceAccount() string {
+	if c.ServiceAccount == "" {
+		return DefaultPrometheusServiceAccount
+	}
+	return c.ServiceAccount
+}
+
+func (c PrometheusConfig) namespace() string {
+	if c.Namespace == "" {
+		return DefaultPrometheusNamespace
+	}
+	return c.Namespace
+}
+
+func (c PrometheusConfig) serviceMonitorLabels() map[string]string {
+	if c.ServiceMonitorLabels == nil {
+		return map[string]string{"release": "prometheus-operator"}
+	}
+	return maps.Clone(c.ServiceMonitorLabels)
+}
+
+// monitoringLabel returns the label enabling the monitoring of the namespace, or an empty string if the namespaces
+// are not labelled
+func (c PrometheusConfig) monitoringLabel(namespace string) string {
+	switch c.NamespaceMonitoring {
+	case NamespaceMonitoringCluster:
+		return clusterMonitoringLabel
+	case NamespaceMonitoringUser:
+		return userDefinedMonitoringLabel
+	case NamespaceMonitoringNone:
+		return ""
+	}
+	if strings.HasPrefix(namespace, "openshift-") {
+		return clusterMonitoringLabel
+	}
+	return userDefinedMonitoringLabel
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestPrometheusConfig(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		config               PrometheusConfig
+		wantErr              string
+		openshiftLabel       string
+		namespaceLabel       string
+		serviceMonitorLabels map[string]string
+	}{
+		{
+			name:                 "default",
+			openshiftLabel:       clusterMonitoringLabel,
+			namespaceLabel:       userDefinedMonitoringLabel,
+			serviceMonitorLabels: map[string]string{"release": "prometheus-operator"},
+		},
+		{
+			name:                 "cluster monitoring",
+			config:               PrometheusConfig{NamespaceMonitoring: NamespaceMonitoringCluster, ServiceMonitorLabels: map[string]string{}},
+			openshiftLabel:       clusterMonitoringLabel,
+			namespaceLabel:       clusterMonitoringLabel,
+			serviceMonitorLabels: map[string]string{},
+		},
+		{
+			name:                 "user workload monitoring",
+			config:               PrometheusConfig{NamespaceMonitoring: NamespaceMonitoringUser},
+			openshiftLabel:       userDefinedMonitoringLabel,
+			namespaceLabel:       userDefinedMonitoringLabel,
+			serviceMonitorLabels: map[string]string{"release": "prometheus-operator"},
+		},
+		{
+			name:                 "no monitoring label",
+			config:               PrometheusConfig{NamespaceMonitoring: NamespaceMonitoringNone, ServiceMonitorLabels: map[string]string{"release": "kube-prometheus-stack"}},
+			serviceMonitorLabels: map[string]string{"release": "kube-prometheus-stack"},
+		},
+		{
+			name:    "invalid namespace monitoring",
+			config:  PrometheusConfig{NamespaceMonitoring: "workload"},
+			wantErr: `invalid namespace monitoring "workload": must be one of auto, cluster, user or none`,
+		},
+		{
+			name:    "invalid namespace",
+			config:  PrometheusConfig{Namespace: "Monitoring"},
+			wantErr: `invalid Prometheus namespace "Monitoring"`,
+		},
+		{
+			name:    "invalid label",
+			config:  PrometheusConfig{ServiceMonitorLabels: map[string]string{"release/": "prometheus"}},
+			wantErr: `invalid ServiceMonitor label "release/"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.config.monitoringLabel("openshift-gitops"), tc.openshiftLabel)
+			assert.Equal(t, tc.config.monitoringLabel("namespace-two"), tc.namespaceLabel)
+			assert.DeepEqual(t, tc.config.serviceMonitorLabels(), tc.serviceMonitorLabels)
+		})
+	}
+}
+
+func TestReconcile_prometheus_config(t *testing.T) {
+	r := newMetricsReconciler(t, "namespace-two", "instance-two", nil)
+	r.Prometheus = PrometheusConfig{
+		ServiceAccount:       "kube-prometheus-stack-prometheus",
+		Namespace:            "monitoring",
+		ServiceMonitorLabels: map[string]string{"release": "kube-prometheus-stack"},
+		NamespaceMonitoring:  NamespaceMonitoringNone,
+	}
+	reconcileInstanceTwo(t, r, nil)
+
+	ns := &corev1.Namespace{}
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two"}, ns))
+	assert.DeepEqual(t, ns.Labels, map[string]string(nil))
+
+	serviceMonitor := &monitoringv1.ServiceMonitor{}
+	assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "instance-two", Namespace: "namespace-two"}, serviceMonitor))
+	assert.DeepEqual(t, serviceMonitor.Labels, map[string]string{"release": "kube-prometheus-stack"})
+
+	roleBinding := &rbacv1.RoleBinding{}
+	name := types.NamespacedName{Name: "namespace-two-prometheus-k8s-read-binding", Namespace: "namespace-two"}
+	assert.NilError(t, r.Client.Get(context.TODO(), name, roleBinding))
+	assert.DeepEqual(t, roleBinding.Subjects, []rbacv1.Subject{
+		{Kind: "ServiceAccount", Name: "kube-prometheus-stack-prometheus", Namespace: "monitoring"},
+	})
+
+	// the read permission is granted to the Prometheus configured after the role binding was created
+	r.Prometheus.Namespace = "user-monitoring"
+	reconcileInstanceTwo(t, r, nil)
+	assert.NilError(t, r.Client.Get(context.TODO(), name, roleBinding))
+	assert.DeepEqual(t, roleBinding.Subjects, []rbacv1.Subject{
+		{Kind: "ServiceAccount", Name: "kube-prometheus-stack-prometheus", Namespace: "user-monitoring"},
+	})
+}
+
+func TestReconcile_namespace_monitoring_change(t *testing.T) {
+	r := newMetricsReconciler(t, "namespace-two", "instance-two", nil)
+	namespace := func() *corev1.Namespace {
+		t.Helper()
+		ns := &corev1.Namespace{}
+		assert.NilError(t, r.Client.Get(context.TODO(), types.NamespacedName{Name: "namespace-two"}, ns))
+		return ns
+	}
+	reconcileInstanceTwo(t, r, nil)
+	assert.DeepEqual(t, namespace().Labels, map[string]string{userDefinedMonitoringLabel: "true"})
+
+	// the label set with the previous configuration is reverted
+	r.Prometheus.NamespaceMonitoring = NamespaceMonitoringCluster
+	reconcileInstanceTwo(t, r, nil)
+	ns := namespace()
+	assert.DeepEqual(t, ns.Labels, map[string]string{clusterMonitoringLabel: "true"})
+	assert.Equal(t, ns.Annotations[monitoringLabelKeyAnnotation], clusterMonitoringLabel)
+
+	r.Prometheus.NamespaceMonitoring = NamespaceMonitoringNone
+	reconcileInstanceTwo(t, r, nil)
+	ns = namespace()
+	assert.Equal(t, len(ns.Labels), 0)
+	assert.Equal(t, len(ns.Annotations), 0)
+}
@@ -14,7 +14,7 @@ import (
 	argocommon "github.com/argoproj-labs/argocd-operator/common"
 	argocdutil "github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 	consolev1 "github.com/openshift/api/console/v1"
-	pipelinesv1alpha1 "github.com/redhat-developer/gitops-operator/api/v1alpha1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	"github.com/redhat-developer/gitops-operator/controllers/util"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -363,9 +363,17 @@ func sortTolerations(tolerations []corev1.Toleration) []corev1.Toleration {
 	return sorted
 }
 
-func (r *ReconcileGitopsService) reconcileDeployment(cr *pipelinesv1alpha1.GitopsService, request reconcile.Request, newPluginConfigMap *corev1.ConfigMap) (reconcile.Result, error) {
+func (r *ReconcileGitopsService) reconcileDeployment(cr *pipelinesv1beta1.GitopsService, request reconcile.Request, newPluginConfigMap *corev1.ConfigMap) (reconcile.Result, error) {
 	reqLogger := logs.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
-	newPluginDeployment := pluginDeployment(cr.Spec.ImagePullPolicy)
+	plugin := cr.Spec.ConsolePlugin
+	if plugin == nil {
+		plugin = &pipelinesv1beta1.ConsolePluginSpec{}
+	}
+	pullPolicy := cr.Spec.ImagePullPolicy
+	if plugin.ImagePullPolicy != "" {
+		pullPolicy = plugin.ImagePullPolicy
+	}
+	newPluginDeployment := pluginDeployment(pullPolicy)
 
 	if err := controllerutil.SetControllerReference(cr, newPluginDeployment, r.Scheme); err != nil {
 		return reconcile.Result{}, err
@@ -373,16 +381,16 @@ func (r *ReconcileGitopsService) reconcileDeployment(cr *pipelinesv1alpha1.Gitop
 
 	newPluginDeployment.Spec.Template.Spec.NodeSelector = argocommon.DefaultNodeSelector()
 
-	if len(cr.Spec.NodeSelector) > 0 {
-		newPluginDeployment.Spec.Template.Spec.NodeSelector = argocdutil.AppendStringMap(newPluginDeployment.Spec.Template.Spec.NodeSelector, cr.Spec.NodeSelector)
+	if len(plugin.NodeSelector) > 0 {
+		newPluginDeployment.Spec.Template.Spec.NodeSelector = argocdutil.AppendStringMap(newPluginDeployment.Spec.Template.Spec.NodeSelector, plugin.NodeSelector)
 	}
 
-	if cr.Spec.ConsolePlugin != nil && cr.Spec.ConsolePlugin.GitopsPlugin != nil && cr.Spec.ConsolePlugin.GitopsPlugin.Resources != nil {
-		newPluginDeployment.Spec.Template.Spec.Containers[0].Resources = *cr.Spec.ConsolePlugin.GitopsPlugin.Resources
+	if plugin.Resources != nil {
+		newPluginDeployment.Spec.Template.Spec.Containers[0].Resources = *plugin.Resources
 	}
 
-	if len(cr.Spec.Tolerations) > 0 {
-		newPluginDeployment.Spec.Template.Spec.Tolerations = cr.Spec.Tolerations
+	if len(plugin.Tolerations) > 0 {
+		newPluginDeployment.Spec.Template.Spec.Tolerations = plugin.Tolerations
 	}
 
 	// ADD THIS: Get ConfigMap and add hash to pod template annotations
@@ -392,6 +400,13 @@ func (r *ReconcileGitopsService) reconcileDeployment(cr *pipelinesv1alpha1.Gitop
 	}
 	newPluginDeployment.Spec.Template.ObjectMeta.Annotations["httpd-cfg-hash"] = configMapHash
 
+	setWorkloadAvailability(newPluginDeployment, plugin.Replicas, plugin.Affinity, plugin.TopologySpreadConstraints)
+	applyPodTemplateOverrides(newPluginDeployment, plugin.PodTemplateOverrides)
+
+	if err := r.reconcilePodDisruptionBudget(cr, newPodDisruptionBudget(newPluginDeployment), reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// Check if this Deployment already exists
 	existingPluginDeployment := &appsv1.Deployment{}
 
@@ -399,7 +414,7 @@ func (r *ReconcileGitopsService) reconcileDeployment(cr *pipelinesv1alpha1.Gitop
 	if err != nil {
 		if errors.IsNotFound(err) {
 			reqLogger.Info("Creating a new Plugin Deployment", "Namespace", newPluginDeployment.Namespace, "Name", newPluginDeployment.Name)
-			err = r.Client.Create(context.TODO(), newPluginDeployment)
+			err = recordResourceEvent(r.Recorder, cr, resourceCreate, newPluginDeployment, r.Client.Create(context.TODO(), newPluginDeployment))
 			if err != nil {
 				return reconcile.Result{}, err
 			}
@@ -421,9 +436,17 @@ func (r *ReconcileGitopsService) reconcileDeployment(cr *pipelinesv1alpha1.Gitop
 			!equality.Semantic.DeepEqual(existingSpecTemplate.Spec.DNSPolicy, newSpecTemplate.Spec.DNSPolicy) ||
 			!equality.Semantic.DeepEqual(existingPluginDeployment.Spec.Template.Spec.NodeSelector, newPluginDeployment.Spec.Template.Spec.NodeSelector) ||
 			!equality.Semantic.DeepEqual(sortTolerations(existingPluginDeployment.Spec.Template.Spec.Tolerations), sortTolerations(newPluginDeployment.Spec.Template.Spec.Tolerations)) ||
+			!equality.Semantic.DeepEqual(existingSpecTemplate.Spec.Affinity, newSpecTemplate.Spec.Affinity) ||
+			!equality.Semantic.DeepEqual(existingSpecTemplate.Spec.TopologySpreadConstraints, newSpecTemplate.Spec.TopologySpreadConstraints) ||
+			!equality.Semantic.DeepEqual(existingSpecTemplate.Spec.PriorityClassName, newSpecTemplate.Spec.PriorityClassName) ||
 			!equality.Semantic.DeepEqual(existingSpecTemplate.Spec.SecurityContext, newSpecTemplate.Spec.SecurityContext) ||
 			!equality.Semantic.DeepEqual(existingSpecTemplate.Spec.Containers[0].Resources, newSpecTemplate.Spec.Containers[0].Resources)
 
+		// Annotations set from the overrides are reconciled in place, other annotations are left to their owners
+		if reconcileOverriddenAnnotations(existingPluginDeployment, newPluginDeployment) {
+			changed = true
+		}
+
 		if changed {
 			if existingSpecTemplate.ObjectMeta.Annotations == nil {
 				existingSpecTemplate.ObjectMeta.Annotations = make(map[string]string)
@@ -441,14 +464,17 @@ func (r *ReconcileGitopsService) reconcileDeployment(cr *pipelinesv1alpha1.Gitop
 			existingSpecTemplate.Spec.DNSPolicy = newSpecTemplate.Spec.DNSPolicy
 			existingPluginDeployment.Spec.Template.Spec.NodeSelector = newPluginDeployment.Spec.Template.Spec.NodeSelector
 			existingPluginDeployment.Spec.Template.Spec.Tolerations = newPluginDeployment.Spec.Template.Spec.Tolerations
+			existingSpecTemplate.Spec.Affinity = newSpecTemplate.Spec.Affinity
+			existingSpecTemplate.Spec.TopologySpreadConstraints = newSpecTemplate.Spec.TopologySpreadConstraints
+			existingSpecTemplate.Spec.PriorityClassName = newSpecTemplate.Spec.PriorityClassName
 			existingSpecTemplate.Spec.Containers[0].Resources = newSpecTemplate.Spec.Containers[0].Resources
-			return reconcile.Result{}, r.Client.Update(context.TODO(), existingPluginDeployment)
+			return reconcile.Result{}, recordResourceEvent(r.Recorder, cr, resourceUpdate, existingPluginDeployment, r.Client.Update(context.TODO(), existingPluginDeployment))
 		}
 	}
 	return reconcile.Result{}, nil
 }
 
-func (r *ReconcileGitopsService) reconcileService(instance *pipelinesv1alpha1.GitopsService, request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileGitopsService) reconcileService(instance *pipelinesv1beta1.GitopsService, request reconcile.Request) (reconcile.Result, error) {
 	reqLogger := logs.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	pluginServiceRef := pluginService()
 	// Set GitopsService instance as the owner and controller
@@ -463,32 +489,21 @@ func (r *ReconcileGitopsService) reconcileService(instance *pipelinesv1alpha1.Gi
 
 		if errors.IsNotFound(err) {
 			reqLogger.Info("Creating a new plugin Service", "Namespace", pluginServiceRef.Namespace, "Name", pluginServiceRef.Name)
-			err = r.Client.Create(context.TODO(), pluginServiceRef)
+			err = recordResourceEvent(r.Recorder, instance, resourceCreate, pluginServiceRef, r.Client.Create(context.TODO(), pluginServiceRef))
 			if err != nil {
 				return reconcile.Result{}, err
 			}
 		} else {
 			return reconcile.Result{}, err
 		}
-	} else {
-		changed := !reflect.DeepEqual(existingServiceRef.Annotations, pluginServiceRef.Annotations) ||
-			!reflect.DeepEqual(existingServiceRef.Labels, pluginServiceRef.Labels) ||
-			!reflect.DeepEqual(existingServiceRef.Spec.Selector, pluginServiceRef.Spec.Selector) ||
-			!reflect.DeepEqual(existingServiceRef.Spec.Ports, pluginServiceRef.Spec.Ports)
-
-		if changed {
-			reqLogger.Info("Reconciling plugin service", "Namespace", existingServiceRef.Namespace, "Name", existingServiceRef.Name)
-			existingServiceRef.Annotations = pluginServiceRef.Annotations
-			existingServiceRef.Labels = pluginServiceRef.Labels
-			existingServiceRef.Spec.Selector = pluginServiceRef.Spec.Selector
-			existingServiceRef.Spec.Ports = pluginServiceRef.Spec.Ports
-			return reconcile.Result{}, r.Client.Update(context.TODO(), pluginServiceRef)
-		}
+	} else if changed := reconcileServiceFields(existingServiceRef, pluginServiceRef); len(changed) > 0 {
+		reqLogger.Info("Reconciling plugin service", "Namespace", existingServiceRef.Namespace, "Name", existingServiceRef.Name, "Changed", changed)
+		return reconcile.Result{}, recordResourceDriftEvent(r.Recorder, instance, existingServiceRef, changed, r.Client.Update(context.TODO(), existingServiceRef))
 	}
 	return reconcile.Result{}, nil
 }
 
-func (r *ReconcileGitopsService) reconcileConsolePlugin(instance *pipelinesv1alpha1.GitopsService, request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileGitopsService) reconcileConsolePlugin(instance *pipelinesv1beta1.GitopsService, request reconcile.Request) (reconcile.Result, error) {
 	reqLogger := logs.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	newConsolePlugin := consolePlugin()
 
@@ -502,7 +517,7 @@ func (r *ReconcileGitopsService) reconcileConsolePlugin(instance *pipelinesv1alp
 		existingPlugin); err != nil {
 		if errors.IsNotFound(err) {
 			reqLogger.Info("Creating a new ConsolePlugin", "Namespace", serviceNamespace, "Name", gitopsPluginName)
-			err = r.Client.Create(context.TODO(), newConsolePlugin)
+			err = recordResourceEvent(r.Recorder, instance, resourceCreate, newConsolePlugin, r.Client.Create(context.TODO(), newConsolePlugin))
 			if err != nil {
 				reqLogger.Error(err, "Error creating a new console plugin",
 					"Name", newConsolePlugin.Name)
@@ -519,7 +534,7 @@ func (r *ReconcileGitopsService) reconcileConsolePlugin(instance *pipelinesv1alp
 			reqLogger.Info("Reconciling Console Plugin", "Namespace", existingPlugin.Namespace, "Name", existingPlugin.Name)
 			existingPlugin.Spec.DisplayName = newConsolePlugin.Spec.DisplayName
 			existingPlugin.Spec.Backend.Service = newConsolePlugin.Spec.Backend.Service
-			return reconcile.Result{}, r.Client.Update(context.TODO(), newConsolePlugin)
+			return reconcile.Result{}, recordResourceEvent(r.Recorder, instance, resourceUpdate, existingPlugin, r.Client.Update(context.TODO(), existingPlugin))
 		}
 	}
 	return reconcile.Result{}, nil
@@ -544,7 +559,7 @@ func getConfigMapHash(cm *corev1.ConfigMap) string {
 	return hex.EncodeToString(hash.Sum(nil))
 }
 
-func (r *ReconcileGitopsService) reconcileConfigMap(instance *pipelinesv1alpha1.GitopsService, request reconcile.Request, newPluginConfigMap *corev1.ConfigMap) (reconcile.Result, error) {
+func (r *ReconcileGitopsService) reconcileConfigMap(instance *pipelinesv1beta1.GitopsService, request reconcile.Request, newPluginConfigMap *corev1.ConfigMap) (reconcile.Result, error) {
 	reqLogger := logs.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 
 	if err := controllerutil.SetControllerReference(instance, newPluginConfigMap, r.Scheme); err != nil {
@@ -557,7 +572,7 @@ func (r *ReconcileGitopsService) reconcileConfigMap(instance *pipelinesv1alpha1.
 	if err != nil {
 		if errors.IsNotFound(err) {
 			reqLogger.Info("Creating a new Plugin ConfigMap", "Namespace", newPluginConfigMap.Namespace, "Name", newPluginConfigMap.Name)
-			err = r.Client.Create(context.TODO(), newPluginConfigMap)
+			err = recordResourceEvent(r.Recorder, instance, resourceCreate, newPluginConfigMap, r.Client.Create(context.TODO(), newPluginConfigMap))
 			if err != nil {
 				return reconcile.Result{}, err
 			}
@@ -571,19 +586,21 @@ func (r *ReconcileGitopsService) reconcileConfigMap(instance *pipelinesv1alpha1.
 			reqLogger.Info("Reconciling plugin configMap", "Namespace", existingPluginConfigMap.Namespace, "Name", existingPluginConfigMap.Name)
 			existingPluginConfigMap.Data = newPluginConfigMap.Data
 			existingPluginConfigMap.Labels = newPluginConfigMap.Labels
-			return reconcile.Result{}, r.Client.Update(context.TODO(), existingPluginConfigMap)
+			return reconcile.Result{}, recordResourceEvent(r.Recorder, instance, resourceUpdate, existingPluginConfigMap, r.Client.Update(context.TODO(), existingPluginConfigMap))
 		}
 	}
 	return reconcile.Result{}, nil
 }
 
 // is this func the reconciler enty point to reconcile the current plugin state?
-func (r *ReconcileGitopsService) reconcilePlugin(instance *pipelinesv1alpha1.GitopsService, request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileGitopsService) reconcilePlugin(instance *pipelinesv1beta1.GitopsService, request reconcile.Request) (reconcile.Result, error) {
 	reqLogger := logs.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	if !util.IsConsoleAPIFound() {
 		reqLogger.Info("Skip console plugin reconcile: OpenShift Console API not found")
+		r.skipEvents.record(r.Recorder, instance, "console plugin", "Console plugin is not reconciled: OpenShift Console API not found")
 		return reconcile.Result{}, nil
 	}
+	r.skipEvents.reconciled(instance, "console plugin")
 
 	// Generate ConfigMap once
 	newPluginConfigMap := r.pluginConfigMap()
@@ -592,6 +609,11 @@ func (r *ReconcileGitopsService) reconcilePlugin(instance *pipelinesv1alpha1.Git
 		return result, err
 	}
 
+	policy := newIngressNetworkPolicy(gitopsPluginName, serviceNamespace, map[string]string{kubeAppLabelApp: gitopsPluginName}, servicePort)
+	if err := r.reconcileNetworkPolicy(instance, policy, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	if result, err := r.reconcileConfigMap(instance, request, newPluginConfigMap); err != nil {
 		return result, err
 	}
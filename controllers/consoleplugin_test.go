@@ -9,18 +9,20 @@ import (
 	argocommon "github.com/argoproj-labs/argocd-operator/common"
 	configv1 "github.com/openshift/api/config/v1"
 	consolev1 "github.com/openshift/api/console/v1"
-	pipelinesv1alpha1 "github.com/redhat-developer/gitops-operator/api/v1alpha1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	"github.com/redhat-developer/gitops-operator/common"
 	"gotest.tools/assert"
 	"gotest.tools/assert/cmp"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	resourcev1 "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -75,7 +77,7 @@ func TestPlugin_reconcileDeployment_changedLabels(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 	var replicas int32 = 1
 
 	for x, test := range tests {
@@ -216,7 +218,7 @@ func TestPlugin_reconcileDeployment_changedReplicas(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -359,7 +361,7 @@ func TestPlugin_reconcileDeployment_changedSelector(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -498,7 +500,7 @@ func TestPlugin_reconcileDeployment_changedTemplateLabels(t *testing.T) {
 	s := scheme.Scheme
 	addKnownTypesToScheme(s)
 
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 	var replicas int32 = 1
 
 	for _, test := range tests {
@@ -621,7 +623,7 @@ func TestPlugin_reconcileDeployment_changedContainers(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	assertPluginDeploymentSpec := func(t *testing.T, deployment *appsv1.Deployment) {
 		t.Helper()
@@ -704,7 +706,7 @@ func TestPlugin_reconcileDeployment_changedRestartPolicy(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -844,7 +846,7 @@ func TestPlugin_reconcileDeployment_changedDNSPolicy(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -965,13 +967,15 @@ func TestPlugin_reconcileDeployment_changedDNSPolicy(t *testing.T) {
 
 func TestPlugin_reconcileDeployment_infraNodeSelectorNotInPodSpec(t *testing.T) {
 
-	gitopsService := &pipelinesv1alpha1.GitopsService{
+	gitopsService := &pipelinesv1beta1.GitopsService{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: serviceName,
 		},
-		Spec: pipelinesv1alpha1.GitopsServiceSpec{
-			RunOnInfra:  true,
-			Tolerations: deploymentDefaultTolerations(),
+		Spec: pipelinesv1beta1.GitopsServiceSpec{
+			RunOnInfra: true,
+			ConsolePlugin: &pipelinesv1beta1.ConsolePluginSpec{
+				Tolerations: deploymentDefaultTolerations(),
+			},
 		},
 	}
 	s := scheme.Scheme
@@ -998,7 +1002,7 @@ func TestPlugin_reconcileDeployment(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	_, err := reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
 	assertNoError(t, err)
@@ -1015,8 +1019,8 @@ func TestPlugin_reconcileDeployment_ChangedResources(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{
-		Spec: pipelinesv1alpha1.GitopsServiceSpec{},
+	instance := &pipelinesv1beta1.GitopsService{
+		Spec: pipelinesv1beta1.GitopsServiceSpec{},
 	}
 	Resources := &corev1.ResourceRequirements{
 		Requests: corev1.ResourceList{
@@ -1028,13 +1032,11 @@ func TestPlugin_reconcileDeployment_ChangedResources(t *testing.T) {
 			corev1.ResourceCPU:    resourcev1.MustParse("5"),
 		},
 	}
-	instance.Spec.ConsolePlugin = &pipelinesv1alpha1.ConsolePluginStruct{
-		Backend: &pipelinesv1alpha1.BackendStruct{
-			Resources: Resources,
-		},
-		GitopsPlugin: &pipelinesv1alpha1.GitopsPluginStruct{
-			Resources: Resources,
-		},
+	instance.Spec.Backend = &pipelinesv1beta1.BackendSpec{
+		Resources: Resources,
+	}
+	instance.Spec.ConsolePlugin = &pipelinesv1beta1.ConsolePluginSpec{
+		Resources: Resources,
 	}
 
 	_, err := reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
@@ -1049,6 +1051,65 @@ func TestPlugin_reconcileDeployment_ChangedResources(t *testing.T) {
 	assert.Equal(t, deployment.Spec.Template.Spec.Containers[0].Resources.Limits["cpu"], resourcev1.MustParse("5"))
 }
 
+func TestPlugin_reconcileDeployment_HighAvailability(t *testing.T) {
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	instance := newGitopsService()
+
+	_, err := reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
+	assertNoError(t, err)
+
+	// A single replica is not spread across nodes, but is still covered by a PodDisruptionBudget
+	deployment := &appsv1.Deployment{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: gitopsPluginName, Namespace: serviceNamespace}, deployment)
+	assertNoError(t, err)
+	assert.Equal(t, *deployment.Spec.Replicas, int32(1))
+	assert.Assert(t, deployment.Spec.Template.Spec.Affinity == nil)
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: gitopsPluginName, Namespace: serviceNamespace}, pdb)
+	assertNoError(t, err)
+	assert.DeepEqual(t, pdb.Spec.MaxUnavailable, ptr.To(intstr.FromInt32(1)))
+	assert.DeepEqual(t, pdb.Spec.Selector, deployment.Spec.Selector)
+	assert.Equal(t, pdb.OwnerReferences[0].Kind, "GitopsService")
+
+	// Multiple replicas are preferably scheduled on different nodes
+	instance.Spec.ConsolePlugin = &pipelinesv1beta1.ConsolePluginSpec{
+		Replicas: ptr.To(int32(3)),
+	}
+	_, err = reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: gitopsPluginName, Namespace: serviceNamespace}, deployment)
+	assertNoError(t, err)
+	assert.Equal(t, *deployment.Spec.Replicas, int32(3))
+	terms := deployment.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	assert.Equal(t, len(terms), 1)
+	assert.Equal(t, terms[0].PodAffinityTerm.TopologyKey, "kubernetes.io/hostname")
+	assert.DeepEqual(t, terms[0].PodAffinityTerm.LabelSelector, deployment.Spec.Selector)
+
+	// Topology spread constraints replace the default anti-affinity
+	constraints := []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+			LabelSelector:     deployment.Spec.Selector,
+		},
+	}
+	instance.Spec.ConsolePlugin.TopologySpreadConstraints = constraints
+	_, err = reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: gitopsPluginName, Namespace: serviceNamespace}, deployment)
+	assertNoError(t, err)
+	assert.Assert(t, deployment.Spec.Template.Spec.Affinity == nil)
+	assert.DeepEqual(t, deployment.Spec.Template.Spec.TopologySpreadConstraints, constraints)
+}
+
 // Test to verify if the default resource values are set when no resource values are provided in the CR
 func TestPlugin_ReconcileDeployment_DefaultResourceValues(t *testing.T) {
 	s := scheme.Scheme
@@ -1056,7 +1117,7 @@ func TestPlugin_ReconcileDeployment_DefaultResourceValues(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 	_, err := reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
 	assertNoError(t, err)
 
@@ -1076,8 +1137,8 @@ func TestPlugin_ReconcileDeployment_ChangeExistingResourceValues(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{
-		Spec: pipelinesv1alpha1.GitopsServiceSpec{},
+	instance := &pipelinesv1beta1.GitopsService{
+		Spec: pipelinesv1beta1.GitopsServiceSpec{},
 	}
 	Resources := &corev1.ResourceRequirements{
 		Requests: corev1.ResourceList{
@@ -1089,13 +1150,11 @@ func TestPlugin_ReconcileDeployment_ChangeExistingResourceValues(t *testing.T) {
 			corev1.ResourceCPU:    resourcev1.MustParse("5"),
 		},
 	}
-	instance.Spec.ConsolePlugin = &pipelinesv1alpha1.ConsolePluginStruct{
-		Backend: &pipelinesv1alpha1.BackendStruct{
-			Resources: Resources,
-		},
-		GitopsPlugin: &pipelinesv1alpha1.GitopsPluginStruct{
-			Resources: Resources,
-		},
+	instance.Spec.Backend = &pipelinesv1beta1.BackendSpec{
+		Resources: Resources,
+	}
+	instance.Spec.ConsolePlugin = &pipelinesv1beta1.ConsolePluginSpec{
+		Resources: Resources,
 	}
 	_, err := reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
 	assertNoError(t, err)
@@ -1119,7 +1178,7 @@ func TestPlugin_ReconcileDeployment_ChangeExistingResourceValues(t *testing.T) {
 			corev1.ResourceCPU:    resourcev1.MustParse("400m"),
 		},
 	}
-	instance.Spec.ConsolePlugin.Backend.Resources, instance.Spec.ConsolePlugin.GitopsPlugin.Resources = updatedResources, updatedResources
+	instance.Spec.Backend.Resources, instance.Spec.ConsolePlugin.Resources = updatedResources, updatedResources
 
 	_, err = reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
 	assertNoError(t, err)
@@ -1141,7 +1200,7 @@ func TestPlugin_reconcileService(t *testing.T) {
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
 
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 	_, err := reconciler.reconcileService(instance, newRequest(serviceNamespace, gitopsPluginName))
 	assertNoError(t, err)
 
@@ -1150,6 +1209,39 @@ func TestPlugin_reconcileService(t *testing.T) {
 	assertNoError(t, err)
 }
 
+func TestPlugin_reconcileService_drift(t *testing.T) {
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+
+	instance := newGitopsService()
+	_, err := reconciler.reconcileService(instance, newRequest(serviceNamespace, gitopsPluginName))
+	assertNoError(t, err)
+
+	name := types.NamespacedName{Name: gitopsPluginName, Namespace: serviceNamespace}
+	service := &corev1.Service{}
+	assertNoError(t, fakeClient.Get(context.TODO(), name, service))
+	service.Labels[kubeAppLabelPartOf] = "other"
+	service.Labels["example.com/team"] = "gitops"
+	service.Spec.Type = corev1.ServiceTypeNodePort
+	assertNoError(t, fakeClient.Update(context.TODO(), service))
+	recordedEvents(recorder)
+
+	_, err = reconciler.reconcileService(instance, newRequest(serviceNamespace, gitopsPluginName))
+	assertNoError(t, err)
+
+	assertNoError(t, fakeClient.Get(context.TODO(), name, service))
+	assert.Equal(t, service.Labels[kubeAppLabelPartOf], gitopsPluginName)
+	assert.Equal(t, service.Labels["example.com/team"], "gitops")
+	assert.Equal(t, service.Spec.Type, corev1.ServiceType(""))
+	assert.DeepEqual(t, recordedEvents(recorder, "Updated"), []string{
+		"Normal Updated Updated Service openshift-gitops/gitops-plugin: reverted changes to labels, type",
+	})
+}
+
 func TestPlugin_reconcileService_changedAnnotations(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -1174,7 +1266,7 @@ func TestPlugin_reconcileService_changedAnnotations(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1252,7 +1344,7 @@ func TestPlugin_reconcileService_changedLabels(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1321,7 +1413,7 @@ func TestPlugin_reconcileService_changedSelector(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1396,7 +1488,7 @@ func TestPlugin_reconcileService_changedPorts(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1453,7 +1545,7 @@ func TestPlugin_reconcileConsolePlugin(t *testing.T) {
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
 
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	_, err := reconciler.reconcileConsolePlugin(instance, newRequest(serviceNamespace, gitopsPluginName))
 	assertNoError(t, err)
@@ -1483,7 +1575,7 @@ func TestPlugin_reconcileConsolePlugin_changedDisplayName(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1550,7 +1642,7 @@ func TestPlugin_reconcileConsolePlugin_changedService(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1593,7 +1685,7 @@ func TestPlug_reconcileConfigMap(t *testing.T) {
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
 
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 	_, err := reconciler.reconcileConfigMap(instance, newRequest(serviceNamespace, httpdConfigMapName), reconciler.pluginConfigMap())
 	assertNoError(t, err)
 
@@ -1628,7 +1720,7 @@ func TestPlug_reconcileConfigMap_changedLabels(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	for x, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -1667,7 +1759,7 @@ func TestReconcileDeployment_NoUpdateWhenContainersOrderDiffers(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	// Create deployment
 	_, err := reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
@@ -1713,7 +1805,7 @@ func TestReconcileDeployment_NoUpdateWhenVolumesOrderDiffers(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	// Create deployment
 	_, err := reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
@@ -1763,21 +1855,23 @@ func TestReconcileDeployment_NoUpdateWhenTolerationsOrderDiffers(t *testing.T) {
 	addKnownTypesToScheme(s)
 
 	// Create GitopsService with tolerations
-	gitopsService := &pipelinesv1alpha1.GitopsService{
+	gitopsService := &pipelinesv1beta1.GitopsService{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: serviceName,
 		},
-		Spec: pipelinesv1alpha1.GitopsServiceSpec{
-			Tolerations: []corev1.Toleration{
-				{
-					Key:      "key-a",
-					Operator: corev1.TolerationOpEqual,
-					Effect:   corev1.TaintEffectNoSchedule,
-				},
-				{
-					Key:      "key-b",
-					Operator: corev1.TolerationOpEqual,
-					Effect:   corev1.TaintEffectNoSchedule,
+		Spec: pipelinesv1beta1.GitopsServiceSpec{
+			ConsolePlugin: &pipelinesv1beta1.ConsolePluginSpec{
+				Tolerations: []corev1.Toleration{
+					{
+						Key:      "key-a",
+						Operator: corev1.TolerationOpEqual,
+						Effect:   corev1.TaintEffectNoSchedule,
+					},
+					{
+						Key:      "key-b",
+						Operator: corev1.TolerationOpEqual,
+						Effect:   corev1.TaintEffectNoSchedule,
+					},
 				},
 			},
 		},
@@ -1835,7 +1929,7 @@ func TestReconcileDeployment_UpdateWhenActualChange(t *testing.T) {
 
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 
 	// Create deployment
 	_, err := reconciler.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsPluginName), reconciler.pluginConfigMap())
@@ -2007,8 +2101,9 @@ func TestReconcileDeployment_AddsHashAnnotation(t *testing.T) {
 	scheme := runtime.NewScheme()
 	assert.NilError(t, appsv1.AddToScheme(scheme))
 	assert.NilError(t, corev1.AddToScheme(scheme))
-	assert.NilError(t, pipelinesv1alpha1.AddToScheme(scheme))
-	instance := &pipelinesv1alpha1.GitopsService{
+	assert.NilError(t, policyv1.AddToScheme(scheme))
+	assert.NilError(t, pipelinesv1beta1.AddToScheme(scheme))
+	instance := &pipelinesv1beta1.GitopsService{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      gitopsService,
 			Namespace: serviceNamespace,
@@ -2023,7 +2118,7 @@ func TestReconcileDeployment_AddsHashAnnotation(t *testing.T) {
 			"httpd.conf": "config-v1",
 		},
 	}
-	r := &ReconcileGitopsService{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance).Build(), Scheme: scheme}
+	r := newReconcileGitOpsService(fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance).Build(), scheme)
 	_, err := r.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsService), cm)
 	assert.NilError(t, err)
 	deployment := &appsv1.Deployment{}
@@ -2039,8 +2134,9 @@ func TestReconcileDeployment_UpdatesHashAnnotationWhenConfigChanges(t *testing.T
 	serviceNamespace := "openshift-gitops"
 	assert.NilError(t, appsv1.AddToScheme(scheme))
 	assert.NilError(t, corev1.AddToScheme(scheme))
-	assert.NilError(t, pipelinesv1alpha1.AddToScheme(scheme))
-	instance := &pipelinesv1alpha1.GitopsService{
+	assert.NilError(t, policyv1.AddToScheme(scheme))
+	assert.NilError(t, pipelinesv1beta1.AddToScheme(scheme))
+	instance := &pipelinesv1beta1.GitopsService{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      gitopsService,
 			Namespace: serviceNamespace,
@@ -2056,7 +2152,7 @@ func TestReconcileDeployment_UpdatesHashAnnotationWhenConfigChanges(t *testing.T
 			"httpd.conf": "config-v2",
 		},
 	}
-	r := &ReconcileGitopsService{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance).Build(), Scheme: scheme}
+	r := newReconcileGitOpsService(fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance).Build(), scheme)
 	_, err := r.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsService), cm1)
 	assert.NilError(t, err)
 	_, err = r.reconcileDeployment(instance, newRequest(serviceNamespace, gitopsService), cm2)
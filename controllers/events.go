@@ -0,0 +1,132 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// eventReasonSkipped is the reason of the events recorded when a reconciler skips a component
+const eventReasonSkipped = "Skipped"
+
+// resourceAction is a change made by a reconciler to a resource it manages, reported as an event
+type resourceAction struct {
+	verb         string
+	reason       string
+	failedReason string
+}
+
+var (
+	resourceCreate = resourceAction{verb: "create", reason: "Created", failedReason: "CreateFailed"}
+	resourceUpdate = resourceAction{verb: "update", reason: "Updated", failedReason: "UpdateFailed"}
+	resourceDelete = resourceAction{verb: "delete", reason: "Deleted", failedReason: "DeleteFailed"}
+)
+
+// recordResourceEvent records the given change of resource as an event on obj: a Normal event if err is nil,
+// a Warning event otherwise. err is returned unchanged, so that the result of the client call can be passed through.
+func recordResourceEvent(recorder record.EventRecorder, obj runtime.Object, action resourceAction, resource client.Object, err error) error {
+	name := resource.GetName()
+	if resource.GetNamespace() != "" {
+		name = resource.GetNamespace() + "/" + name
+	}
+	kind := resourceKind(resource)
+	if err != nil {
+		recorder.Eventf(obj, corev1.EventTypeWarning, action.failedReason, "Failed to %s %s %s: %v", action.verb, kind, name, err)
+		return err
+	}
+	recorder.Eventf(obj, corev1.EventTypeNormal, action.reason, "%s %s %s", action.reason, kind, name)
+	return nil
+}
+
+// recordResourceDriftEvent records the update of resource reverting the changes made to the given fields as an event
+// on obj, like recordResourceEvent records the other changes
+func recordResourceDriftEvent(recorder record.EventRecorder, obj runtime.Object, resource client.Object, fields []string, err error) error {
+	if err != nil {
+		return recordResourceEvent(recorder, obj, resourceUpdate, resource, err)
+	}
+	name := resource.GetName()
+	if resource.GetNamespace() != "" {
+		name = resource.GetNamespace() + "/" + name
+	}
+	recorder.Eventf(obj, corev1.EventTypeNormal, resourceUpdate.reason, "%s %s %s: reverted changes to %s",
+		resourceUpdate.reason, resourceKind(resource), name, strings.Join(fields, ", "))
+	return nil
+}
+
+// resourceKind returns the kind of the given resource, typed objects returned by the client have no TypeMeta
+func resourceKind(resource client.Object) string {
+	if kind := resource.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.Indirect(reflect.ValueOf(resource)).Type().Name()
+}
+
+// recordSkipEvent records a Normal event on obj reporting that a component is not reconciled
+func recordSkipEvent(recorder record.EventRecorder, obj runtime.Object, messageFmt string, args ...interface{}) {
+	recorder.Eventf(obj, corev1.EventTypeNormal, eventReasonSkipped, messageFmt, args...)
+}
+
+// skipEvents remembers the components a reconciler skips, so that the Skipped event is recorded when a component
+// starts being skipped, or for another reason, instead of on every reconcile. A nil skipEvents records every skip.
+type skipEvents struct {
+	mu sync.Mutex
+	// skipped maps the components of the objects to the message of their last Skipped event
+	skipped map[string]string
+}
+
+func newSkipEvents() *skipEvents {
+	return &skipEvents{skipped: map[string]string{}}
+}
+
+func skipEventKey(obj client.Object, component string) string {
+	return obj.GetNamespace() + "/" + obj.GetName() + "/" + component
+}
+
+// record records the Skipped event of the component on obj, unless the component was already skipped with the same
+// message since it was last reconciled
+func (s *skipEvents) record(recorder record.EventRecorder, obj client.Object, component, messageFmt string, args ...interface{}) {
+	if s == nil {
+		recordSkipEvent(recorder, obj, messageFmt, args...)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, message := skipEventKey(obj, component), fmt.Sprintf(messageFmt, args...)
+	if s.skipped[key] == message {
+		return
+	}
+	s.skipped[key] = message
+	recordSkipEvent(recorder, obj, "%s", message)
+}
+
+// reconciled forgets that the component of obj was skipped, so that the next skip is recorded again
+func (s *skipEvents) reconciled(obj client.Object, component string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.skipped, skipEventKey(obj, component))
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"gotest.tools/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSkipEvents(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	argocd := &argoapp.ArgoCD{ObjectMeta: v1.ObjectMeta{Name: "argocd", Namespace: "argocd"}}
+	other := &argoapp.ArgoCD{ObjectMeta: v1.ObjectMeta{Name: "other", Namespace: "argocd"}}
+	tracker := newSkipEvents()
+
+	// a component skipped on every reconcile is reported once
+	tracker.record(recorder, argocd, "dashboards", "Dashboards are not installed: %s", "not found")
+	tracker.record(recorder, argocd, "dashboards", "Dashboards are not installed: %s", "not found")
+	tracker.record(recorder, other, "dashboards", "Dashboards are not installed: %s", "not found")
+	assert.DeepEqual(t, recordedEvents(recorder, "Skipped"), []string{
+		"Normal Skipped Dashboards are not installed: not found",
+		"Normal Skipped Dashboards are not installed: not found",
+	})
+
+	// and again when skipped for another reason, or after it was reconciled
+	tracker.record(recorder, argocd, "dashboards", "Dashboards are not installed: %s", "unsupported")
+	tracker.reconciled(other, "dashboards")
+	tracker.record(recorder, other, "dashboards", "Dashboards are not installed: %s", "not found")
+	assert.DeepEqual(t, recordedEvents(recorder, "Skipped"), []string{
+		"Normal Skipped Dashboards are not installed: unsupported",
+		"Normal Skipped Dashboards are not installed: not found",
+	})
+
+	// every skip is reported without tracking
+	var untracked *skipEvents
+	untracked.record(recorder, argocd, "dashboards", "Dashboards are not installed")
+	untracked.record(recorder, argocd, "dashboards", "Dashboards are not installed")
+	untracked.reconciled(argocd, "dashboards")
+	assert.Equal(t, len(recordedEvents(recorder, "Skipped")), 2)
+}
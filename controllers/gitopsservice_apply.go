@@ -0,0 +1,521 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	argocd "github.com/redhat-developer/gitops-operator/controllers/argocd"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
+)
+
+// argoCDFieldManager is the field manager used to server-side apply the Argo CD instances managed by the operator
+const argoCDFieldManager = "gitops-operator"
+
+// legacyFieldManagers are the field managers of the updates sent by the operator before it used server-side apply
+var legacyFieldManagers = sets.New("manager")
+
+// fieldConflict is a field of an Argo CD instance set by the operator but owned by another field manager
+type fieldConflict struct {
+	Field   string
+	Manager string
+}
+
+func (c fieldConflict) String() string {
+	return fmt.Sprintf("%s (%s)", c.Field, c.Manager)
+}
+
+// applyArgoCDInstance server-side applies the given Argo CD instance with the operator field manager.
+// Fields owned by another field manager are left unchanged, unless they are listed in forced, and are recorded
+// in argocd.ConflictingFieldsAnnotation. It returns the fields that were left unchanged. The existing instance,
+// if any, is used to upgrade the fields set by previous versions of the operator.
+func (r *ReconcileGitopsService) applyArgoCDInstance(ctx context.Context, argoCD, existing *argoapp.ArgoCD, forced []string) ([]fieldConflict, error) {
+	// The zero values of the fields configured in the GitopsService CR are set on purpose as well
+	obj, err := toApplyConfiguration(argoCD, slices.Concat(zeroValueArgoCDFields, forced))
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if err := r.upgradeArgoCDManagedFields(ctx, existing, obj); err != nil {
+			return nil, err
+		}
+	}
+	// Record the upgrade of the fields set by previous versions of the operator, which is never run again
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[argocd.ManagedFieldsUpgradedAnnotation] = "true"
+	obj.SetAnnotations(annotations)
+
+	err = r.Client.Apply(ctx, client.ApplyConfigurationFromUnstructured(obj), client.FieldOwner(argoCDFieldManager))
+	if err == nil || !errors.IsConflict(err) {
+		return nil, err
+	}
+
+	conflicts := fieldConflictsFromError(err)
+	if len(conflicts) == 0 {
+		return nil, err
+	}
+	var kept []fieldConflict
+	for _, conflict := range conflicts {
+		if isForcedField(conflict.Field, forced) {
+			continue
+		}
+		if !removeField(obj.Object, conflict.Field) {
+			return nil, err
+		}
+		kept = append(kept, conflict)
+	}
+
+	if len(kept) > 0 {
+		values := make([]string, 0, len(kept))
+		for _, conflict := range kept {
+			values = append(values, conflict.String())
+		}
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[argocd.ConflictingFieldsAnnotation] = strings.Join(values, ", ")
+		obj.SetAnnotations(annotations)
+	}
+
+	// The remaining conflicts are on fields the operator must own, take them over
+	err = r.Client.Apply(ctx, client.ApplyConfigurationFromUnstructured(obj), client.FieldOwner(argoCDFieldManager), client.ForceOwnership)
+	if err != nil {
+		return nil, err
+	}
+	return kept, nil
+}
+
+// upgradeArgoCDManagedFields transfers the ownership of the fields set by previous versions of the operator
+// to the server-side apply field manager, so that they are not reported as conflicts. Only the fields of the
+// apply configuration are transferred: the other fields of the legacy field manager, like the finalizers set by
+// the argocd-operator, are not removed by the next apply. The upgrade runs once, the apply configuration records
+// it in argocd.ManagedFieldsUpgradedAnnotation.
+func (r *ReconcileGitopsService) upgradeArgoCDManagedFields(ctx context.Context, existing *argoapp.ArgoCD, obj *unstructured.Unstructured) error {
+	if _, ok := existing.Annotations[argocd.ManagedFieldsUpgradedAnnotation]; ok {
+		return nil
+	}
+
+	var entries []metav1.ManagedFieldsEntry
+	applyIndex := -1
+	moved := &fieldpath.Set{}
+	for _, entry := range existing.ManagedFields {
+		if entry.Manager == argoCDFieldManager && entry.Operation == metav1.ManagedFieldsOperationApply && entry.Subresource == "" {
+			applyIndex = len(entries)
+		}
+		if !legacyFieldManagers.Has(entry.Manager) || entry.Operation != metav1.ManagedFieldsOperationUpdate ||
+			entry.Subresource != "" || entry.FieldsV1 == nil {
+			entries = append(entries, entry)
+			continue
+		}
+
+		fields := &fieldpath.Set{}
+		if err := fields.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			return fmt.Errorf("failed to decode the managed fields of %s: %w", entry.Manager, err)
+		}
+		applied := &fieldpath.Set{}
+		fields.Iterate(func(path fieldpath.Path) {
+			if hasFieldPath(obj.Object, path) {
+				applied.Insert(path)
+			}
+		})
+		if applied.Empty() {
+			entries = append(entries, entry)
+			continue
+		}
+		moved = moved.Union(applied)
+		if remaining := fields.Difference(applied); !remaining.Empty() {
+			raw, err := remaining.ToJSON()
+			if err != nil {
+				return err
+			}
+			entry.FieldsV1 = &metav1.FieldsV1{Raw: raw}
+			entries = append(entries, entry)
+		}
+	}
+	if moved.Empty() {
+		return nil
+	}
+
+	if applyIndex < 0 {
+		applyIndex = len(entries)
+		entries = append(entries, metav1.ManagedFieldsEntry{
+			Manager:    argoCDFieldManager,
+			Operation:  metav1.ManagedFieldsOperationApply,
+			APIVersion: argoapp.GroupVersion.String(),
+			Time:       ptr.To(metav1.Now()),
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte("{}")},
+		})
+	}
+	fields := &fieldpath.Set{}
+	if err := fields.FromJSON(bytes.NewReader(entries[applyIndex].FieldsV1.Raw)); err != nil {
+		return fmt.Errorf("failed to decode the managed fields of %s: %w", argoCDFieldManager, err)
+	}
+	raw, err := fields.Union(moved).ToJSON()
+	if err != nil {
+		return err
+	}
+	entries[applyIndex].FieldsV1 = &metav1.FieldsV1{Raw: raw}
+
+	// The resource version makes the patch fail if the instance changed in the meantime
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/metadata/managedFields", "value": entries},
+		{"op": "replace", "path": "/metadata/resourceVersion", "value": existing.ResourceVersion},
+	})
+	if err != nil {
+		return err
+	}
+	return r.Client.Patch(ctx, existing, client.RawPatch(types.JSONPatchType, patch))
+}
+
+// hasFieldPath returns true if the given path of managed fields is set in obj. Members of lists are considered
+// set with the whole list.
+func hasFieldPath(obj map[string]interface{}, path fieldpath.Path) bool {
+	var value interface{} = obj
+	for _, element := range path {
+		if element.FieldName == nil {
+			_, ok := value.([]interface{})
+			return ok
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if value, ok = fields[*element.FieldName]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// forcedArgoCDFields returns the fields of an Argo CD instance that the operator takes over from other field
+// managers: the resource exclusions, which already keep the entries added by users, and the fields explicitly
+// configured in spec, which can be nil.
+func forcedArgoCDFields(spec *pipelinesv1beta1.DefaultArgoCDSpec) ([]string, error) {
+	fields := []string{
+		".spec.resourceExclusions",
+		".metadata.annotations." + argocd.ManagedResourceExclusionsAnnotation,
+	}
+	if spec == nil {
+		return fields, nil
+	}
+	if len(spec.NodeSelector) > 0 || len(spec.Tolerations) > 0 {
+		fields = append(fields, ".spec.nodePlacement")
+	}
+	if spec.Overrides != nil && len(spec.Overrides.Raw) > 0 {
+		var overrides map[string]interface{}
+		if err := json.Unmarshal(spec.Overrides.Raw, &overrides); err != nil {
+			return nil, fmt.Errorf("unable to apply overrides: %w", err)
+		}
+		fields = append(fields, leafFields(".spec", overrides)...)
+	}
+	return fields, nil
+}
+
+// leafFields returns the paths of the values of the given patch that are not maps, ignoring the
+// strategic merge patch directives
+func leafFields(prefix string, patch map[string]interface{}) []string {
+	var fields []string
+	for key, value := range patch {
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+		field := prefix + "." + key
+		if child, ok := value.(map[string]interface{}); ok && len(child) > 0 {
+			fields = append(fields, leafFields(field, child)...)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func isForcedField(field string, forced []string) bool {
+	for _, f := range forced {
+		if field == f || strings.HasPrefix(field, f+".") || strings.HasPrefix(field, f+"[") || strings.HasPrefix(f, field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldConflictsFromError returns the field conflicts reported by a server-side apply request
+func fieldConflictsFromError(err error) []fieldConflict {
+	status, ok := err.(errors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return nil
+	}
+	var conflicts []fieldConflict
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		// The message has the form: conflict with "<manager>" [using <apiVersion>]
+		manager := cause.Message
+		if parts := strings.Split(cause.Message, `"`); len(parts) >= 3 {
+			manager = parts[1]
+		}
+		conflicts = append(conflicts, fieldConflict{Field: cause.Field, Manager: manager})
+	}
+	return conflicts
+}
+
+// removeField removes the field with the given path, as reported by a server-side apply conflict, from obj. Keys of
+// maps can contain dots, so the longest key matching the path is looked up at each level. Elements of lists are
+// selected by their keys, value or index, so that only the conflicting element is removed from the list.
+func removeField(obj map[string]interface{}, path string) bool {
+	parts, ok := parseFieldPath(path)
+	if !ok {
+		return false
+	}
+	return removeFieldParts(obj, parts)
+}
+
+// fieldPathPart is a dot separated part of a field name, or the selector of a list element, without brackets
+type fieldPathPart struct {
+	name     string
+	selector string
+	isList   bool
+}
+
+// parseFieldPath splits a path of the form .spec.field[key="value"].field into its parts
+func parseFieldPath(path string) ([]fieldPathPart, bool) {
+	var parts []fieldPathPart
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			j := i + 1
+			for j < len(path) && path[j] != '.' && path[j] != '[' {
+				j++
+			}
+			parts = append(parts, fieldPathPart{name: path[i+1 : j]})
+			i = j
+		case '[':
+			// Values of keys are quoted strings, which can contain brackets
+			j, quoted := i+1, false
+			for ; j < len(path) && (quoted || path[j] != ']'); j++ {
+				switch {
+				case quoted && path[j] == '\\':
+					j++
+				case path[j] == '"':
+					quoted = !quoted
+				}
+			}
+			if j >= len(path) {
+				return nil, false
+			}
+			parts = append(parts, fieldPathPart{selector: path[i+1 : j], isList: true})
+			i = j + 1
+		default:
+			return nil, false
+		}
+	}
+	return parts, len(parts) > 0 && !parts[0].isList
+}
+
+func removeFieldParts(obj map[string]interface{}, parts []fieldPathPart) bool {
+	names := 0
+	for names < len(parts) && !parts[names].isList {
+		names++
+	}
+	for i := names; i > 0; i-- {
+		keyParts := make([]string, i)
+		for j := range keyParts {
+			keyParts[j] = parts[j].name
+		}
+		key := strings.Join(keyParts, ".")
+		value, ok := obj[key]
+		if !ok {
+			continue
+		}
+		if i == len(parts) {
+			delete(obj, key)
+			return true
+		}
+		switch child := value.(type) {
+		case map[string]interface{}:
+			if !parts[i].isList && removeFieldParts(child, parts[i:]) {
+				return true
+			}
+		case []interface{}:
+			if list, ok := removeListElement(child, parts[i:]); ok {
+				// The operator keeps no element of the list, it does not apply it anymore
+				if len(list) == 0 {
+					delete(obj, key)
+				} else {
+					obj[key] = list
+				}
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeListElement removes the element of list selected by the first part, or the field of the element selected
+// by the next parts, and returns the updated list
+func removeListElement(list []interface{}, parts []fieldPathPart) ([]interface{}, bool) {
+	if !parts[0].isList {
+		return nil, false
+	}
+	index := -1
+	for i, element := range list {
+		if matchesSelector(element, i, parts[0].selector) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, false
+	}
+	if len(parts) == 1 {
+		return append(list[:index:index], list[index+1:]...), true
+	}
+	element, ok := list[index].(map[string]interface{})
+	if !ok || !removeFieldParts(element, parts[1:]) {
+		return nil, false
+	}
+	return list, true
+}
+
+// matchesSelector returns true if the element at the given index of a list is selected by a selector of a managed
+// fields path: [name="value",port=80] for lists of maps with keys, [="value"] for sets, and [0] otherwise
+func matchesSelector(element interface{}, index int, selector string) bool {
+	if value, ok := strings.CutPrefix(selector, "="); ok {
+		return selectorValue(element) == value
+	}
+	if i, err := strconv.Atoi(selector); err == nil {
+		return i == index
+	}
+	fields, ok := element.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, key := range splitSelectorKeys(selector) {
+		name, value, ok := strings.Cut(key, "=")
+		if !ok {
+			return false
+		}
+		field, ok := fields[name]
+		if !ok || selectorValue(field) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// splitSelectorKeys splits the keys of a selector on the commas that are not quoted
+func splitSelectorKeys(selector string) []string {
+	var keys []string
+	start, quoted := 0, false
+	for i := 0; i < len(selector); i++ {
+		switch {
+		case quoted && selector[i] == '\\':
+			i++
+		case selector[i] == '"':
+			quoted = !quoted
+		case selector[i] == ',' && !quoted:
+			keys = append(keys, selector[start:i])
+			start = i + 1
+		}
+	}
+	return append(keys, selector[start:])
+}
+
+// selectorValue formats a scalar value like the selectors of managed fields paths
+func selectorValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// zeroValueArgoCDFields are the fields of the Argo CD instances that the operator sets even to their zero value,
+// so that it owns and reconciles the features it disables
+var zeroValueArgoCDFields = []string{
+	".spec.ha.enabled",
+	".spec.controller.sharding.enabled",
+	".spec.server.route.enabled",
+}
+
+// toApplyConfiguration converts the given Argo CD instance to an apply configuration. Nil and empty values are
+// dropped, so that the operator does not own fields it leaves to their default. Explicit false and zero values are
+// only kept for the given fields, the ones the operator sets.
+func toApplyConfiguration(argoCD *argoapp.ArgoCD, zeroValueFields []string) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(argoCD)
+	if err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	pruneEmptyValues(content, "", zeroValueFields)
+
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetGroupVersionKind(argoapp.GroupVersion.WithKind("ArgoCD"))
+	return obj, nil
+}
+
+func pruneEmptyValues(obj map[string]interface{}, prefix string, zeroValueFields []string) {
+	for key, value := range obj {
+		field := prefix + "." + key
+		switch v := value.(type) {
+		case nil:
+			delete(obj, key)
+		case string:
+			if v == "" {
+				delete(obj, key)
+			}
+		case bool, int64, float64:
+			if reflect.ValueOf(v).IsZero() && !isForcedField(field, zeroValueFields) {
+				delete(obj, key)
+			}
+		case []interface{}:
+			if len(v) == 0 {
+				delete(obj, key)
+			}
+		case map[string]interface{}:
+			pruneEmptyValues(v, field, zeroValueFields)
+			if len(v) == 0 {
+				delete(obj, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sort"
+	"testing"
+
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	"gotest.tools/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRemoveField(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				"pipelines.openshift.io/managed-fields-upgraded": "true",
+			},
+		},
+		"spec": map[string]interface{}{
+			"repo": map[string]interface{}{"replicas": int64(3)},
+			"nodePlacement": map[string]interface{}{
+				"tolerations": []interface{}{
+					map[string]interface{}{"key": "infra", "effect": "NoSchedule"},
+					map[string]interface{}{"key": "gitops", "effect": "NoSchedule"},
+				},
+			},
+			"sourceNamespaces": []interface{}{"team-a", "team-b"},
+			"server": map[string]interface{}{
+				"service": map[string]interface{}{
+					"ports": []interface{}{map[string]interface{}{"port": int64(8080), "protocol": "TCP"}},
+				},
+			},
+		},
+	}
+	spec := obj["spec"].(map[string]interface{})
+
+	assert.Assert(t, removeField(obj, ".spec.repo.replicas"))
+	assert.DeepEqual(t, spec["repo"], map[string]interface{}{})
+
+	// Keys containing dots are matched as a whole
+	assert.Assert(t, removeField(obj, ".metadata.annotations.pipelines.openshift.io/managed-fields-upgraded"))
+	assert.DeepEqual(t, obj["metadata"].(map[string]interface{})["annotations"], map[string]interface{}{})
+
+	// Only the selected element of a list, or its field, is removed
+	assert.Assert(t, removeField(obj, `.spec.nodePlacement.tolerations[key="infra"].effect`))
+	assert.Assert(t, removeField(obj, `.spec.nodePlacement.tolerations[key="gitops"]`))
+	assert.DeepEqual(t, spec["nodePlacement"], map[string]interface{}{
+		"tolerations": []interface{}{map[string]interface{}{"key": "infra"}},
+	})
+	assert.Assert(t, removeField(obj, `.spec.sourceNamespaces[="team-b"]`))
+	assert.DeepEqual(t, spec["sourceNamespaces"], []interface{}{"team-a"})
+
+	// A list without elements left is not applied
+	assert.Assert(t, removeField(obj, `.spec.server.service.ports[port=8080,protocol="TCP"]`))
+	assert.DeepEqual(t, spec["server"], map[string]interface{}{"service": map[string]interface{}{}})
+
+	assert.Assert(t, !removeField(obj, ".spec.server.replicas"))
+	assert.Assert(t, !removeField(obj, `.spec.sourceNamespaces[="team-c"]`))
+	assert.Assert(t, !removeField(obj, `.spec.nodePlacement.tolerations[key="infra"`))
+}
+
+func TestForcedArgoCDFields(t *testing.T) {
+	instance := newGitopsService()
+	instance.Spec.DefaultArgoCD = &pipelinesv1beta1.DefaultArgoCDSpec{
+		NodeSelector: map[string]string{"key": "value"},
+		Overrides: &runtime.RawExtension{
+			Raw: []byte(`{"server":{"replicas":2,"route":{"enabled":true}},"sourceNamespaces":["team-a"],"$patch":"merge"}`),
+		},
+	}
+
+	fields, err := forcedArgoCDFields(instance.Spec.DefaultArgoCD)
+	assert.NilError(t, err)
+	sort.Strings(fields)
+	assert.DeepEqual(t, fields, []string{
+		".metadata.annotations.pipelines.openshift.io/managed-resource-exclusions",
+		".spec.nodePlacement",
+		".spec.resourceExclusions",
+		".spec.server.replicas",
+		".spec.server.route.enabled",
+		".spec.sourceNamespaces",
+	})
+
+	assert.Assert(t, isForcedField(".spec.server.replicas", fields))
+	assert.Assert(t, isForcedField(".spec.nodePlacement.nodeSelector.key", fields))
+	assert.Assert(t, isForcedField(`.spec.sourceNamespaces[team-a]`, fields))
+	assert.Assert(t, !isForcedField(".spec.server.resources", fields))
+}
+
+func TestPruneEmptyValues(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "openshift-gitops", "creationTimestamp": nil},
+		"spec": map[string]interface{}{
+			"ha":         map[string]interface{}{"enabled": false},
+			"server":     map[string]interface{}{"route": map[string]interface{}{"enabled": true}, "insecure": false},
+			"repo":       map[string]interface{}{"replicas": int64(0)},
+			"controller": map[string]interface{}{"sharding": map[string]interface{}{"enabled": false, "replicas": int64(0)}},
+			"rbac":       map[string]interface{}{"scopes": ""},
+			"kustomize":  []interface{}{},
+		},
+	}
+	// Only the zero values of the given fields are kept
+	pruneEmptyValues(obj, "", []string{".spec.ha.enabled", ".spec.repo"})
+	assert.DeepEqual(t, obj, map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "openshift-gitops"},
+		"spec": map[string]interface{}{
+			"ha":     map[string]interface{}{"enabled": false},
+			"server": map[string]interface{}{"route": map[string]interface{}{"enabled": true}},
+			"repo":   map[string]interface{}{"replicas": int64(0)},
+		},
+	})
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// hostnameTopologyKey is the topology key used to spread the replicas of a workload across nodes
+const hostnameTopologyKey = "kubernetes.io/hostname"
+
+// setWorkloadAvailability sets the number of replicas and the scheduling constraints of the given deployment.
+// When neither affinity nor topology spread constraints are given and the deployment has more than one replica,
+// its pods are preferably scheduled on different nodes.
+func setWorkloadAvailability(deployment *appsv1.Deployment, replicas *int32, affinity *corev1.Affinity, constraints []corev1.TopologySpreadConstraint) {
+	if replicas != nil {
+		deployment.Spec.Replicas = replicas
+	}
+	podSpec := &deployment.Spec.Template.Spec
+	podSpec.Affinity = affinity
+	podSpec.TopologySpreadConstraints = constraints
+	if affinity != nil || len(constraints) > 0 || *deployment.Spec.Replicas < 2 {
+		return
+	}
+	podSpec.Affinity = &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: deployment.Spec.Selector.DeepCopy(),
+						TopologyKey:   hostnameTopologyKey,
+					},
+				},
+			},
+		},
+	}
+}
+
+// newPodDisruptionBudget returns the PodDisruptionBudget of the given deployment, which allows one of its pods
+// to be unavailable at a time
+func newPodDisruptionBudget(deployment *appsv1.Deployment) *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt32(1)
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployment.Name,
+			Namespace: deployment.Namespace,
+			Labels:    deployment.Labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector:       deployment.Spec.Selector.DeepCopy(),
+		},
+	}
+}
+
+// reconcilePodDisruptionBudget creates or updates the given PodDisruptionBudget, owned by the GitopsService
+func (r *ReconcileGitopsService) reconcilePodDisruptionBudget(instance *pipelinesv1beta1.GitopsService, pdb *policyv1.PodDisruptionBudget,
+	reqLogger logr.Logger) error {
+	if err := controllerutil.SetControllerReference(instance, pdb, r.Scheme); err != nil {
+		return err
+	}
+
+	existing := &policyv1.PodDisruptionBudget{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: pdb.Name, Namespace: pdb.Namespace}, existing)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		reqLogger.Info("Creating a new PodDisruptionBudget", "Namespace", pdb.Namespace, "Name", pdb.Name)
+		return recordResourceEvent(r.Recorder, instance, resourceCreate, pdb, r.Client.Create(context.TODO(), pdb))
+	}
+
+	changed := !equality.Semantic.DeepEqual(existing.Labels, pdb.Labels) ||
+		!equality.Semantic.DeepEqual(existing.Spec.MaxUnavailable, pdb.Spec.MaxUnavailable) ||
+		!equality.Semantic.DeepEqual(existing.Spec.MinAvailable, pdb.Spec.MinAvailable) ||
+		!equality.Semantic.DeepEqual(existing.Spec.Selector, pdb.Spec.Selector)
+	if !changed {
+		return nil
+	}
+	reqLogger.Info("Reconciling existing PodDisruptionBudget", "Namespace", existing.Namespace, "Name", existing.Name)
+	existing.Labels = pdb.Labels
+	existing.Spec.MaxUnavailable = pdb.Spec.MaxUnavailable
+	existing.Spec.MinAvailable = pdb.Spec.MinAvailable
+	existing.Spec.Selector = pdb.Spec.Selector
+	return recordResourceEvent(r.Recorder, instance, resourceUpdate, existing, r.Client.Update(context.TODO(), existing))
+}
@@ -17,12 +17,16 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	argocommon "github.com/argoproj-labs/argocd-operator/common"
@@ -31,29 +35,41 @@ import (
 	"github.com/go-logr/logr"
 	version "github.com/hashicorp/go-version"
 	routev1 "github.com/openshift/api/route/v1"
-	pipelinesv1alpha1 "github.com/redhat-developer/gitops-operator/api/v1alpha1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	"github.com/redhat-developer/gitops-operator/common"
 	argocd "github.com/redhat-developer/gitops-operator/controllers/argocd"
 	"github.com/redhat-developer/gitops-operator/controllers/util"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	resourcev1 "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	configv1 "github.com/openshift/api/config/v1"
 )
@@ -85,55 +101,140 @@ const (
 func (r *ReconcileGitopsService) SetupWithManager(mgr ctrl.Manager) error {
 	reqLogger := logs.WithValues()
 	reqLogger.Info("Watching GitopsService")
+	r.skipEvents = newSkipEvents()
 
-	pred := predicate.Funcs{
+	pred := ownedResourcePredicate()
+
+	// Deployment availability is reported in the GitopsService status, so status updates that change
+	// the number of available replicas must trigger a reconcile as well
+	deploymentPred := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			// Ignore updates to CR status in which case metadata.Generation does not change
+			oldDeployment, oldOK := e.ObjectOld.(*appsv1.Deployment)
+			newDeployment, newOK := e.ObjectNew.(*appsv1.Deployment)
+			if oldOK && newOK && oldDeployment.Status.AvailableReplicas != newDeployment.Status.AvailableReplicas {
+				return true
+			}
 			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
 		},
-		DeleteFunc: func(e event.DeleteEvent) bool {
-			// Evaluates to false if the object has been confirmed deleted.
-			return !e.DeleteStateUnknown
-		},
+		DeleteFunc: pred.DeleteFunc,
 	}
 
-	// check if the gitops service instance already exists, do nothing if exists, create if it doesn't
-	gitopsServiceRef := newGitopsService()
-	err := r.Client.Create(context.TODO(), gitopsServiceRef)
-	if err != nil {
-		if errors.IsAlreadyExists(err) {
-			reqLogger.Info("GitOps service instance already exists, skipping creation")
-		} else {
-			reqLogger.Error(err, "Failed to create GitOps service instance")
-		}
+	// The GitopsService instance is created once the manager started, its creation is validated by the webhook
+	// server of the operator
+	if err := mgr.Add(manager.RunnableFunc(r.bootstrapGitopsService)); err != nil {
+		return err
 	}
 
 	bldr := ctrl.NewControllerManagedBy(mgr).
-		For(&pipelinesv1alpha1.GitopsService{}, builder.WithPredicates(pred)).
+		For(&pipelinesv1beta1.GitopsService{}, builder.WithPredicates(pred)).
 		Owns(&rbacv1.ClusterRoleBinding{}).
 		Owns(&rbacv1.ClusterRole{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&rbacv1.Role{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&corev1.ConfigMap{}).
-		Owns(&appsv1.Deployment{}, builder.WithPredicates(pred)).
-		Owns(&corev1.Service{}, builder.WithPredicates(pred))
-
-	if util.IsRouteAPIFound() {
-		bldr = bldr.Owns(&routev1.Route{}, builder.WithPredicates(pred))
+		Owns(&appsv1.Deployment{}, builder.WithPredicates(deploymentPred)).
+		Owns(&policyv1.PodDisruptionBudget{}, builder.WithPredicates(pred)).
+		Owns(&corev1.Service{}, builder.WithPredicates(pred)).
+		Owns(&networkingv1.NetworkPolicy{}, builder.WithPredicates(pred))
+
+	// In the namespaced RBAC scope of the backend, the namespaces that are created, deleted or change of
+	// managing Argo CD instance change the namespaces the backend is granted access to
+	namespacePred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return e.Object.GetName() == "openshift-gitops" || r.isBackendRBACNamespaced()
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectNew.GetName() == "openshift-gitops" {
+				return true
+			}
+			managedBy := argocommon.ArgoCDKeyManagedBy
+			return e.ObjectOld.GetLabels()[managedBy] != e.ObjectNew.GetLabels()[managedBy] && r.isBackendRBACNamespaced()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return e.Object.GetName() == "openshift-gitops" || r.isBackendRBACNamespaced()
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return e.Object.GetName() == "openshift-gitops"
+		},
 	}
 
-	return bldr.
+	c, err := bldr.
 		Watches(
 			&corev1.Namespace{},
 			&handler.EnqueueRequestForObject{},
-			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
-				return obj.GetName() == "openshift-gitops"
-			})),
+			builder.WithPredicates(namespacePred),
 		).Watches(&argoapp.ArgoCD{},
 		&handler.EnqueueRequestForObject{},
 		builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
-			return obj.GetName() == "openshift-gitops" && obj.GetNamespace() == "openshift-gitops"
+			if obj.GetName() == "openshift-gitops" && obj.GetNamespace() == "openshift-gitops" {
+				return true
+			}
+			// Instances listed in spec.instances of the GitopsService
+			owner := metav1.GetControllerOf(obj)
+			if owner != nil && owner.Kind == "GitopsService" {
+				return true
+			}
+			// Any instance in the namespaced RBAC scope of the backend, whose namespaces depend on the instances
+			return r.isBackendRBACNamespaced()
 		}))).
-		Complete(r)
+		Build(r)
+	r.controller = c
+	return err
+}
+
+// bootstrapRetryInterval is the interval between the attempts to create the GitopsService instance
+var bootstrapRetryInterval = 5 * time.Second
+
+// bootstrapGitopsService creates the GitopsService instance of the cluster if it does not exist. It waits for the
+// webhook server, and retries until the instance is created, since the creation is rejected while the webhook
+// validating it cannot be reached.
+func (r *ReconcileGitopsService) bootstrapGitopsService(ctx context.Context) error {
+	reqLogger := logs.WithValues()
+	return wait.PollUntilContextCancel(ctx, bootstrapRetryInterval, true, func(ctx context.Context) (bool, error) {
+		if r.WebhookReady != nil {
+			if err := r.WebhookReady(nil); err != nil {
+				reqLogger.Info("Waiting for the webhook server to create the GitOps service instance", "reason", err.Error())
+				return false, nil
+			}
+		}
+		err := r.Client.Create(ctx, newGitopsService())
+		switch {
+		case err == nil:
+			reqLogger.Info("Created GitOps service instance")
+		case errors.IsAlreadyExists(err):
+			reqLogger.Info("GitOps service instance already exists, skipping creation")
+		default:
+			reqLogger.Error(err, "Failed to create GitOps service instance, retrying")
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+// ownedResourcePredicate selects the events of the resources owned by the GitopsService
+func ownedResourcePredicate() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// Ignore updates to CR status in which case metadata.Generation does not change
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			// Evaluates to false if the object has been confirmed deleted.
+			return !e.DeleteStateUnknown
+		},
+	}
+}
+
+// WatchRoutes watches the Routes owned by the GitopsService. It is called once the Route API is available, which
+// can be after the controller started.
+func (r *ReconcileGitopsService) WatchRoutes(mgr ctrl.Manager) error {
+	if r.controller == nil {
+		return fmt.Errorf("the GitopsService controller is not set up")
+	}
+	return r.controller.Watch(source.Kind[client.Object](mgr.GetCache(), &routev1.Route{},
+		handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &pipelinesv1beta1.GitopsService{}, handler.OnlyControllerOwner()),
+		ownedResourcePredicate()))
 }
 
 // blank assignment to verify that ReconcileGitopsService implements reconcile.Reconciler
@@ -147,9 +248,20 @@ type ReconcileGitopsService struct {
 	Scheme *runtime.Scheme
 
 	// disableDefaultInstall, if true, will ensure that the default ArgoCD instance is not instantiated in the openshift-gitops namespace.
+	// It only applies when spec.defaultArgoCD.managementState of the GitopsService is not set.
 	DisableDefaultInstall bool
+	// Recorder records the events reported on the GitopsService
+	Recorder record.EventRecorder
 	//CentralTLSProfile contains MinVersion and CipherSuites
 	CentralTLSProfile configv1.TLSProfileSpec
+
+	// WebhookReady, if set, reports whether the webhook server validating the GitopsService is serving
+	WebhookReady healthz.Checker
+
+	// controller watches the Routes once the Route API is available
+	controller controller.Controller
+	// skipEvents reports the components that are skipped once, not on every reconcile
+	skipEvents *skipEvents
 }
 
 // +kubebuilder:rbac:groups=config.openshift.io,resources=authentications,verbs=get;list;watch
@@ -169,6 +281,7 @@ type ReconcileGitopsService struct {
 
 //+kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;replicasets;statefulsets,verbs=create;delete;get;list;patch;update;watch
 //+kubebuilder:rbac:groups=apps,resourceNames=gitops-operator,resources=deployments/finalizers,verbs=update
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=create;delete;get;list;patch;update;watch
 
 //+kubebuilder:rbac:groups=apps.openshift.io,resources=*,verbs=get;list;watch;create;delete;patch;update
 
@@ -234,7 +347,7 @@ func (r *ReconcileGitopsService) Reconcile(ctx context.Context, request reconcil
 	reqLogger.Info("Reconciling GitopsService")
 
 	// Fetch the GitopsService instance
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 	err := r.Client.Get(ctx, types.NamespacedName{Name: serviceName}, instance)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -247,6 +360,20 @@ func (r *ReconcileGitopsService) Reconcile(ctx context.Context, request reconcil
 		return reconcile.Result{}, err
 	}
 
+	result, reconcileErr := r.reconcileGitopsService(ctx, instance, request, reqLogger)
+	if err := r.reconcileStatus(ctx, instance, reconcileErr); err != nil {
+		reqLogger.Error(err, "Failed to update GitopsService status")
+		if reconcileErr == nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return result, reconcileErr
+}
+
+// reconcileGitopsService creates or updates every component managed by the GitopsService instance
+func (r *ReconcileGitopsService) reconcileGitopsService(ctx context.Context, instance *pipelinesv1beta1.GitopsService,
+	request reconcile.Request, reqLogger logr.Logger) (reconcile.Result, error) {
+
 	namespace, err := GetBackendNamespace(r.Client)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -259,7 +386,7 @@ func (r *ReconcileGitopsService) Reconcile(ctx context.Context, request reconcil
 		if errors.IsNotFound(err) {
 			reqLogger.Info("Creating a new Namespace", "Name", namespace)
 			ensureInfraNodeSelectorAnnotation(namespaceRef, instance.Spec.RunOnInfra)
-			err = r.Client.Create(ctx, namespaceRef)
+			err = recordResourceEvent(r.Recorder, instance, resourceCreate, namespaceRef, r.Client.Create(ctx, namespaceRef))
 			if err != nil {
 				return reconcile.Result{}, err
 			}
@@ -268,7 +395,7 @@ func (r *ReconcileGitopsService) Reconcile(ctx context.Context, request reconcil
 		}
 	} else {
 		if ensureNamespaceMetadata(namespaceRef, instance.Spec.RunOnInfra) {
-			err = r.Client.Update(context.TODO(), namespaceRef)
+			err = recordResourceEvent(r.Recorder, instance, resourceUpdate, namespaceRef, r.Client.Update(context.TODO(), namespaceRef))
 			if err != nil {
 				return reconcile.Result{}, err
 			}
@@ -280,25 +407,47 @@ func (r *ReconcileGitopsService) Reconcile(ctx context.Context, request reconcil
 		Namespace: namespace,
 	}
 
-	r.cleanKAMResources(ctx, reqLogger)
+	r.cleanKAMResources(ctx, instance, reqLogger)
 
-	if !r.DisableDefaultInstall {
-		// Create/reconcile the default Argo CD instance, unless default install is disabled
-		if result, err := r.reconcileDefaultArgoCDInstance(instance, reqLogger); err != nil {
-			return result, fmt.Errorf("unable to reconcile default Argo CD instance: %v", err)
+	switch r.defaultArgoCDManagementState(instance) {
+	case pipelinesv1beta1.DefaultArgoCDUnmanaged:
+		// Leave the default Argo CD instance running, but stop reconciling it
+		if err := r.releaseArgoCDInstance(ctx, instance, types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, reqLogger); err != nil {
+			return reconcile.Result{}, fmt.Errorf("unable to release default Argo CD instance: %v", err)
 		}
-	} else {
+	case pipelinesv1beta1.DefaultArgoCDRemoved:
 		// If installation of default Argo CD instance is disabled, make sure it doesn't exist,
 		// deleting it if necessary
-		if err := r.ensureDefaultArgoCDInstanceDoesntExist(); err != nil {
+		if err := r.ensureDefaultArgoCDInstanceDoesntExist(instance); err != nil {
 			return reconcile.Result{}, fmt.Errorf("unable to ensure non-existence of default Argo CD instance: %v", err)
 		}
+	default:
+		// Create/reconcile the default Argo CD instance
+		if result, err := r.reconcileDefaultArgoCDInstance(instance, reqLogger); err != nil {
+			return result, fmt.Errorf("unable to reconcile default Argo CD instance: %v", err)
+		}
+	}
+
+	if err := r.reconcileArgoCDInstances(ctx, instance, reqLogger); err != nil {
+		return reconcile.Result{}, err
 	}
 
 	if result, err := r.reconcileBackend(gitopsserviceNamespacedName, instance, reqLogger); err != nil {
 		return result, err
 	}
 
+	if !r.isDynamicPluginSupported() {
+		// Skip plugin reconciliation if real OCP version is less than dynamic plugin start OCP version
+		reqLogger.Info("Skip console plugin reconcile: dynamic plugins are not supported by the cluster version")
+		r.skipEvents.record(r.Recorder, instance, "console plugin",
+			"Console plugin is not reconciled: dynamic plugins are not supported by the cluster version")
+		return reconcile.Result{}, nil
+	}
+	return r.reconcilePlugin(instance, request)
+}
+
+// isDynamicPluginSupported returns true if the OCP version of the cluster supports the console dynamic plugin
+func (r *ReconcileGitopsService) isDynamicPluginSupported() bool {
 	dynamicPluginStartOCPVersion := os.Getenv(dynamicPluginStartOCPVersionEnv)
 	if dynamicPluginStartOCPVersion == "" {
 		dynamicPluginStartOCPVersion = common.DefaultDynamicPluginStartOCPVersion
@@ -307,13 +456,13 @@ func (r *ReconcileGitopsService) Reconcile(ctx context.Context, request reconcil
 	OCPVersion, err := util.GetClusterVersion(r.Client)
 	if err != nil {
 		log.Printf("Unable to get cluster version: %v", err)
-		return reconcile.Result{}, nil
+		return false
 	}
 
 	v1, err := version.NewVersion(OCPVersion)
 	if err != nil {
 		log.Printf("Unable to retrieve current OCP version: %v", err)
-		return reconcile.Result{}, nil
+		return false
 	}
 	realVersion := v1.Segments()
 	realMajorVersion := realVersion[0]
@@ -321,28 +470,25 @@ func (r *ReconcileGitopsService) Reconcile(ctx context.Context, request reconcil
 
 	v2, err := version.NewVersion(dynamicPluginStartOCPVersion)
 	if err != nil {
-		return reconcile.Result{}, nil
+		return false
 	}
 	startVersion := v2.Segments()
 	startMajorVersion := startVersion[0]
 	startMinorVersion := startVersion[1]
 
-	if realMajorVersion < startMajorVersion || (realMajorVersion == startMajorVersion && realMinorVersion < startMinorVersion) {
-		// Skip plugin reconciliation if real OCP version is less than dynamic plugin start OCP version
-		return reconcile.Result{}, nil
-	} else {
-		return r.reconcilePlugin(instance, request)
-	}
+	return realMajorVersion > startMajorVersion || (realMajorVersion == startMajorVersion && realMinorVersion >= startMinorVersion)
 }
 
 // Detect the unsupported KAM components across Deployments , Routes , Services and deletes them to perform cleanup as KAM is no longer supported since 1.15
-func (r *ReconcileGitopsService) cleanKAMResources(ctx context.Context, reqLogger logr.Logger) {
+func (r *ReconcileGitopsService) cleanKAMResources(ctx context.Context, instance *pipelinesv1beta1.GitopsService, reqLogger logr.Logger) {
 
 	// KAM Deployment
 	cleanupKAMDeployment := &appsv1.Deployment{}
 	if err := r.Client.Get(ctx, types.NamespacedName{Name: kamResourceName, Namespace: serviceNamespace}, cleanupKAMDeployment); err == nil {
 		reqLogger.Info("Detected unsupported KAM Deployment, deleting", "Name", kamResourceName, "Namespace", serviceNamespace)
-		if err := r.Client.Delete(ctx, cleanupKAMDeployment); err != nil && !errors.IsNotFound(err) {
+		if err := r.Client.Delete(ctx, cleanupKAMDeployment); errors.IsNotFound(err) {
+			reqLogger.Info("KAM Deployment already deleted", "Name", kamResourceName, "Namespace", serviceNamespace)
+		} else if err := recordResourceEvent(r.Recorder, instance, resourceDelete, cleanupKAMDeployment, err); err != nil {
 			reqLogger.Error(err, "Failed to delete KAM Deployment", "Name", kamResourceName, "Namespace", serviceNamespace)
 		}
 	} else if !errors.IsNotFound(err) {
@@ -353,7 +499,9 @@ func (r *ReconcileGitopsService) cleanKAMResources(ctx context.Context, reqLogge
 	cleanupKAMService := &corev1.Service{}
 	if err := r.Client.Get(ctx, types.NamespacedName{Name: kamResourceName, Namespace: serviceNamespace}, cleanupKAMService); err == nil {
 		reqLogger.Info("Detected unsupported KAM Service, deleting", "Name", kamResourceName, "Namespace", serviceNamespace)
-		if err := r.Client.Delete(ctx, cleanupKAMService); err != nil && !errors.IsNotFound(err) {
+		if err := r.Client.Delete(ctx, cleanupKAMService); errors.IsNotFound(err) {
+			reqLogger.Info("KAM Service already deleted", "Name", kamResourceName, "Namespace", serviceNamespace)
+		} else if err := recordResourceEvent(r.Recorder, instance, resourceDelete, cleanupKAMService, err); err != nil {
 			reqLogger.Error(err, "Failed to delete KAM Service", "Name", kamResourceName, "Namespace", serviceNamespace)
 		}
 	} else if !errors.IsNotFound(err) {
@@ -365,7 +513,9 @@ func (r *ReconcileGitopsService) cleanKAMResources(ctx context.Context, reqLogge
 		cleanupKAMRoute := &routev1.Route{}
 		if err := r.Client.Get(ctx, types.NamespacedName{Name: kamResourceName, Namespace: serviceNamespace}, cleanupKAMRoute); err == nil {
 			reqLogger.Info("Detected unsupported KAM Route, deleting", "Name", kamResourceName, "Namespace", serviceNamespace)
-			if err := r.Client.Delete(ctx, cleanupKAMRoute); err != nil && !errors.IsNotFound(err) {
+			if err := r.Client.Delete(ctx, cleanupKAMRoute); errors.IsNotFound(err) {
+				reqLogger.Info("KAM Route already deleted", "Name", kamResourceName, "Namespace", serviceNamespace)
+			} else if err := recordResourceEvent(r.Recorder, instance, resourceDelete, cleanupKAMRoute, err); err != nil {
 				reqLogger.Error(err, "Failed to delete KAM Route", "Name", kamResourceName, "Namespace", serviceNamespace)
 			}
 		} else if !errors.IsNotFound(err) {
@@ -375,14 +525,59 @@ func (r *ReconcileGitopsService) cleanKAMResources(ctx context.Context, reqLogge
 
 }
 
-func (r *ReconcileGitopsService) ensureDefaultArgoCDInstanceDoesntExist() error {
+// defaultArgoCDManagementState returns how the default Argo CD instance is managed. The management state set in the
+// GitopsService CR takes precedence over the DISABLE_DEFAULT_ARGOCD_INSTANCE environment variable.
+func (r *ReconcileGitopsService) defaultArgoCDManagementState(instance *pipelinesv1beta1.GitopsService) pipelinesv1beta1.DefaultArgoCDManagementState {
+	if instance.Spec.DefaultArgoCD != nil && instance.Spec.DefaultArgoCD.ManagementState != "" {
+		return instance.Spec.DefaultArgoCD.ManagementState
+	}
+	if r.DisableDefaultInstall {
+		return pipelinesv1beta1.DefaultArgoCDRemoved
+	}
+	return pipelinesv1beta1.DefaultArgoCDManaged
+}
+
+// releaseArgoCDInstance removes the GitopsService owner reference from the given Argo CD instance, so that
+// it is neither reconciled nor garbage collected with the GitopsService.
+func (r *ReconcileGitopsService) releaseArgoCDInstance(ctx context.Context, instance *pipelinesv1beta1.GitopsService, name types.NamespacedName,
+	reqLogger logr.Logger) error {
+	existingArgoCD := &argoapp.ArgoCD{}
+	err := r.Client.Get(ctx, name, existingArgoCD)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	var ownerReferences []metav1.OwnerReference
+	for _, ownerReference := range existingArgoCD.OwnerReferences {
+		if ownerReference.UID != instance.UID {
+			ownerReferences = append(ownerReferences, ownerReference)
+		}
+	}
+	if len(ownerReferences) == len(existingArgoCD.OwnerReferences) {
+		return nil
+	}
+
+	reqLogger.Info("Releasing ArgoCD", "Namespace", existingArgoCD.Namespace, "Name", existingArgoCD.Name)
+	existingArgoCD.OwnerReferences = ownerReferences
+	if err := r.Client.Update(ctx, existingArgoCD); err != nil {
+		return recordResourceEvent(r.Recorder, instance, resourceUpdate, existingArgoCD, err)
+	}
+	r.Recorder.Eventf(instance, corev1.EventTypeNormal, "ArgoCDUnmanaged",
+		"Argo CD instance %s/%s is no longer managed by the operator and is left running", existingArgoCD.Namespace, existingArgoCD.Name)
+	return nil
+}
+
+func (r *ReconcileGitopsService) ensureDefaultArgoCDInstanceDoesntExist(instance *pipelinesv1beta1.GitopsService) error {
 
-	defaultArgoCDInstance, err := argocd.NewCR(common.ArgoCDInstanceName, serviceNamespace, r.Client)
+	argoCD, err := argocd.NewCR(common.ArgoCDInstanceName, serviceNamespace, r.Client)
 	if err != nil {
 		return err
 	}
 
-	argocdNS := newRestrictedNamespace(defaultArgoCDInstance.Namespace)
+	argocdNS := newRestrictedNamespace(argoCD.Namespace)
 	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: argocdNS.Name}, &corev1.Namespace{})
 	if err != nil {
 
@@ -396,12 +591,14 @@ func (r *ReconcileGitopsService) ensureDefaultArgoCDInstanceDoesntExist() error
 
 	// Delete the existing Argo CD instance, if it exists
 	existingArgoCD := &argoapp.ArgoCD{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: defaultArgoCDInstance.Name, Namespace: defaultArgoCDInstance.Namespace}, existingArgoCD)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: argoCD.Name, Namespace: argoCD.Namespace}, existingArgoCD)
 	if err == nil {
 		// The default Argo CD instance exists, delete it.
 		if err := r.Client.Delete(context.TODO(), existingArgoCD); err != nil {
-			return err
+			return recordResourceEvent(r.Recorder, instance, resourceDelete, existingArgoCD, err)
 		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "ArgoCDRemoved",
+			"Argo CD instance %s/%s was deleted because the default Argo CD instance is disabled", existingArgoCD.Namespace, existingArgoCD.Name)
 
 	} else if !errors.IsNotFound(err) {
 		// If an unexpected error occurred (eg not the 'not found' error, which is expected) then just return it
@@ -411,23 +608,18 @@ func (r *ReconcileGitopsService) ensureDefaultArgoCDInstanceDoesntExist() error
 	return nil
 }
 
-func (r *ReconcileGitopsService) reconcileDefaultArgoCDInstance(instance *pipelinesv1alpha1.GitopsService, reqLogger logr.Logger) (reconcile.Result, error) {
-
-	defaultArgoCDInstance, err := argocd.NewCR(common.ArgoCDInstanceName, serviceNamespace, r.Client)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
+func (r *ReconcileGitopsService) reconcileDefaultArgoCDInstance(instance *pipelinesv1beta1.GitopsService, reqLogger logr.Logger) (reconcile.Result, error) {
 
 	// The operator decides the namespace based on the version of the cluster it is installed in
 	// 4.6 Cluster: Backend in openshift-pipelines-app-delivery namespace and argocd in openshift-gitops namespace
 	// 4.7 Cluster: Both backend and argocd instance in openshift-gitops namespace
-	argocdNS := newRestrictedNamespace(defaultArgoCDInstance.Namespace)
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: argocdNS.Name}, argocdNS)
+	argocdNS := newRestrictedNamespace(serviceNamespace)
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: argocdNS.Name}, argocdNS)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			reqLogger.Info("Creating a new Namespace", "Name", argocdNS.Name)
 			ensureInfraNodeSelectorAnnotation(argocdNS, instance.Spec.RunOnInfra)
-			err = r.Client.Create(context.TODO(), argocdNS)
+			err = recordResourceEvent(r.Recorder, instance, resourceCreate, argocdNS, r.Client.Create(context.TODO(), argocdNS))
 			if err != nil {
 				return reconcile.Result{}, err
 			}
@@ -450,14 +642,14 @@ func (r *ReconcileGitopsService) reconcileDefaultArgoCDInstance(instance *pipeli
 				reqLogger.Info("No ResourceQuota set for namespace", "Name", argocdNS.Name)
 			}
 		} else {
-			err = r.Client.Delete(context.TODO(), resourceQuotaObj)
+			err = recordResourceEvent(r.Recorder, instance, resourceDelete, resourceQuotaObj, r.Client.Delete(context.TODO(), resourceQuotaObj))
 			if err != nil {
 				return reconcile.Result{}, err
 			}
 		}
 
 		if ensureNamespaceMetadata(argocdNS, instance.Spec.RunOnInfra) {
-			err = r.Client.Update(context.TODO(), argocdNS)
+			err = recordResourceEvent(r.Recorder, instance, resourceUpdate, argocdNS, r.Client.Update(context.TODO(), argocdNS))
 			if err != nil {
 				return reconcile.Result{}, err
 			}
@@ -465,120 +657,293 @@ func (r *ReconcileGitopsService) reconcileDefaultArgoCDInstance(instance *pipeli
 
 	}
 
+	return r.reconcileArgoCDInstance(instance, types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace},
+		instance.Spec.DefaultArgoCD, reqLogger)
+}
+
+// reconcileArgoCDInstance creates or updates the given Argo CD instance with the settings of spec, which can be nil.
+func (r *ReconcileGitopsService) reconcileArgoCDInstance(instance *pipelinesv1beta1.GitopsService, name types.NamespacedName,
+	spec *pipelinesv1beta1.DefaultArgoCDSpec, reqLogger logr.Logger) (reconcile.Result, error) {
+
+	var profile pipelinesv1beta1.DefaultArgoCDProfile
+	var resourceExclusions *pipelinesv1beta1.ResourceExclusionsSpec
+	if spec != nil {
+		profile = spec.Profile
+		resourceExclusions = spec.ResourceExclusions
+	}
+
+	argoCD, err := argocd.NewCRForProfile(name.Name, name.Namespace, profile, r.Client)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// Set GitopsService instance as the owner and controller
-	if err := controllerutil.SetControllerReference(instance, defaultArgoCDInstance, r.Scheme); err != nil {
+	if err := controllerutil.SetControllerReference(instance, argoCD, r.Scheme); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if len(instance.Spec.NodeSelector) > 0 {
-		if defaultArgoCDInstance.Spec.NodePlacement == nil {
-			defaultArgoCDInstance.Spec.NodePlacement = &argoapp.ArgoCDNodePlacementSpec{
-				NodeSelector: instance.Spec.NodeSelector,
+	var nodeSelector map[string]string
+	var tolerations []corev1.Toleration
+	if spec != nil {
+		nodeSelector = spec.NodeSelector
+		tolerations = spec.Tolerations
+	}
+	if len(nodeSelector) > 0 {
+		if argoCD.Spec.NodePlacement == nil {
+			argoCD.Spec.NodePlacement = &argoapp.ArgoCDNodePlacementSpec{
+				NodeSelector: nodeSelector,
 			}
 		} else {
-			defaultArgoCDInstance.Spec.NodePlacement.NodeSelector = argocdutil.AppendStringMap(defaultArgoCDInstance.Spec.NodePlacement.NodeSelector, instance.Spec.NodeSelector)
+			argoCD.Spec.NodePlacement.NodeSelector = argocdutil.AppendStringMap(argoCD.Spec.NodePlacement.NodeSelector, nodeSelector)
 		}
 	}
-	if len(instance.Spec.Tolerations) > 0 {
-		if defaultArgoCDInstance.Spec.NodePlacement == nil {
-			defaultArgoCDInstance.Spec.NodePlacement = &argoapp.ArgoCDNodePlacementSpec{
-				Tolerations: instance.Spec.Tolerations,
+	if len(tolerations) > 0 {
+		if argoCD.Spec.NodePlacement == nil {
+			argoCD.Spec.NodePlacement = &argoapp.ArgoCDNodePlacementSpec{
+				Tolerations: tolerations,
 			}
 		} else {
-			defaultArgoCDInstance.Spec.NodePlacement.Tolerations = instance.Spec.Tolerations
+			argoCD.Spec.NodePlacement.Tolerations = tolerations
 		}
 	}
 
-	// Get or create ArgoCD instance in default namespace
+	// Get the existing ArgoCD instance, if any
+	action := resourceUpdate
 	existingArgoCD := &argoapp.ArgoCD{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: defaultArgoCDInstance.Name, Namespace: defaultArgoCDInstance.Namespace}, existingArgoCD)
+	err = r.Client.Get(context.TODO(), name, existingArgoCD)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			reqLogger.Info("Creating a new ArgoCD instance", "Namespace", defaultArgoCDInstance.Namespace, "Name", defaultArgoCDInstance.Name)
-			err = r.Client.Create(context.TODO(), defaultArgoCDInstance)
-			if err != nil {
-				return reconcile.Result{}, err
-			}
-		} else {
+		if !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
+		reqLogger.Info("Creating a new ArgoCD instance", "Namespace", argoCD.Namespace, "Name", argoCD.Name)
+		action = resourceCreate
 	} else {
-		changed := false
-		if existingArgoCD.Spec.ApplicationSet != nil {
-			if existingArgoCD.Spec.ApplicationSet.Resources == nil {
-				existingArgoCD.Spec.ApplicationSet.Resources = defaultArgoCDInstance.Spec.ApplicationSet.Resources
-				changed = true
-			}
+		if !metav1.IsControlledBy(existingArgoCD, instance) {
+			reqLogger.Info("Taking over ArgoCD", "Namespace", existingArgoCD.Namespace, "Name", existingArgoCD.Name)
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "ArgoCDManaged",
+				"Argo CD instance %s/%s is managed by the operator", existingArgoCD.Namespace, existingArgoCD.Name)
 		}
 
-		if existingArgoCD.Spec.Controller.Resources == nil {
-			existingArgoCD.Spec.Controller.Resources = defaultArgoCDInstance.Spec.Controller.Resources
-			changed = true
+		// Leave the SSO configuration alone when the user switched to another provider
+		if !argocdcontroller.UseDex(existingArgoCD) {
+			argoCD.Spec.SSO = nil
 		}
 
-		if argocdcontroller.UseDex(existingArgoCD) {
-			if existingArgoCD.Spec.SSO != nil && existingArgoCD.Spec.SSO.Provider == argoapp.SSOProviderTypeDex {
-				if existingArgoCD.Spec.SSO.Dex != nil {
-					if existingArgoCD.Spec.SSO.Dex.Resources == nil {
-						existingArgoCD.Spec.SSO.Dex.Resources = defaultArgoCDInstance.Spec.SSO.Dex.Resources
-					}
-				}
+		// The resource exclusions are merged with the entries added by users
+		argoCD.Spec.ResourceExclusions = existingArgoCD.Spec.ResourceExclusions
+		if value, ok := existingArgoCD.Annotations[argocd.ManagedResourceExclusionsAnnotation]; ok {
+			if argoCD.Annotations == nil {
+				argoCD.Annotations = map[string]string{}
 			}
-			changed = true
+			argoCD.Annotations[argocd.ManagedResourceExclusionsAnnotation] = value
 		}
+	}
 
-		//lint:ignore SA1019 known to be deprecated
-		if existingArgoCD.Spec.Grafana.Resources == nil { //nolint:staticcheck // SA1019: We must test deprecated fields.
-			//lint:ignore SA1019 known to be deprecated
-			existingArgoCD.Spec.Grafana.Resources = defaultArgoCDInstance.Spec.Grafana.Resources //nolint:staticcheck // SA1019: We must test deprecated fields.
-			changed = true
-		}
+	// Keep the resource exclusions in sync with the operator defaults and the GitopsService CR
+	if _, err := argocd.SetResourceExclusions(argoCD, resourceExclusions); err != nil {
+		return reconcile.Result{}, err
+	}
 
-		if existingArgoCD.Spec.HA.Resources == nil {
-			existingArgoCD.Spec.HA.Resources = defaultArgoCDInstance.Spec.HA.Resources
-			changed = true
+	if err := applyArgoCDOverrides(argoCD, spec); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Export the Application labels of the alerts configured on the instance in the metrics of its application
+	// controller, along with the arguments set in the overrides
+	if action == resourceUpdate {
+		keys, err := metricsApplicationLabelsConfig(existingArgoCD)
+		if err != nil {
+			reqLogger.Info("Invalid monitoring configuration, keeping the exported Application labels", "error", err.Error())
 		}
+		var added string
+		argoCD.Spec.Controller.ExtraCommandArgs, added = withMetricsApplicationLabels(argoCD.Spec.Controller.ExtraCommandArgs, "", keys)
+		if added != "" {
+			if argoCD.Annotations == nil {
+				argoCD.Annotations = map[string]string{}
+			}
+			argoCD.Annotations[metricsApplicationLabelsAnnotation] = added
+		}
+	}
+
+	// Fields owned by another field manager are left unchanged, except the ones configured in the GitopsService CR
+	forced, err := forcedArgoCDFields(spec)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	var existing *argoapp.ArgoCD
+	if action == resourceUpdate {
+		existing = existingArgoCD
+	}
+	conflicts, err := r.applyArgoCDInstance(context.TODO(), argoCD, existing, forced)
+	if err != nil {
+		return reconcile.Result{}, recordResourceEvent(r.Recorder, instance, action, argoCD, err)
+	}
+	// The instance is applied on every reconcile, only its creation is reported
+	if action == resourceCreate {
+		_ = recordResourceEvent(r.Recorder, instance, action, argoCD, nil)
+	}
+	if len(conflicts) > 0 {
+		reqLogger.Info("Leaving fields of ArgoCD owned by other field managers unchanged", "Namespace", argoCD.Namespace,
+			"Name", argoCD.Name, "Conflicts", fmt.Sprint(conflicts))
+	}
 
-		if existingArgoCD.Spec.Redis.Resources == nil {
-			existingArgoCD.Spec.Redis.Resources = defaultArgoCDInstance.Spec.Redis.Resources
-			changed = true
+	return reconcile.Result{}, nil
+}
+
+// reconcileArgoCDInstances creates, updates, releases or deletes the Argo CD instances listed in spec.instances
+// of the GitopsService, and deletes the instances it controls that are no longer listed.
+func (r *ReconcileGitopsService) reconcileArgoCDInstances(ctx context.Context, instance *pipelinesv1beta1.GitopsService, reqLogger logr.Logger) error {
+	// Grant the cluster configuration privileges before the instances are reconciled
+	changed, err := util.SetClusterConfigNamespaces(clusterConfigNamespaces(instance))
+	if err != nil {
+		return err
+	}
+	if err := r.annotateClusterConfigInstances(ctx, changed, reqLogger); err != nil {
+		return err
+	}
+
+	listed := map[types.NamespacedName]bool{}
+	for i := range instance.Spec.Instances {
+		spec := &instance.Spec.Instances[i]
+		name := types.NamespacedName{Name: spec.Name, Namespace: spec.Namespace}
+		switch spec.ManagementState {
+		case pipelinesv1beta1.DefaultArgoCDRemoved:
+			// Deleted below, with the instances that are no longer listed
+			continue
+		case pipelinesv1beta1.DefaultArgoCDUnmanaged:
+			if err := r.releaseArgoCDInstance(ctx, instance, name, reqLogger); err != nil {
+				return fmt.Errorf("unable to release Argo CD instance %s: %v", name, err)
+			}
+		default:
+			if err := r.ensureArgoCDNamespace(ctx, instance, spec.Namespace, reqLogger); err != nil {
+				return fmt.Errorf("unable to reconcile Argo CD instance %s: %v", name, err)
+			}
+			if _, err := r.reconcileArgoCDInstance(instance, name, &spec.DefaultArgoCDSpec, reqLogger); err != nil {
+				return fmt.Errorf("unable to reconcile Argo CD instance %s: %v", name, err)
+			}
 		}
+		listed[name] = true
+	}
 
-		if existingArgoCD.Spec.Repo.Resources == nil {
-			existingArgoCD.Spec.Repo.Resources = defaultArgoCDInstance.Spec.Repo.Resources
-			changed = true
+	argoCDs := &argoapp.ArgoCDList{}
+	if err := r.Client.List(ctx, argoCDs); err != nil {
+		return err
+	}
+	for i := range argoCDs.Items {
+		argoCD := &argoCDs.Items[i]
+		name := types.NamespacedName{Name: argoCD.Name, Namespace: argoCD.Namespace}
+		if listed[name] || (argoCD.Name == common.ArgoCDInstanceName && argoCD.Namespace == serviceNamespace) ||
+			!metav1.IsControlledBy(argoCD, instance) {
+			continue
 		}
+		reqLogger.Info("Deleting ArgoCD no longer listed in the GitopsService", "Namespace", argoCD.Namespace, "Name", argoCD.Name)
+		if err := r.Client.Delete(ctx, argoCD); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete Argo CD instance %s: %v", name, recordResourceEvent(r.Recorder, instance, resourceDelete, argoCD, err))
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "ArgoCDRemoved",
+			"Argo CD instance %s/%s was deleted because it is no longer listed in spec.instances", argoCD.Namespace, argoCD.Name)
+	}
+	return nil
+}
 
-		if existingArgoCD.Spec.Server.Resources == nil {
-			existingArgoCD.Spec.Server.Resources = defaultArgoCDInstance.Spec.Server.Resources
-			changed = true
+// clusterConfigNamespaces returns the namespaces of the instances of spec.instances granted the cluster configuration
+// privileges. The privileges are kept while an instance is left running unmanaged.
+func clusterConfigNamespaces(instance *pipelinesv1beta1.GitopsService) []string {
+	var namespaces []string
+	for _, spec := range instance.Spec.Instances {
+		if spec.ClusterConfig && spec.ManagementState != pipelinesv1beta1.DefaultArgoCDRemoved {
+			namespaces = append(namespaces, spec.Namespace)
 		}
+	}
+	return namespaces
+}
 
-		// if user is patching nodePlacement through GitopsService CR, then existingArgoCD NodePlacement is updated.
-		if defaultArgoCDInstance.Spec.NodePlacement != nil {
-			if !reflect.DeepEqual(existingArgoCD.Spec.NodePlacement, defaultArgoCDInstance.Spec.NodePlacement) {
-				existingArgoCD.Spec.NodePlacement = defaultArgoCDInstance.Spec.NodePlacement
-				changed = true
+// annotateClusterConfigInstances records in argocd.ClusterConfigAnnotation whether the Argo CD instances of the given
+// namespaces, whose cluster configuration privileges were granted or revoked, have the privileges. The change of
+// annotation makes the Argo CD reconciler grant or revoke them, their spec is unchanged.
+func (r *ReconcileGitopsService) annotateClusterConfigInstances(ctx context.Context, namespaces []string, reqLogger logr.Logger) error {
+	for _, namespace := range namespaces {
+		argoCDs := &argoapp.ArgoCDList{}
+		if err := r.Client.List(ctx, argoCDs, client.InNamespace(namespace)); err != nil {
+			return err
+		}
+		value := strconv.FormatBool(util.IsClusterConfigNamespace(namespace))
+		for i := range argoCDs.Items {
+			argoCD := &argoCDs.Items[i]
+			if argoCD.Annotations[argocd.ClusterConfigAnnotation] == value {
+				continue
+			}
+			reqLogger.Info("Updating the cluster configuration privileges of ArgoCD", "Namespace", argoCD.Namespace,
+				"Name", argoCD.Name, "Granted", value)
+			patch := client.MergeFrom(argoCD.DeepCopy())
+			if argoCD.Annotations == nil {
+				argoCD.Annotations = map[string]string{}
+			}
+			argoCD.Annotations[argocd.ClusterConfigAnnotation] = value
+			if err := r.Client.Patch(ctx, argoCD, patch); err != nil && !errors.IsNotFound(err) {
+				return err
 			}
-			// Handle the case where NodePlacement should be removed
-		} else if existingArgoCD.Spec.NodePlacement != nil {
-			existingArgoCD.Spec.NodePlacement = defaultArgoCDInstance.Spec.NodePlacement
-			changed = true
 		}
+	}
+	return nil
+}
 
-		if changed {
-			reqLogger.Info("Reconciling ArgoCD", "Namespace", existingArgoCD.Namespace, "Name", existingArgoCD.Name)
-			err = r.Client.Update(context.TODO(), existingArgoCD)
-			if err != nil {
-				return reconcile.Result{}, err
-			}
+// InitClusterConfigNamespaces grants the cluster configuration privileges of the instances listed in the GitopsService
+// before the controllers start, so that the Argo CD reconciler does not revoke them after a restart of the operator
+// until the GitopsService is reconciled. reader must not depend on the cache of the manager, which is not started.
+func InitClusterConfigNamespaces(ctx context.Context, reader client.Reader) error {
+	instance := &pipelinesv1beta1.GitopsService{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: serviceName}, instance); err != nil {
+		if errors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+			return nil
 		}
+		return err
 	}
+	_, err := util.SetClusterConfigNamespaces(clusterConfigNamespaces(instance))
+	return err
+}
 
-	return reconcile.Result{}, nil
+// ensureArgoCDNamespace creates the namespace of an Argo CD instance listed in spec.instances if it does not exist.
+// Existing namespaces are left unchanged.
+func (r *ReconcileGitopsService) ensureArgoCDNamespace(ctx context.Context, instance *pipelinesv1beta1.GitopsService, namespace string, reqLogger logr.Logger) error {
+	err := r.Client.Get(ctx, types.NamespacedName{Name: namespace}, &corev1.Namespace{})
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+	reqLogger.Info("Creating a new Namespace", "Name", namespace)
+	argocdNS := newRestrictedNamespace(namespace)
+	ensureInfraNodeSelectorAnnotation(argocdNS, instance.Spec.RunOnInfra)
+	return recordResourceEvent(r.Recorder, instance, resourceCreate, argocdNS, r.Client.Create(ctx, argocdNS))
 }
 
-func (r *ReconcileGitopsService) reconcileBackend(gitopsserviceNamespacedName types.NamespacedName, instance *pipelinesv1alpha1.GitopsService,
+// applyArgoCDOverrides applies the overrides of spec, which can be nil, as a strategic merge patch on top of the spec
+// of the given Argo CD instance.
+func applyArgoCDOverrides(argoCD *argoapp.ArgoCD, spec *pipelinesv1beta1.DefaultArgoCDSpec) error {
+	if spec == nil || spec.Overrides == nil || len(spec.Overrides.Raw) == 0 {
+		return nil
+	}
+
+	original, err := json.Marshal(argoCD.Spec)
+	if err != nil {
+		return err
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, spec.Overrides.Raw, argoapp.ArgoCDSpec{})
+	if err != nil {
+		return fmt.Errorf("unable to apply overrides: %w", err)
+	}
+
+	// Reject unknown fields so that typos in the overrides are reported instead of silently ignored
+	patchedSpec := argoapp.ArgoCDSpec{}
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patchedSpec); err != nil {
+		return fmt.Errorf("unable to apply overrides: %w", err)
+	}
+	argoCD.Spec = patchedSpec
+	return nil
+}
+
+func (r *ReconcileGitopsService) reconcileBackend(gitopsserviceNamespacedName types.NamespacedName, instance *pipelinesv1beta1.GitopsService,
 	reqLogger logr.Logger) (reconcile.Result, error) {
 
 	// Define Service account for backend Service
@@ -595,74 +960,45 @@ func (r *ReconcileGitopsService) reconcileBackend(gitopsserviceNamespacedName ty
 		if err != nil {
 			if errors.IsNotFound(err) {
 				reqLogger.Info("Creating a new ServiceAccount", "Namespace", serviceAccountObj.Namespace, "Name", serviceAccountObj.Name)
-				err = r.Client.Create(context.TODO(), serviceAccountObj)
-				if err != nil {
-					return reconcile.Result{}, err
-				}
-			} else {
-				return reconcile.Result{}, err
-			}
-		}
-	}
-
-	// Define a new cluster role for backend service
-	{
-		clusterRoleObj := newClusterRole(gitopsserviceNamespacedName)
-
-		// Set GitopsService instance as the owner and controller
-		if err := controllerutil.SetControllerReference(instance, clusterRoleObj, r.Scheme); err != nil {
-			return reconcile.Result{}, err
-		}
-
-		existingClusterRole := &rbacv1.ClusterRole{}
-		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: clusterRoleObj.Name}, existingClusterRole)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				reqLogger.Info("Creating a new Cluster Role", "Name", clusterRoleObj.Name)
-				err = r.Client.Create(context.TODO(), clusterRoleObj)
+				err = recordResourceEvent(r.Recorder, instance, resourceCreate, serviceAccountObj, r.Client.Create(context.TODO(), serviceAccountObj))
 				if err != nil {
 					return reconcile.Result{}, err
 				}
 			} else {
 				return reconcile.Result{}, err
 			}
-		} else if !reflect.DeepEqual(existingClusterRole.Rules, clusterRoleObj.Rules) {
-			reqLogger.Info("Reconciling existing Cluster Role", "Name", clusterRoleObj.Name)
-			existingClusterRole.Rules = clusterRoleObj.Rules
-			err = r.Client.Update(context.TODO(), existingClusterRole)
+		} else {
+			changed, err := r.reconcileControllerReference(instance, existingServiceAccount)
 			if err != nil {
 				return reconcile.Result{}, err
 			}
-		}
-	}
-
-	// Define Cluster Role Binding for backend service
-	{
-		clusterRoleBinding := newClusterRoleBinding(gitopsserviceNamespacedName)
-
-		// Set GitopsService instance as the owner and controller
-		if err := controllerutil.SetControllerReference(instance, clusterRoleBinding, r.Scheme); err != nil {
-			return reconcile.Result{}, err
-		}
-
-		existingClusterRoleBinding := &rbacv1.ClusterRoleBinding{}
-		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: clusterRoleBinding.Name}, existingClusterRoleBinding)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				reqLogger.Info("Creating a new Cluster Role Binding", "Name", clusterRoleBinding.Name)
-				err = r.Client.Create(context.TODO(), clusterRoleBinding)
+			if changed {
+				reqLogger.Info("Reconciling existing ServiceAccount", "Namespace", existingServiceAccount.Namespace, "Name", existingServiceAccount.Name)
+				err = recordResourceDriftEvent(r.Recorder, instance, existingServiceAccount, []string{"ownerReferences"},
+					r.Client.Update(context.TODO(), existingServiceAccount))
 				if err != nil {
 					return reconcile.Result{}, err
 				}
-			} else {
-				return reconcile.Result{}, err
 			}
 		}
 	}
 
+	// Define the RBAC of the backend service, cluster wide or in the namespaces used by Argo CD
+	if err := r.reconcileBackendRBAC(instance, gitopsserviceNamespacedName, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// Define a new backend Deployment
 	{
-		deploymentObj := newBackendDeployment(gitopsserviceNamespacedName, instance.Spec.ImagePullPolicy, r.CentralTLSProfile)
+		backend := instance.Spec.Backend
+		if backend == nil {
+			backend = &pipelinesv1beta1.BackendSpec{}
+		}
+		pullPolicy := instance.Spec.ImagePullPolicy
+		if backend.ImagePullPolicy != "" {
+			pullPolicy = backend.ImagePullPolicy
+		}
+		deploymentObj := newBackendDeployment(gitopsserviceNamespacedName, pullPolicy, r.CentralTLSProfile)
 
 		// Add SeccompProfile based on cluster version
 		util.AddSeccompProfileForOpenShift(r.Client, &deploymentObj.Spec.Template.Spec)
@@ -672,15 +1008,22 @@ func (r *ReconcileGitopsService) reconcileBackend(gitopsserviceNamespacedName ty
 		if err := controllerutil.SetControllerReference(instance, deploymentObj, r.Scheme); err != nil {
 			return reconcile.Result{}, err
 		}
-		if len(instance.Spec.NodeSelector) > 0 {
-			deploymentObj.Spec.Template.Spec.NodeSelector = argocdutil.AppendStringMap(deploymentObj.Spec.Template.Spec.NodeSelector, instance.Spec.NodeSelector)
+		if len(backend.NodeSelector) > 0 {
+			deploymentObj.Spec.Template.Spec.NodeSelector = argocdutil.AppendStringMap(deploymentObj.Spec.Template.Spec.NodeSelector, backend.NodeSelector)
+		}
+		if len(backend.Tolerations) > 0 {
+			deploymentObj.Spec.Template.Spec.Tolerations = backend.Tolerations
 		}
-		if len(instance.Spec.Tolerations) > 0 {
-			deploymentObj.Spec.Template.Spec.Tolerations = instance.Spec.Tolerations
+		if backend.Resources != nil {
+			deploymentObj.Spec.Template.Spec.Containers[0].Resources = *backend.Resources
 		}
-		if instance.Spec.ConsolePlugin != nil && instance.Spec.ConsolePlugin.Backend != nil && instance.Spec.ConsolePlugin.Backend.Resources != nil {
-			deploymentObj.Spec.Template.Spec.Containers[0].Resources = *instance.Spec.ConsolePlugin.Backend.Resources
+		setWorkloadAvailability(deploymentObj, backend.Replicas, backend.Affinity, backend.TopologySpreadConstraints)
+		applyPodTemplateOverrides(deploymentObj, backend.PodTemplateOverrides)
+
+		if err := r.reconcilePodDisruptionBudget(instance, newPodDisruptionBudget(deploymentObj), reqLogger); err != nil {
+			return reconcile.Result{}, err
 		}
+
 		// Check if this Deployment already exists
 		found := &appsv1.Deployment{}
 		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: deploymentObj.Name, Namespace: deploymentObj.Namespace},
@@ -688,7 +1031,7 @@ func (r *ReconcileGitopsService) reconcileBackend(gitopsserviceNamespacedName ty
 
 			if errors.IsNotFound(err) {
 				reqLogger.Info("Creating a new Deployment", "Namespace", deploymentObj.Namespace, "Name", deploymentObj.Name)
-				err = r.Client.Create(context.TODO(), deploymentObj)
+				err = recordResourceEvent(r.Recorder, instance, resourceCreate, deploymentObj, r.Client.Create(context.TODO(), deploymentObj))
 				if err != nil {
 					return reconcile.Result{}, err
 				}
@@ -697,6 +1040,10 @@ func (r *ReconcileGitopsService) reconcileBackend(gitopsserviceNamespacedName ty
 			}
 		} else {
 			changed := false
+			if !reflect.DeepEqual(found.Spec.Replicas, deploymentObj.Spec.Replicas) {
+				found.Spec.Replicas = deploymentObj.Spec.Replicas
+				changed = true
+			}
 			desiredImage := deploymentObj.Spec.Template.Spec.Containers[0].Image
 			if found.Spec.Template.Spec.Containers[0].Image != desiredImage {
 				found.Spec.Template.Spec.Containers[0].Image = desiredImage
@@ -738,10 +1085,41 @@ func (r *ReconcileGitopsService) reconcileBackend(gitopsserviceNamespacedName ty
 				found.Spec.Template.Spec.SecurityContext = deploymentObj.Spec.Template.Spec.SecurityContext
 				changed = true
 			}
+			if !reflect.DeepEqual(found.Spec.Template.Spec.Affinity, deploymentObj.Spec.Template.Spec.Affinity) {
+				found.Spec.Template.Spec.Affinity = deploymentObj.Spec.Template.Spec.Affinity
+				changed = true
+			}
+			if !reflect.DeepEqual(found.Spec.Template.Spec.TopologySpreadConstraints, deploymentObj.Spec.Template.Spec.TopologySpreadConstraints) {
+				found.Spec.Template.Spec.TopologySpreadConstraints = deploymentObj.Spec.Template.Spec.TopologySpreadConstraints
+				changed = true
+			}
+			if !reflect.DeepEqual(found.Spec.Template.Spec.Containers[0].VolumeMounts, deploymentObj.Spec.Template.Spec.Containers[0].VolumeMounts) {
+				found.Spec.Template.Spec.Containers[0].VolumeMounts = deploymentObj.Spec.Template.Spec.Containers[0].VolumeMounts
+				changed = true
+			}
+			if !reflect.DeepEqual(found.Spec.Template.Spec.Containers[1:], deploymentObj.Spec.Template.Spec.Containers[1:]) {
+				found.Spec.Template.Spec.Containers = append(found.Spec.Template.Spec.Containers[:1], deploymentObj.Spec.Template.Spec.Containers[1:]...)
+				changed = true
+			}
+			if !reflect.DeepEqual(found.Spec.Template.Spec.Volumes, deploymentObj.Spec.Template.Spec.Volumes) {
+				found.Spec.Template.Spec.Volumes = deploymentObj.Spec.Template.Spec.Volumes
+				changed = true
+			}
+			if !reflect.DeepEqual(found.Spec.Template.Labels, deploymentObj.Spec.Template.Labels) {
+				found.Spec.Template.Labels = deploymentObj.Spec.Template.Labels
+				changed = true
+			}
+			if found.Spec.Template.Spec.PriorityClassName != deploymentObj.Spec.Template.Spec.PriorityClassName {
+				found.Spec.Template.Spec.PriorityClassName = deploymentObj.Spec.Template.Spec.PriorityClassName
+				changed = true
+			}
+			if reconcileOverriddenAnnotations(found, deploymentObj) {
+				changed = true
+			}
 
 			if changed {
 				reqLogger.Info("Reconciling existing backend Deployment", "Namespace", deploymentObj.Namespace, "Name", deploymentObj.Name)
-				err = r.Client.Update(context.TODO(), found)
+				err = recordResourceEvent(r.Recorder, instance, resourceUpdate, found, r.Client.Update(context.TODO(), found))
 				if err != nil {
 					return reconcile.Result{}, err
 				}
@@ -764,13 +1142,28 @@ func (r *ReconcileGitopsService) reconcileBackend(gitopsserviceNamespacedName ty
 
 			if errors.IsNotFound(err) {
 				reqLogger.Info("Creating a new Service", "Namespace", serviceRef.Namespace, "Name", serviceRef.Name)
-				err = r.Client.Create(context.TODO(), serviceRef)
+				err = recordResourceEvent(r.Recorder, instance, resourceCreate, serviceRef, r.Client.Create(context.TODO(), serviceRef))
 				if err != nil {
 					return reconcile.Result{}, err
 				}
 			} else {
 				return reconcile.Result{}, err
 			}
+		} else if changed := reconcileServiceFields(existingServiceRef, serviceRef); len(changed) > 0 {
+			reqLogger.Info("Reconciling existing backend Service", "Namespace", existingServiceRef.Namespace, "Name", existingServiceRef.Name, "Changed", changed)
+			err = recordResourceDriftEvent(r.Recorder, instance, existingServiceRef, changed, r.Client.Update(context.TODO(), existingServiceRef))
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
+	// Only allow the ingress traffic of the backend from the console and the monitoring stack
+	{
+		policy := newIngressNetworkPolicy(gitopsserviceNamespacedName.Name, gitopsserviceNamespacedName.Namespace,
+			map[string]string{"app.kubernetes.io/name": gitopsserviceNamespacedName.Name}, port)
+		if err := r.reconcileNetworkPolicy(instance, policy, reqLogger); err != nil {
+			return reconcile.Result{}, err
 		}
 	}
 
@@ -862,7 +1255,8 @@ func newBackendDeployment(ns types.NamespacedName, crImagePullPolicy corev1.Pull
 				Name: "backend-ssl",
 				VolumeSource: corev1.VolumeSource{
 					Secret: &corev1.SecretVolumeSource{
-						SecretName: ns.Name,
+						SecretName:  ns.Name,
+						DefaultMode: ptr.To(corev1.SecretVolumeSourceDefaultMode),
 					},
 				},
 			},
@@ -927,12 +1321,72 @@ func newBackendService(ns types.NamespacedName) *corev1.Service {
 	return svc
 }
 
+// reconcileControllerReference sets the GitopsService as the controller of the existing object, and returns true
+// if it was not already. It fails if the object is controlled by another resource.
+func (r *ReconcileGitopsService) reconcileControllerReference(instance *pipelinesv1beta1.GitopsService, existing metav1.Object) (bool, error) {
+	if metav1.IsControlledBy(existing, instance) {
+		return false, nil
+	}
+	return true, controllerutil.SetControllerReference(instance, existing, r.Scheme)
+}
+
+// reconcileServiceFields sets the fields of the existing Service that differ from the desired one, and returns
+// the names of the fields that changed. Only the labels and annotations set by the operator are compared, the
+// ones added by other controllers, like the service CA operator, are kept.
+func reconcileServiceFields(existing, desired *corev1.Service) []string {
+	var changed []string
+	if !containsStringMap(existing.Annotations, desired.Annotations) {
+		existing.Annotations = argocdutil.AppendStringMap(existing.Annotations, desired.Annotations)
+		changed = append(changed, "annotations")
+	}
+	if !containsStringMap(existing.Labels, desired.Labels) {
+		existing.Labels = argocdutil.AppendStringMap(existing.Labels, desired.Labels)
+		changed = append(changed, "labels")
+	}
+	if serviceType(existing) != serviceType(desired) {
+		existing.Spec.Type = desired.Spec.Type
+		changed = append(changed, "type")
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Selector, desired.Spec.Selector) {
+		existing.Spec.Selector = desired.Spec.Selector
+		changed = append(changed, "selector")
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Ports, desired.Spec.Ports) {
+		existing.Spec.Ports = desired.Spec.Ports
+		changed = append(changed, "ports")
+	}
+	return changed
+}
+
+// serviceType returns the type of the Service, ClusterIP by default
+func serviceType(service *corev1.Service) corev1.ServiceType {
+	if service.Spec.Type == "" {
+		return corev1.ServiceTypeClusterIP
+	}
+	return service.Spec.Type
+}
+
+// containsStringMap returns true if every key of subset is set to the same value in m
+func containsStringMap(m, subset map[string]string) bool {
+	for key, value := range subset {
+		if current, ok := m[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
+
 func newRestrictedNamespace(ns string) *corev1.Namespace {
 	objectMeta := metav1.ObjectMeta{
 		Name: ns,
 		Labels: map[string]string{
 			// Enable full-fledged support for integration with cluster monitoring.
-			"openshift.io/cluster-monitoring": "true",
+			clusterMonitoringLabel: "true",
+		},
+		Annotations: map[string]string{
+			// The monitoring label is set by the operator, it is reverted once metrics are disabled.
+			monitoringLabelOwnersAnnotation: "",
+			monitoringLabelKeyAnnotation:    clusterMonitoringLabel,
 		},
 	}
 
@@ -945,12 +1399,12 @@ func newRestrictedNamespace(ns string) *corev1.Namespace {
 	}
 }
 
-func newGitopsService() *pipelinesv1alpha1.GitopsService {
-	return &pipelinesv1alpha1.GitopsService{
+func newGitopsService() *pipelinesv1beta1.GitopsService {
+	return &pipelinesv1beta1.GitopsService{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: serviceName,
 		},
-		Spec: pipelinesv1alpha1.GitopsServiceSpec{},
+		Spec: pipelinesv1beta1.GitopsServiceSpec{},
 	}
 }
 
@@ -19,8 +19,13 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
 	argocommon "github.com/argoproj-labs/argocd-operator/common"
@@ -28,21 +33,27 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	consolev1 "github.com/openshift/api/console/v1"
 	routev1 "github.com/openshift/api/route/v1"
-	pipelinesv1alpha1 "github.com/redhat-developer/gitops-operator/api/v1alpha1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
 	"github.com/redhat-developer/gitops-operator/common"
+	gitopsargocd "github.com/redhat-developer/gitops-operator/controllers/argocd"
 	"github.com/redhat-developer/gitops-operator/controllers/util"
 	"gotest.tools/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	resourcev1 "k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -147,6 +158,46 @@ func TestImageFromEnvVariable(t *testing.T) {
 	})
 }
 
+func TestBootstrapGitopsService(t *testing.T) {
+	defer func(interval time.Duration) { bootstrapRetryInterval = interval }(bootstrapRetryInterval)
+	bootstrapRetryInterval = time.Millisecond
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	// The creation is rejected until the webhook server is reachable from the API server
+	var creates int
+	fakeClient := fake.NewClientBuilder().WithScheme(s).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				creates++
+				if creates < 3 {
+					return fmt.Errorf("failed calling webhook \"vgitopsservice.kb.io\": connection refused")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	var checks int
+	reconciler.WebhookReady = func(*http.Request) error {
+		checks++
+		if checks < 2 {
+			return fmt.Errorf("webhook server has not been started yet")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+	assertNoError(t, reconciler.bootstrapGitopsService(ctx))
+	assert.Equal(t, creates, 3)
+	getGitopsService(t, fakeClient)
+
+	// An existing instance is left unchanged
+	assertNoError(t, reconciler.bootstrapGitopsService(ctx))
+	assert.Equal(t, creates, 4)
+}
+
 func TestReconcileDefaultForArgoCDNodeplacement(t *testing.T) {
 	logf.SetLogger(argocd.ZapLogger(true))
 	s := scheme.Scheme
@@ -154,13 +205,15 @@ func TestReconcileDefaultForArgoCDNodeplacement(t *testing.T) {
 
 	var err error
 
-	gitopsService := &pipelinesv1alpha1.GitopsService{
+	gitopsService := &pipelinesv1beta1.GitopsService{
 		ObjectMeta: v1.ObjectMeta{
 			Name: serviceName,
 		},
-		Spec: pipelinesv1alpha1.GitopsServiceSpec{
-			NodeSelector: map[string]string{
-				"foo": "bar",
+		Spec: pipelinesv1beta1.GitopsServiceSpec{
+			DefaultArgoCD: &pipelinesv1beta1.DefaultArgoCDSpec{
+				NodeSelector: map[string]string{
+					"foo": "bar",
+				},
 			},
 		},
 	}
@@ -200,7 +253,303 @@ func TestReconcileDefaultForArgoCDNodeplacement(t *testing.T) {
 		existingArgoCD)
 	assertNoError(t, err)
 	assert.Check(t, existingArgoCD.Spec.NodePlacement != nil)
-	assert.DeepEqual(t, existingArgoCD.Spec.NodePlacement.NodeSelector, gitopsService.Spec.NodeSelector)
+	assert.DeepEqual(t, existingArgoCD.Spec.NodePlacement.NodeSelector, gitopsService.Spec.DefaultArgoCD.NodeSelector)
+}
+
+func TestReconcileDefaultArgoCDOverrides(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	gitopsService := &pipelinesv1beta1.GitopsService{
+		ObjectMeta: v1.ObjectMeta{
+			Name: serviceName,
+		},
+		Spec: pipelinesv1beta1.GitopsServiceSpec{
+			DefaultArgoCD: &pipelinesv1beta1.DefaultArgoCDSpec{
+				NodeSelector: map[string]string{
+					"foo": "bar",
+				},
+				Overrides: &runtime.RawExtension{
+					Raw: []byte(`{"server":{"replicas":2},"extraConfig":{"admin.enabled":"false"},"nodePlacement":{"nodeSelector":{"zone":"a"}}}`),
+				},
+			},
+		},
+	}
+
+	fakeClient := fake.NewFakeClient(gitopsService)
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	// The overrides are applied on top of the default instance when it is created
+	argoCD := &argoapp.ArgoCD{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.DeepEqual(t, argoCD.Spec.Server.Replicas, ptr.To(int32(2)))
+	assert.Equal(t, argoCD.Spec.Server.Route.Enabled, true)
+	assert.DeepEqual(t, argoCD.Spec.ExtraConfig, map[string]string{"admin.enabled": "false"})
+	assert.DeepEqual(t, argoCD.Spec.NodePlacement.NodeSelector, map[string]string{"foo": "bar", "zone": "a"})
+
+	// Manual edits of overridden fields are reverted, other edits are kept
+	argoCD.Spec.Server.Replicas = ptr.To(int32(5))
+	argoCD.Spec.SourceNamespaces = []string{"team-a"}
+	err = fakeClient.Update(context.TODO(), argoCD)
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.DeepEqual(t, argoCD.Spec.Server.Replicas, ptr.To(int32(2)))
+	assert.DeepEqual(t, argoCD.Spec.SourceNamespaces, []string{"team-a"})
+}
+
+func TestReconcileDefaultArgoCDOverrides_UnknownField(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	gitopsService := newGitopsService()
+	gitopsService.Spec.DefaultArgoCD = &pipelinesv1beta1.DefaultArgoCDSpec{
+		Overrides: &runtime.RawExtension{Raw: []byte(`{"srever":{"replicas":2}}`)},
+	}
+
+	fakeClient := fake.NewFakeClient(gitopsService)
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assert.ErrorContains(t, err, `unable to apply overrides: json: unknown field "srever"`)
+
+	// The default instance is not created from a spec the user did not intend
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, &argoapp.ArgoCD{})
+	assert.Check(t, errors.IsNotFound(err))
+}
+
+func TestReconcileDefaultArgoCDProfile(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	gitopsService := newGitopsService()
+	gitopsService.Spec.DefaultArgoCD = &pipelinesv1beta1.DefaultArgoCDSpec{
+		Profile: pipelinesv1beta1.DefaultArgoCDProfileLarge,
+	}
+
+	fakeClient := fake.NewFakeClient(gitopsService)
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	argoCD := &argoapp.ArgoCD{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.Equal(t, argoCD.Spec.HA.Enabled, true)
+	assert.Equal(t, argoCD.Spec.Controller.Sharding.Replicas, int32(3))
+
+	// Switching the profile resizes the existing instance
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: serviceName}, gitopsService)
+	assertNoError(t, err)
+	gitopsService.Spec.DefaultArgoCD.Profile = pipelinesv1beta1.DefaultArgoCDProfileSmall
+	err = fakeClient.Update(context.TODO(), gitopsService)
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.Equal(t, argoCD.Spec.HA.Enabled, false)
+	assert.Equal(t, argoCD.Spec.Controller.Sharding.Enabled, false)
+	assert.Assert(t, argoCD.Spec.Repo.Replicas == nil)
+	assert.DeepEqual(t, argoCD.Spec.Controller.Resources.Limits[corev1.ResourceMemory], resourcev1.MustParse("1024Mi"))
+
+	// Manual resource changes are owned by another field manager and are kept
+	argoCD.Spec.Repo.Resources.Limits[corev1.ResourceMemory] = resourcev1.MustParse("4096Mi")
+	err = fakeClient.Update(context.TODO(), argoCD)
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.DeepEqual(t, argoCD.Spec.Repo.Resources.Limits[corev1.ResourceMemory], resourcev1.MustParse("4096Mi"))
+}
+
+func TestReconcileDefaultArgoCDResourceExclusions(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	gitopsService := newGitopsService()
+	gitopsService.Spec.DefaultArgoCD = &pipelinesv1beta1.DefaultArgoCDSpec{
+		ResourceExclusions: &pipelinesv1beta1.ResourceExclusionsSpec{
+			Add: []pipelinesv1beta1.ResourceExclusion{{APIGroups: []string{"example.com"}, Kinds: []string{"Report"}}},
+		},
+	}
+
+	// Existing instance with a user-authored exclusion, created before the exclusions were reconciled
+	existingArgoCD := &argoapp.ArgoCD{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      common.ArgoCDInstanceName,
+			Namespace: serviceNamespace,
+		},
+		Spec: argoapp.ArgoCDSpec{
+			ResourceExclusions: "- apiGroups:\n  - user.example.com\n  kinds:\n  - Widget\n",
+		},
+	}
+
+	fakeClient := fake.NewFakeClient(gitopsService, existingArgoCD)
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	argoCD := &argoapp.ArgoCD{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.Assert(t, strings.Contains(argoCD.Spec.ResourceExclusions, "Widget"), "user-authored exclusions must be kept")
+	assert.Assert(t, strings.Contains(argoCD.Spec.ResourceExclusions, "Report"))
+	assert.Assert(t, strings.Contains(argoCD.Spec.ResourceExclusions, "PipelineRun"))
+	assert.Assert(t, argoCD.Annotations[gitopsargocd.ManagedResourceExclusionsAnnotation] != "")
+}
+
+func TestReconcileDefaultArgoCDFieldConflicts(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	gitopsService := newGitopsService()
+	gitopsService.Spec.DefaultArgoCD = &pipelinesv1beta1.DefaultArgoCDSpec{
+		Profile: pipelinesv1beta1.DefaultArgoCDProfileLarge,
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(gitopsService).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+	assertCondition(t, getGitopsService(t, fakeClient), pipelinesv1beta1.ConditionDefaultArgoCDConflict, v1.ConditionFalse, pipelinesv1beta1.ReasonNoConflict)
+
+	// A user takes over the repo-server replicas
+	argoCD := &argoapp.ArgoCD{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	argoCD.Spec.Repo.Replicas = ptr.To(int32(5))
+	err = fakeClient.Update(context.TODO(), argoCD, client.FieldOwner("kubectl-edit"))
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.Equal(t, *argoCD.Spec.Repo.Replicas, int32(5))
+	assert.Equal(t, argoCD.Annotations[gitopsargocd.ConflictingFieldsAnnotation], ".spec.repo.replicas (kubectl-edit)")
+	assertCondition(t, getGitopsService(t, fakeClient), pipelinesv1beta1.ConditionDefaultArgoCDConflict, v1.ConditionTrue, pipelinesv1beta1.ReasonFieldConflict)
+
+	// Fields configured in the GitopsService CR take precedence over the ones owned by users
+	gitopsService = getGitopsService(t, fakeClient)
+	gitopsService.Spec.DefaultArgoCD.Overrides = &runtime.RawExtension{Raw: []byte(`{"repo":{"replicas":2}}`)}
+	err = fakeClient.Update(context.TODO(), gitopsService)
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.Equal(t, *argoCD.Spec.Repo.Replicas, int32(2))
+	_, found := argoCD.Annotations[gitopsargocd.ConflictingFieldsAnnotation]
+	assert.Assert(t, !found)
+	assertCondition(t, getGitopsService(t, fakeClient), pipelinesv1beta1.ConditionDefaultArgoCDConflict, v1.ConditionFalse, pipelinesv1beta1.ReasonNoConflict)
+}
+
+func TestReconcileDefaultArgoCDLegacyFieldManager(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(newGitopsService()).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).WithReturnManagedFields().Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+
+	// Instance created by a previous version of the operator, without server-side apply
+	existingArgoCD, err := gitopsargocd.NewCR(common.ArgoCDInstanceName, serviceNamespace, fakeClient)
+	assertNoError(t, err)
+	existingArgoCD.Spec.Server.Route.Enabled = false
+	existingArgoCD.Finalizers = []string{"argoproj.io/finalizer"}
+	err = fakeClient.Create(context.TODO(), existingArgoCD, client.FieldOwner("manager"))
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	// The fields applied by the operator are owned by the operator and reconciled, the others are kept
+	argoCD := &argoapp.ArgoCD{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.Assert(t, argoCD.Spec.Server.Route.Enabled)
+	assert.DeepEqual(t, argoCD.Finalizers, []string{"argoproj.io/finalizer"})
+	assert.Equal(t, argoCD.Annotations[gitopsargocd.ManagedFieldsUpgradedAnnotation], "true")
+	_, found := argoCD.Annotations[gitopsargocd.ConflictingFieldsAnnotation]
+	assert.Assert(t, !found)
+
+	// The upgrade runs once, the fields set later with the default field manager are kept
+	argoCD.Spec.Controller.ExtraCommandArgs = []string{"--metrics-application-labels", "team"}
+	err = fakeClient.Update(context.TODO(), argoCD, client.FieldOwner("manager"))
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.DeepEqual(t, argoCD.Spec.Controller.ExtraCommandArgs, []string{"--metrics-application-labels", "team"})
+	assert.DeepEqual(t, argoCD.Finalizers, []string{"argoproj.io/finalizer"})
+}
+
+func TestReconcileDefaultArgoCDMetricsApplicationLabels(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(newGitopsService()).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).WithReturnManagedFields().Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	name := types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}
+	setConfig := func(config string) *argoapp.ArgoCD {
+		t.Helper()
+		argoCD := &argoapp.ArgoCD{}
+		assertNoError(t, fakeClient.Get(context.TODO(), name, argoCD))
+		argoCD.Annotations[monitoringConfigAnnotation] = config
+		assertNoError(t, fakeClient.Update(context.TODO(), argoCD, client.FieldOwner("kubectl-edit")))
+		_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+		assertNoError(t, err)
+		assertNoError(t, fakeClient.Get(context.TODO(), name, argoCD))
+		return argoCD
+	}
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	// The Application labels of the alerts are applied with the other fields of the instance
+	argoCD := setConfig(`{"applicationLabels": ["team", "env"]}`)
+	assert.DeepEqual(t, argoCD.Spec.Controller.ExtraCommandArgs, []string{"--metrics-application-labels", "team,env"})
+	assert.Equal(t, argoCD.Annotations[metricsApplicationLabelsAnnotation], "team,env")
+	_, found := argoCD.Annotations[gitopsargocd.ConflictingFieldsAnnotation]
+	assert.Assert(t, !found)
+
+	// and removed with the configuration
+	argoCD = setConfig(`{}`)
+	assert.Equal(t, len(argoCD.Spec.Controller.ExtraCommandArgs), 0)
+	_, found = argoCD.Annotations[metricsApplicationLabelsAnnotation]
+	assert.Assert(t, !found)
 }
 
 // If the DISABLE_DEFAULT_ARGOCD_INSTANCE is set, ensure that the default ArgoCD instance is not created.
@@ -286,6 +635,213 @@ func TestReconcileDisableDefault_DeleteIfAlreadyExists(t *testing.T) {
 
 }
 
+func TestReconcileDefaultArgoCDUnmanaged(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(newGitopsService()).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	// Release the default instance
+	gitopsService := getGitopsService(t, fakeClient)
+	gitopsService.Spec.DefaultArgoCD = &pipelinesv1beta1.DefaultArgoCDSpec{
+		ManagementState: pipelinesv1beta1.DefaultArgoCDUnmanaged,
+		Profile:         pipelinesv1beta1.DefaultArgoCDProfileLarge,
+	}
+	err = fakeClient.Update(context.TODO(), gitopsService)
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	// The instance is left running without owner, and is no longer reconciled
+	argoCD := &argoapp.ArgoCD{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.Equal(t, len(argoCD.OwnerReferences), 0)
+	assert.Equal(t, argoCD.Spec.HA.Enabled, false)
+	assert.DeepEqual(t, recordedEvents(recorder, "ArgoCDUnmanaged"), []string{"Normal ArgoCDUnmanaged Argo CD instance openshift-gitops/openshift-gitops is no longer managed by the operator and is left running"})
+	assertCondition(t, getGitopsService(t, fakeClient), pipelinesv1beta1.ConditionDefaultArgoCDConflict, v1.ConditionFalse, pipelinesv1beta1.ReasonUnmanaged)
+
+	// Reconciling again does not report the transition twice
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+	assert.Equal(t, len(recordedEvents(recorder, "ArgoCDUnmanaged")), 0)
+
+	// Take the instance over again
+	gitopsService = getGitopsService(t, fakeClient)
+	gitopsService.Spec.DefaultArgoCD.ManagementState = pipelinesv1beta1.DefaultArgoCDManaged
+	err = fakeClient.Update(context.TODO(), gitopsService)
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD)
+	assertNoError(t, err)
+	assert.Assert(t, v1.IsControlledBy(argoCD, gitopsService))
+	assert.Equal(t, argoCD.Spec.HA.Enabled, true)
+	assert.DeepEqual(t, recordedEvents(recorder, "ArgoCDManaged"), []string{"Normal ArgoCDManaged Argo CD instance openshift-gitops/openshift-gitops is managed by the operator"})
+}
+
+func TestReconcileDefaultArgoCDManagementState_Removed(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	gitopsService := newGitopsService()
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(gitopsService).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	gitopsService = getGitopsService(t, fakeClient)
+	gitopsService.Spec.DefaultArgoCD = &pipelinesv1beta1.DefaultArgoCDSpec{
+		ManagementState: pipelinesv1beta1.DefaultArgoCDRemoved,
+	}
+	err = fakeClient.Update(context.TODO(), gitopsService)
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, &argoapp.ArgoCD{})
+	assert.Check(t, errors.IsNotFound(err))
+	assert.DeepEqual(t, recordedEvents(recorder, "ArgoCDRemoved"), []string{"Normal ArgoCDRemoved Argo CD instance openshift-gitops/openshift-gitops was deleted because the default Argo CD instance is disabled"})
+	assertCondition(t, getGitopsService(t, fakeClient), pipelinesv1beta1.ConditionDefaultArgoCDAvailable, v1.ConditionFalse, pipelinesv1beta1.ReasonDisabled)
+
+	// The management state of the GitopsService takes precedence over DISABLE_DEFAULT_ARGOCD_INSTANCE
+	reconciler.DisableDefaultInstall = true
+	gitopsService = getGitopsService(t, fakeClient)
+	gitopsService.Spec.DefaultArgoCD.ManagementState = pipelinesv1beta1.DefaultArgoCDManaged
+	err = fakeClient.Update(context.TODO(), gitopsService)
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, &argoapp.ArgoCD{})
+	assertNoError(t, err)
+}
+
+func TestReconcileArgoCDInstances(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+	t.Setenv(util.ClusterConfigNamespacesEnvVar, "")
+	defer util.SetConfiguredClusterConfigNamespaces(strings.Join(util.ClusterConfigNamespaces(), ","))
+	util.SetConfiguredClusterConfigNamespaces("")
+
+	gitopsService := newGitopsService()
+	gitopsService.Spec.Instances = []pipelinesv1beta1.ArgoCDInstanceSpec{
+		{
+			Name:              "cluster-config",
+			Namespace:         "cluster-config",
+			ClusterConfig:     true,
+			DefaultArgoCDSpec: pipelinesv1beta1.DefaultArgoCDSpec{Profile: pipelinesv1beta1.DefaultArgoCDProfileLarge},
+		},
+		{
+			Name:      "tenants",
+			Namespace: "tenants",
+			DefaultArgoCDSpec: pipelinesv1beta1.DefaultArgoCDSpec{
+				Overrides: &runtime.RawExtension{Raw: []byte(`{"server":{"replicas":2}}`)},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(gitopsService).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	gitopsService = getGitopsService(t, fakeClient)
+	clusterConfig := &argoapp.ArgoCD{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster-config", Namespace: "cluster-config"}, clusterConfig)
+	assertNoError(t, err)
+	assert.Assert(t, v1.IsControlledBy(clusterConfig, gitopsService))
+	assert.Equal(t, clusterConfig.Spec.HA.Enabled, true)
+	tenants := &argoapp.ArgoCD{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "tenants", Namespace: "tenants"}, tenants)
+	assertNoError(t, err)
+	assert.Equal(t, *tenants.Spec.Server.Replicas, int32(2))
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "tenants"}, &corev1.Namespace{})
+	assertNoError(t, err)
+	assert.DeepEqual(t, util.ClusterConfigNamespaces(), []string{"cluster-config"})
+
+	// The instances are annotated when their privileges are revoked or granted, so that they are reconciled again
+	setClusterConfig := func(enabled bool) {
+		t.Helper()
+		gitopsService = getGitopsService(t, fakeClient)
+		gitopsService.Spec.Instances[0].ClusterConfig = enabled
+		assertNoError(t, fakeClient.Update(context.TODO(), gitopsService))
+		_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+		assertNoError(t, err)
+		assertNoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster-config", Namespace: "cluster-config"}, clusterConfig))
+	}
+	setClusterConfig(false)
+	assert.Equal(t, clusterConfig.Annotations[gitopsargocd.ClusterConfigAnnotation], "false")
+	assert.DeepEqual(t, util.ClusterConfigNamespaces(), []string(nil))
+	setClusterConfig(true)
+	assert.Equal(t, clusterConfig.Annotations[gitopsargocd.ClusterConfigAnnotation], "true")
+	assert.DeepEqual(t, util.ClusterConfigNamespaces(), []string{"cluster-config"})
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "tenants", Namespace: "tenants"}, tenants)
+	assertNoError(t, err)
+	_, found := tenants.Annotations[gitopsargocd.ClusterConfigAnnotation]
+	assert.Assert(t, !found)
+
+	// The privileges are granted again after a restart of the operator, before the GitopsService is reconciled
+	util.SetConfiguredClusterConfigNamespaces("")
+	assertNoError(t, InitClusterConfigNamespaces(context.TODO(), fakeClient))
+	assert.DeepEqual(t, util.ClusterConfigNamespaces(), []string{"cluster-config"})
+
+	// Instances no longer listed are deleted, unmanaged instances are released and keep their privileges
+	gitopsService = getGitopsService(t, fakeClient)
+	gitopsService.Spec.Instances = gitopsService.Spec.Instances[:1]
+	gitopsService.Spec.Instances[0].ManagementState = pipelinesv1beta1.DefaultArgoCDUnmanaged
+	err = fakeClient.Update(context.TODO(), gitopsService)
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "tenants", Namespace: "tenants"}, tenants)
+	assert.Check(t, errors.IsNotFound(err))
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster-config", Namespace: "cluster-config"}, clusterConfig)
+	assertNoError(t, err)
+	assert.Equal(t, len(clusterConfig.OwnerReferences), 0)
+	assert.DeepEqual(t, util.ClusterConfigNamespaces(), []string{"cluster-config"})
+	events := recordedEvents(recorder, "ArgoCDRemoved", "ArgoCDUnmanaged")
+	sort.Strings(events)
+	assert.DeepEqual(t, events, []string{
+		"Normal ArgoCDRemoved Argo CD instance tenants/tenants was deleted because it is no longer listed in spec.instances",
+		"Normal ArgoCDUnmanaged Argo CD instance cluster-config/cluster-config is no longer managed by the operator and is left running",
+	})
+
+	// The privileges are revoked once the instance is no longer listed
+	gitopsService = getGitopsService(t, fakeClient)
+	gitopsService.Spec.Instances = nil
+	err = fakeClient.Update(context.TODO(), gitopsService)
+	assertNoError(t, err)
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	assert.DeepEqual(t, util.ClusterConfigNamespaces(), []string(nil))
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster-config", Namespace: "cluster-config"}, clusterConfig)
+	assertNoError(t, err)
+}
+
 func TestReconcile(t *testing.T) {
 	defer util.SetConsoleAPIFound(util.IsConsoleAPIFound())
 	util.SetConsoleAPIFound(true)
@@ -465,6 +1021,63 @@ func TestReconcile_ocpVersionLowerThan4_15(t *testing.T) {
 	assert.Error(t, err, "configmaps \"httpd-cfg\" not found")
 }
 
+func TestReconcile_Events(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(util.NewClusterVersion("4.11.1"), newGitopsService()).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated", "Skipped"), []string{
+		"Normal Created Created Namespace openshift-gitops",
+		"Normal Created Created ArgoCD openshift-gitops/openshift-gitops",
+		"Normal Created Created ServiceAccount openshift-gitops/gitops-service-cluster",
+		"Normal Created Created ClusterRole gitops-service-cluster",
+		"Normal Created Created ClusterRoleBinding gitops-service-cluster",
+		"Normal Created Created PodDisruptionBudget openshift-gitops/cluster",
+		"Normal Created Created Deployment openshift-gitops/cluster",
+		"Normal Created Created Service openshift-gitops/cluster",
+		"Normal Created Created NetworkPolicy openshift-gitops/cluster-network-policy",
+		"Normal Skipped Console plugin is not reconciled: dynamic plugins are not supported by the cluster version",
+	})
+
+	// Unchanged resources and components that are still skipped are not reported
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+	assert.DeepEqual(t, recordedEvents(recorder, "Created", "Updated", "Skipped"), []string(nil))
+}
+
+func TestReconcile_EventsOnFailure(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(util.NewClusterVersion("4.11.1"), newGitopsService()).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if _, ok := obj.(*appsv1.Deployment); ok {
+					return fmt.Errorf("admission denied")
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assert.Error(t, err, "admission denied")
+	assert.DeepEqual(t, recordedEvents(recorder, "CreateFailed"), []string{
+		"Warning CreateFailed Failed to create Deployment openshift-gitops/cluster: admission denied",
+	})
+}
+
 func TestReconcile_GitOpsNamespace(t *testing.T) {
 	logf.SetLogger(argocd.ZapLogger(true))
 	s := scheme.Scheme
@@ -679,13 +1292,18 @@ func TestReconcile_InfrastructureNode(t *testing.T) {
 	logf.SetLogger(argocd.ZapLogger(true))
 	s := scheme.Scheme
 	addKnownTypesToScheme(s)
-	gitopsService := &pipelinesv1alpha1.GitopsService{
+	gitopsService := &pipelinesv1beta1.GitopsService{
 		ObjectMeta: v1.ObjectMeta{
 			Name: serviceName,
 		},
-		Spec: pipelinesv1alpha1.GitopsServiceSpec{
-			RunOnInfra:  true,
-			Tolerations: deploymentDefaultTolerations(),
+		Spec: pipelinesv1beta1.GitopsServiceSpec{
+			RunOnInfra: true,
+			DefaultArgoCD: &pipelinesv1beta1.DefaultArgoCDSpec{
+				Tolerations: deploymentDefaultTolerations(),
+			},
+			Backend: &pipelinesv1beta1.BackendSpec{
+				Tolerations: deploymentDefaultTolerations(),
+			},
 		},
 	}
 	fakeClient := fake.NewFakeClient(gitopsService)
@@ -721,11 +1339,11 @@ func TestReconcile_InfrastructureNode_AnnotationRemoved(t *testing.T) {
 	logf.SetLogger(argocd.ZapLogger(true))
 	s := scheme.Scheme
 	addKnownTypesToScheme(s)
-	gitopsService := &pipelinesv1alpha1.GitopsService{
+	gitopsService := &pipelinesv1beta1.GitopsService{
 		ObjectMeta: v1.ObjectMeta{
 			Name: serviceName,
 		},
-		Spec: pipelinesv1alpha1.GitopsServiceSpec{
+		Spec: pipelinesv1beta1.GitopsServiceSpec{
 			RunOnInfra: true,
 		},
 	}
@@ -851,8 +1469,8 @@ func TestReconcileBackend_ResourceRequestsAndLimits(t *testing.T) {
 	addKnownTypesToScheme(s)
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{
-		Spec: pipelinesv1alpha1.GitopsServiceSpec{},
+	instance := &pipelinesv1beta1.GitopsService{
+		Spec: pipelinesv1beta1.GitopsServiceSpec{},
 	}
 	Resources := &corev1.ResourceRequirements{
 		Requests: corev1.ResourceList{
@@ -864,13 +1482,11 @@ func TestReconcileBackend_ResourceRequestsAndLimits(t *testing.T) {
 			corev1.ResourceCPU:    resourcev1.MustParse("5"),
 		},
 	}
-	instance.Spec.ConsolePlugin = &pipelinesv1alpha1.ConsolePluginStruct{
-		Backend: &pipelinesv1alpha1.BackendStruct{
-			Resources: Resources,
-		},
-		GitopsPlugin: &pipelinesv1alpha1.GitopsPluginStruct{
-			Resources: Resources,
-		},
+	instance.Spec.Backend = &pipelinesv1beta1.BackendSpec{
+		Resources: Resources,
+	}
+	instance.Spec.ConsolePlugin = &pipelinesv1beta1.ConsolePluginSpec{
+		Resources: Resources,
 	}
 
 	gitopsserviceNamespacedName := types.NamespacedName{
@@ -891,6 +1507,168 @@ func TestReconcileBackend_ResourceRequestsAndLimits(t *testing.T) {
 	assert.Equal(t, deployment.Spec.Template.Spec.Containers[0].Resources.Limits["cpu"], resourcev1.MustParse("5"))
 }
 
+func TestReconcileBackend_HighAvailability(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	instance := newGitopsService()
+
+	gitopsserviceNamespacedName := types.NamespacedName{
+		Name:      serviceName,
+		Namespace: serviceNamespace,
+	}
+	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
+	_, err := reconciler.reconcileBackend(gitopsserviceNamespacedName, instance, reqLogger)
+	assertNoError(t, err)
+
+	pdb := &policyv1.PodDisruptionBudget{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: serviceName, Namespace: serviceNamespace}, pdb)
+	assertNoError(t, err)
+	assert.DeepEqual(t, pdb.Spec.MaxUnavailable, ptr.To(intstr.FromInt32(1)))
+	assert.DeepEqual(t, pdb.Spec.Selector.MatchLabels, map[string]string{"app.kubernetes.io/name": serviceName})
+
+	// verify whether the existing backend deployment is scaled and spread with the user defined values
+	affinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      "kubernetes.io/arch",
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{"amd64"},
+					}},
+				}},
+			},
+		},
+	}
+	instance.Spec.Backend = &pipelinesv1beta1.BackendSpec{
+		Replicas: ptr.To(int32(2)),
+		Affinity: affinity,
+	}
+	_, err = reconciler.reconcileBackend(gitopsserviceNamespacedName, instance, reqLogger)
+	assertNoError(t, err)
+
+	deployment := &appsv1.Deployment{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: serviceName, Namespace: serviceNamespace}, deployment)
+	assertNoError(t, err)
+	assert.Equal(t, *deployment.Spec.Replicas, int32(2))
+	assert.DeepEqual(t, deployment.Spec.Template.Spec.Affinity, affinity)
+
+	// the default anti-affinity applies when no scheduling constraint is set
+	instance.Spec.Backend.Affinity = nil
+	_, err = reconciler.reconcileBackend(gitopsserviceNamespacedName, instance, reqLogger)
+	assertNoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: serviceName, Namespace: serviceNamespace}, deployment)
+	assertNoError(t, err)
+	assert.Assert(t, deployment.Spec.Template.Spec.Affinity.PodAntiAffinity != nil)
+}
+
+func TestReconcileBackend_ServiceDrift(t *testing.T) {
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	instance := newGitopsService()
+
+	name := types.NamespacedName{Name: serviceName, Namespace: serviceNamespace}
+	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
+	_, err := reconciler.reconcileBackend(name, instance, reqLogger)
+	assertNoError(t, err)
+
+	// the annotations added by the service CA operator are not a drift
+	service := &corev1.Service{}
+	assertNoError(t, fakeClient.Get(context.TODO(), name, service))
+	service.Annotations["service.alpha.openshift.io/serving-cert-signed-by"] = "openshift-service-serving-signer"
+	assertNoError(t, fakeClient.Update(context.TODO(), service))
+	resourceVersion := service.ResourceVersion
+	recordedEvents(recorder)
+	_, err = reconciler.reconcileBackend(name, instance, reqLogger)
+	assertNoError(t, err)
+	assertNoError(t, fakeClient.Get(context.TODO(), name, service))
+	assert.Equal(t, service.ResourceVersion, resourceVersion)
+
+	// the changes made to the fields set by the operator are reverted
+	service.Annotations["service.beta.openshift.io/serving-cert-secret-name"] = "other"
+	service.Spec.Selector = map[string]string{"app.kubernetes.io/name": "other"}
+	service.Spec.Ports[0].TargetPort = intstr.FromInt32(9090)
+	assertNoError(t, fakeClient.Update(context.TODO(), service))
+	_, err = reconciler.reconcileBackend(name, instance, reqLogger)
+	assertNoError(t, err)
+
+	assertNoError(t, fakeClient.Get(context.TODO(), name, service))
+	desired := newBackendService(name)
+	assert.DeepEqual(t, service.Spec.Selector, desired.Spec.Selector)
+	assert.DeepEqual(t, service.Spec.Ports, desired.Spec.Ports)
+	assert.DeepEqual(t, service.Annotations, map[string]string{
+		"service.beta.openshift.io/serving-cert-secret-name": serviceName,
+		"service.alpha.openshift.io/serving-cert-signed-by":  "openshift-service-serving-signer",
+	})
+	assert.DeepEqual(t, recordedEvents(recorder, "Updated"), []string{
+		"Normal Updated Updated Service openshift-gitops/cluster: reverted changes to annotations, selector, ports",
+	})
+}
+
+func TestReconcileBackend_RBACDrift(t *testing.T) {
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+	instance := newGitopsService()
+
+	name := types.NamespacedName{Name: serviceName, Namespace: serviceNamespace}
+	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
+	_, err := reconciler.reconcileBackend(name, instance, reqLogger)
+	assertNoError(t, err)
+
+	rbacName := types.NamespacedName{Name: gitopsServicePrefix + serviceName}
+	desired := newClusterRoleBinding(name)
+
+	// extra subjects and removed owner references are reverted
+	binding := &rbacv1.ClusterRoleBinding{}
+	assertNoError(t, fakeClient.Get(context.TODO(), rbacName, binding))
+	binding.Subjects = append(binding.Subjects, rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "default", Namespace: "default"})
+	binding.OwnerReferences = nil
+	assertNoError(t, fakeClient.Update(context.TODO(), binding))
+	serviceAccount := &corev1.ServiceAccount{}
+	assertNoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: rbacName.Name, Namespace: serviceNamespace}, serviceAccount))
+	serviceAccount.OwnerReferences = nil
+	assertNoError(t, fakeClient.Update(context.TODO(), serviceAccount))
+	recordedEvents(recorder)
+
+	_, err = reconciler.reconcileBackend(name, instance, reqLogger)
+	assertNoError(t, err)
+	assertNoError(t, fakeClient.Get(context.TODO(), rbacName, binding))
+	assert.DeepEqual(t, binding.Subjects, desired.Subjects)
+	assert.Assert(t, v1.IsControlledBy(binding, instance))
+	assertNoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: rbacName.Name, Namespace: serviceNamespace}, serviceAccount))
+	assert.Assert(t, v1.IsControlledBy(serviceAccount, instance))
+	assert.DeepEqual(t, recordedEvents(recorder, "Updated"), []string{
+		"Normal Updated Updated ServiceAccount openshift-gitops/gitops-service-cluster: reverted changes to ownerReferences",
+		"Normal Updated Updated ClusterRoleBinding gitops-service-cluster: reverted changes to subjects, ownerReferences",
+	})
+
+	// a changed roleRef recreates the binding
+	assertNoError(t, fakeClient.Delete(context.TODO(), binding))
+	binding = newClusterRoleBinding(name)
+	binding.RoleRef.Name = "cluster-admin"
+	assertNoError(t, fakeClient.Create(context.TODO(), binding))
+
+	_, err = reconciler.reconcileBackend(name, instance, reqLogger)
+	assertNoError(t, err)
+	assertNoError(t, fakeClient.Get(context.TODO(), rbacName, binding))
+	assert.DeepEqual(t, binding.RoleRef, desired.RoleRef)
+	assert.Assert(t, v1.IsControlledBy(binding, instance))
+	assert.DeepEqual(t, recordedEvents(recorder, "Deleted", "Created"), []string{
+		"Normal Deleted Deleted ClusterRoleBinding gitops-service-cluster",
+		"Normal Created Created ClusterRoleBinding gitops-service-cluster",
+	})
+}
+
 // TestReconcileBackend_ModifyExistingValuesOfResourceRequestsAndLimits tests whether backend deployment is updated with new resource requests and limits
 func TestReconcileBackend_ModifyExistingValuesOfResourceRequestsAndLimits(t *testing.T) {
 
@@ -899,8 +1677,8 @@ func TestReconcileBackend_ModifyExistingValuesOfResourceRequestsAndLimits(t *tes
 	addKnownTypesToScheme(s)
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{
-		Spec: pipelinesv1alpha1.GitopsServiceSpec{},
+	instance := &pipelinesv1beta1.GitopsService{
+		Spec: pipelinesv1beta1.GitopsServiceSpec{},
 	}
 	Resources := &corev1.ResourceRequirements{
 		Requests: corev1.ResourceList{
@@ -912,13 +1690,11 @@ func TestReconcileBackend_ModifyExistingValuesOfResourceRequestsAndLimits(t *tes
 			corev1.ResourceCPU:    resourcev1.MustParse("5"),
 		},
 	}
-	instance.Spec.ConsolePlugin = &pipelinesv1alpha1.ConsolePluginStruct{
-		Backend: &pipelinesv1alpha1.BackendStruct{
-			Resources: Resources,
-		},
-		GitopsPlugin: &pipelinesv1alpha1.GitopsPluginStruct{
-			Resources: Resources,
-		},
+	instance.Spec.Backend = &pipelinesv1beta1.BackendSpec{
+		Resources: Resources,
+	}
+	instance.Spec.ConsolePlugin = &pipelinesv1beta1.ConsolePluginSpec{
+		Resources: Resources,
 	}
 	gitopsserviceNamespacedName := types.NamespacedName{
 		Name:      serviceName,
@@ -948,13 +1724,11 @@ func TestReconcileBackend_ModifyExistingValuesOfResourceRequestsAndLimits(t *tes
 			corev1.ResourceCPU:    resourcev1.MustParse("400m"),
 		},
 	}
-	instance.Spec.ConsolePlugin = &pipelinesv1alpha1.ConsolePluginStruct{
-		Backend: &pipelinesv1alpha1.BackendStruct{
-			Resources: updatedResource,
-		},
-		GitopsPlugin: &pipelinesv1alpha1.GitopsPluginStruct{
-			Resources: updatedResource,
-		},
+	instance.Spec.Backend = &pipelinesv1beta1.BackendSpec{
+		Resources: updatedResource,
+	}
+	instance.Spec.ConsolePlugin = &pipelinesv1beta1.ConsolePluginSpec{
+		Resources: updatedResource,
 	}
 	_, err = reconciler.reconcileBackend(gitopsserviceNamespacedName, instance, reqLogger)
 	assertNoError(t, err)
@@ -974,7 +1748,7 @@ func TestReconcileBackend_DefaultRequestsAndLimits(t *testing.T) {
 	addKnownTypesToScheme(s)
 	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
 	reconciler := newReconcileGitOpsService(fakeClient, s)
-	instance := &pipelinesv1alpha1.GitopsService{}
+	instance := &pipelinesv1beta1.GitopsService{}
 	gitopsserviceNamespacedName := types.NamespacedName{
 		Name:      serviceName,
 		Namespace: serviceNamespace,
@@ -1003,7 +1777,7 @@ func TestCleanKAMResources_NoResourcesExist(t *testing.T) {
 	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
 
 	// No KAM resources exist - function should be a silent no-op
-	reconciler.cleanKAMResources(context.TODO(), reqLogger)
+	reconciler.cleanKAMResources(context.TODO(), newGitopsService(), reqLogger)
 }
 
 // Deployment exist
@@ -1019,7 +1793,7 @@ func TestCleanKAMResources_DeploymentExist(t *testing.T) {
 	reconciler := newReconcileGitOpsService(fakeClient, s)
 	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
 
-	reconciler.cleanKAMResources(context.TODO(), reqLogger)
+	reconciler.cleanKAMResources(context.TODO(), newGitopsService(), reqLogger)
 
 	err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: kamResourceName, Namespace: serviceNamespace}, &appsv1.Deployment{})
 	if !errors.IsNotFound(err) {
@@ -1039,7 +1813,7 @@ func TestCleanKAMResources_ServiceExist(t *testing.T) {
 	reconciler := newReconcileGitOpsService(fakeClient, s)
 	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
 
-	reconciler.cleanKAMResources(context.TODO(), reqLogger)
+	reconciler.cleanKAMResources(context.TODO(), newGitopsService(), reqLogger)
 
 	err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: kamResourceName, Namespace: serviceNamespace}, &corev1.Service{})
 	if !errors.IsNotFound(err) {
@@ -1061,7 +1835,7 @@ func TestCleanKAMResources_RouteExist(t *testing.T) {
 	reconciler := newReconcileGitOpsService(fakeClient, s)
 	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
 
-	reconciler.cleanKAMResources(context.TODO(), reqLogger)
+	reconciler.cleanKAMResources(context.TODO(), newGitopsService(), reqLogger)
 
 	err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: kamResourceName, Namespace: serviceNamespace}, &routev1.Route{})
 	if !errors.IsNotFound(err) {
@@ -1089,8 +1863,13 @@ func TestCleanKAMResources_AllResourcesExist(t *testing.T) {
 	reconciler := newReconcileGitOpsService(fakeClient, s)
 	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
 
-	reconciler.cleanKAMResources(context.TODO(), reqLogger)
+	reconciler.cleanKAMResources(context.TODO(), newGitopsService(), reqLogger)
 
+	assert.DeepEqual(t, recordedEvents(reconciler.Recorder.(*record.FakeRecorder), "Deleted"), []string{
+		"Normal Deleted Deleted Deployment openshift-gitops/kam",
+		"Normal Deleted Deleted Service openshift-gitops/kam",
+		"Normal Deleted Deleted Route openshift-gitops/kam",
+	})
 	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: kamResourceName, Namespace: serviceNamespace}, &appsv1.Deployment{}); !errors.IsNotFound(err) {
 		t.Fatalf("expected KAM Deployment to be deleted , got err: %v", err)
 	}
@@ -1115,17 +1894,17 @@ func TestCleanKAMResources_Idempotent(t *testing.T) {
 	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
 
 	// First call - deletes the KAM resource
-	reconciler.cleanKAMResources(context.TODO(), reqLogger)
+	reconciler.cleanKAMResources(context.TODO(), newGitopsService(), reqLogger)
 
 	// Second call - must not panic even if the resources are already deleted
-	reconciler.cleanKAMResources(context.TODO(), reqLogger)
+	reconciler.cleanKAMResources(context.TODO(), newGitopsService(), reqLogger)
 
 }
 
 func addKnownTypesToScheme(scheme *runtime.Scheme) {
 	scheme.AddKnownTypes(configv1.GroupVersion, &configv1.ClusterVersion{})
-	scheme.AddKnownTypes(pipelinesv1alpha1.GroupVersion, &pipelinesv1alpha1.GitopsService{})
-	scheme.AddKnownTypes(argoapp.GroupVersion, &argoapp.ArgoCD{})
+	scheme.AddKnownTypes(pipelinesv1beta1.GroupVersion, &pipelinesv1beta1.GitopsService{})
+	scheme.AddKnownTypes(argoapp.GroupVersion, &argoapp.ArgoCD{}, &argoapp.ArgoCDList{})
 	scheme.AddKnownTypes(consolev1.GroupVersion, &consolev1.ConsoleCLIDownload{})
 	scheme.AddKnownTypes(routev1.GroupVersion, &routev1.Route{})
 	scheme.AddKnownTypes(consolev1.GroupVersion, &consolev1.ConsolePlugin{})
@@ -1133,8 +1912,25 @@ func addKnownTypesToScheme(scheme *runtime.Scheme) {
 
 func newReconcileGitOpsService(client client.Client, scheme *runtime.Scheme) *ReconcileGitopsService {
 	return &ReconcileGitopsService{
-		Client: client,
-		Scheme: scheme,
+		Client:     client,
+		Scheme:     scheme,
+		Recorder:   record.NewFakeRecorder(100),
+		skipEvents: newSkipEvents(),
+	}
+}
+
+// recordedEvents drains the events recorded by recorder and returns the ones with one of the given reasons
+func recordedEvents(recorder *record.FakeRecorder, reasons ...string) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			if fields := strings.Fields(event); len(fields) > 1 && slices.Contains(reasons, fields[1]) {
+				events = append(events, event)
+			}
+		default:
+			return events
+		}
 	}
 }
 
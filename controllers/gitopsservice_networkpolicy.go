@@ -0,0 +1,118 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	networkPolicySuffix = "-network-policy"
+	// consoleNamespace is the namespace of the OpenShift console, which proxies the requests to the plugin and backend
+	consoleNamespace = "openshift-console"
+	// policyGroupLabel selects the namespaces of the OpenShift monitoring stack
+	policyGroupLabel           = "network.openshift.io/policy-group"
+	policyGroupLabelMonitoring = "monitoring"
+)
+
+// networkPolicyDisabled returns true if the NetworkPolicies of the backend and console plugin are disabled
+func networkPolicyDisabled(instance *pipelinesv1beta1.GitopsService) bool {
+	return instance.Spec.NetworkPolicy != nil && instance.Spec.NetworkPolicy.Disabled
+}
+
+// newIngressNetworkPolicy returns a NetworkPolicy that only allows ingress traffic to the given port of the pods
+// matching podLabels from the OpenShift console and the monitoring stack
+func newIngressNetworkPolicy(name, namespace string, podLabels map[string]string, port int32) *networkingv1.NetworkPolicy {
+	protocol := corev1.ProtocolTCP
+	targetPort := intstr.FromInt32(port)
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: objectMeta(name+networkPolicySuffix, namespace),
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: podLabels,
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{corev1.LabelMetadataName: consoleNamespace},
+							},
+						},
+						{
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{policyGroupLabel: policyGroupLabelMonitoring},
+							},
+						},
+					},
+					Ports: []networkingv1.NetworkPolicyPort{
+						{
+							Protocol: &protocol,
+							Port:     &targetPort,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// reconcileNetworkPolicy creates or updates the given NetworkPolicy, owned by the GitopsService, or deletes it
+// when the NetworkPolicies are disabled
+func (r *ReconcileGitopsService) reconcileNetworkPolicy(instance *pipelinesv1beta1.GitopsService, policy *networkingv1.NetworkPolicy,
+	reqLogger logr.Logger) error {
+	existing := &networkingv1.NetworkPolicy{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+
+	if networkPolicyDisabled(instance) {
+		if !found || !metav1.IsControlledBy(existing, instance) {
+			return nil
+		}
+		reqLogger.Info("Deleting NetworkPolicy, NetworkPolicies are disabled", "Namespace", existing.Namespace, "Name", existing.Name)
+		return recordResourceEvent(r.Recorder, instance, resourceDelete, existing, r.Client.Delete(context.TODO(), existing))
+	}
+
+	if err := controllerutil.SetControllerReference(instance, policy, r.Scheme); err != nil {
+		return err
+	}
+	if !found {
+		reqLogger.Info("Creating a new NetworkPolicy", "Namespace", policy.Namespace, "Name", policy.Name)
+		return recordResourceEvent(r.Recorder, instance, resourceCreate, policy, r.Client.Create(context.TODO(), policy))
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, policy.Spec) {
+		return nil
+	}
+	reqLogger.Info("Reconciling existing NetworkPolicy", "Namespace", existing.Namespace, "Name", existing.Name)
+	existing.Spec = policy.Spec
+	return recordResourceEvent(r.Recorder, instance, resourceUpdate, existing, r.Client.Update(context.TODO(), existing))
+}
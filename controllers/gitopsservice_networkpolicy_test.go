@@ -0,0 +1,100 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	"github.com/redhat-developer/gitops-operator/controllers/util"
+	"gotest.tools/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileNetworkPolicies(t *testing.T) {
+	defer util.SetConsoleAPIFound(util.IsConsoleAPIFound())
+	util.SetConsoleAPIFound(true)
+
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+	instance := newGitopsService()
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(instance).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	recorder := reconciler.Recorder.(*record.FakeRecorder)
+
+	backendName := types.NamespacedName{Name: serviceName, Namespace: serviceNamespace}
+	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
+	reconcileAll := func() {
+		t.Helper()
+		_, err := reconciler.reconcileBackend(backendName, instance, reqLogger)
+		assertNoError(t, err)
+		_, err = reconciler.reconcilePlugin(instance, newRequest("test", "test"))
+		assertNoError(t, err)
+	}
+	reconcileAll()
+
+	tests := []struct {
+		name      string
+		podLabels map[string]string
+		port      int
+	}{
+		{name: "cluster-network-policy", podLabels: map[string]string{"app.kubernetes.io/name": serviceName}, port: 8080},
+		{name: "gitops-plugin-network-policy", podLabels: map[string]string{kubeAppLabelApp: gitopsPluginName}, port: 9001},
+	}
+	for _, test := range tests {
+		policy := &networkingv1.NetworkPolicy{}
+		err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: test.name, Namespace: serviceNamespace}, policy)
+		assertNoError(t, err)
+		assert.DeepEqual(t, policy.Spec.PodSelector.MatchLabels, test.podLabels)
+		assert.DeepEqual(t, policy.Spec.PolicyTypes, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress})
+		assert.Equal(t, len(policy.Spec.Ingress), 1)
+		assert.DeepEqual(t, *policy.Spec.Ingress[0].Ports[0].Port, intstr.FromInt(test.port))
+		assert.DeepEqual(t, policy.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels, map[string]string{"kubernetes.io/metadata.name": "openshift-console"})
+		assert.DeepEqual(t, policy.Spec.Ingress[0].From[1].NamespaceSelector.MatchLabels, map[string]string{"network.openshift.io/policy-group": "monitoring"})
+		assert.Equal(t, policy.OwnerReferences[0].Kind, "GitopsService")
+	}
+
+	// Changes made to the policies are reverted
+	policy := &networkingv1.NetworkPolicy{}
+	assertNoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster-network-policy", Namespace: serviceNamespace}, policy))
+	policy.Spec.Ingress = append(policy.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{})
+	assertNoError(t, fakeClient.Update(context.TODO(), policy))
+	recordedEvents(recorder)
+	reconcileAll()
+	assertNoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "cluster-network-policy", Namespace: serviceNamespace}, policy))
+	assert.Equal(t, len(policy.Spec.Ingress), 1)
+	assert.DeepEqual(t, recordedEvents(recorder, "Updated"), []string{"Normal Updated Updated NetworkPolicy openshift-gitops/cluster-network-policy"})
+
+	// The policies are deleted when they are disabled
+	instance.Spec.NetworkPolicy = &pipelinesv1beta1.NetworkPolicySpec{Disabled: true}
+	reconcileAll()
+	for _, test := range tests {
+		err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: test.name, Namespace: serviceNamespace}, &networkingv1.NetworkPolicy{})
+		assert.Assert(t, errors.IsNotFound(err), "NetworkPolicy %s was not deleted: %v", test.name, err)
+	}
+	assert.DeepEqual(t, recordedEvents(recorder, "Deleted"), []string{
+		"Normal Deleted Deleted NetworkPolicy openshift-gitops/cluster-network-policy",
+		"Normal Deleted Deleted NetworkPolicy openshift-gitops/gitops-plugin-network-policy",
+	})
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+// overriddenAnnotationsAnnotation lists, on a Deployment, the pod template annotations set from the
+// podTemplateOverrides of the GitopsService, so that the annotations removed from the overrides are removed
+// from the pod template while the ones set by other controllers are kept
+const overriddenAnnotationsAnnotation = "pipelines.openshift.io/pod-template-annotations"
+
+// applyPodTemplateOverrides merges the given overrides into the pod template of the deployment. The first container
+// of the pod template is the workload container; the labels, annotations and containers set by the operator are kept.
+func applyPodTemplateOverrides(deployment *appsv1.Deployment, overrides *pipelinesv1beta1.PodTemplateOverrides) {
+	if overrides == nil {
+		return
+	}
+	template := &deployment.Spec.Template
+
+	for key, value := range overrides.Labels {
+		if _, ok := template.Labels[key]; !ok {
+			if template.Labels == nil {
+				template.Labels = map[string]string{}
+			}
+			template.Labels[key] = value
+		}
+	}
+
+	var annotations []string
+	for key, value := range overrides.Annotations {
+		if _, ok := template.Annotations[key]; !ok {
+			if template.Annotations == nil {
+				template.Annotations = map[string]string{}
+			}
+			template.Annotations[key] = value
+			annotations = append(annotations, key)
+		}
+	}
+	if len(annotations) > 0 {
+		sort.Strings(annotations)
+		if deployment.Annotations == nil {
+			deployment.Annotations = map[string]string{}
+		}
+		deployment.Annotations[overriddenAnnotationsAnnotation] = strings.Join(annotations, ",")
+	}
+
+	if overrides.PriorityClassName != "" {
+		template.Spec.PriorityClassName = overrides.PriorityClassName
+	}
+
+	container := &template.Spec.Containers[0]
+	for _, env := range overrides.Env {
+		env = *env.DeepCopy()
+		setEnvVarDefaults(&env)
+		container.Env = mergeEnvVar(container.Env, env)
+	}
+	for _, mount := range overrides.VolumeMounts {
+		container.VolumeMounts = append(container.VolumeMounts, *mount.DeepCopy())
+	}
+
+	for _, volume := range overrides.Volumes {
+		volume = *volume.DeepCopy()
+		setVolumeDefaults(&volume)
+		replaced := false
+		for i := range template.Spec.Volumes {
+			if template.Spec.Volumes[i].Name == volume.Name {
+				template.Spec.Volumes[i] = volume
+				replaced = true
+			}
+		}
+		if !replaced {
+			template.Spec.Volumes = append(template.Spec.Volumes, volume)
+		}
+	}
+
+	for _, sidecar := range overrides.Sidecars {
+		if sidecar.Name == container.Name {
+			continue
+		}
+		sidecar = *sidecar.DeepCopy()
+		setContainerDefaults(&sidecar)
+		template.Spec.Containers = append(template.Spec.Containers, sidecar)
+	}
+}
+
+// mergeEnvVar replaces the variable of the same name as env in vars, or appends env to vars
+func mergeEnvVar(vars []corev1.EnvVar, env corev1.EnvVar) []corev1.EnvVar {
+	for i := range vars {
+		if vars[i].Name == env.Name {
+			vars[i] = env
+			return vars
+		}
+	}
+	return append(vars, env)
+}
+
+// reconcileOverriddenAnnotations updates the pod template annotations of existing that are set from the
+// podTemplateOverrides, as listed in desired, and returns true if existing changed. The annotations set by the
+// operator itself are reconciled by the callers.
+func reconcileOverriddenAnnotations(existing, desired *appsv1.Deployment) bool {
+	changed := false
+	wanted := splitAnnotationKeys(desired.Annotations[overriddenAnnotationsAnnotation])
+	for _, key := range splitAnnotationKeys(existing.Annotations[overriddenAnnotationsAnnotation]) {
+		if _, ok := existing.Spec.Template.Annotations[key]; ok && !slices.Contains(wanted, key) {
+			delete(existing.Spec.Template.Annotations, key)
+			changed = true
+		}
+	}
+	for _, key := range wanted {
+		value := desired.Spec.Template.Annotations[key]
+		if current, ok := existing.Spec.Template.Annotations[key]; !ok || current != value {
+			if existing.Spec.Template.Annotations == nil {
+				existing.Spec.Template.Annotations = map[string]string{}
+			}
+			existing.Spec.Template.Annotations[key] = value
+			changed = true
+		}
+	}
+	if existing.Annotations[overriddenAnnotationsAnnotation] != desired.Annotations[overriddenAnnotationsAnnotation] {
+		if len(wanted) == 0 {
+			delete(existing.Annotations, overriddenAnnotationsAnnotation)
+		} else {
+			if existing.Annotations == nil {
+				existing.Annotations = map[string]string{}
+			}
+			existing.Annotations[overriddenAnnotationsAnnotation] = desired.Annotations[overriddenAnnotationsAnnotation]
+		}
+		changed = true
+	}
+	return changed
+}
+
+func splitAnnotationKeys(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// setContainerDefaults sets the defaults the API server applies to the fields of a container that the
+// reconcilers compare, so that containers set from the GitopsService are not updated on every reconcile
+func setContainerDefaults(container *corev1.Container) {
+	normalizeContainerDefaults(container)
+	if container.ImagePullPolicy == "" {
+		container.ImagePullPolicy = corev1.PullIfNotPresent
+		if _, tag, ok := strings.Cut(container.Image[strings.LastIndex(container.Image, "/")+1:], ":"); !ok || tag == "latest" {
+			container.ImagePullPolicy = corev1.PullAlways
+		}
+	}
+	for i := range container.Ports {
+		if container.Ports[i].Protocol == "" {
+			container.Ports[i].Protocol = corev1.ProtocolTCP
+		}
+	}
+	for i := range container.Env {
+		setEnvVarDefaults(&container.Env[i])
+	}
+}
+
+func setEnvVarDefaults(env *corev1.EnvVar) {
+	if env.ValueFrom != nil && env.ValueFrom.FieldRef != nil && env.ValueFrom.FieldRef.APIVersion == "" {
+		env.ValueFrom.FieldRef.APIVersion = "v1"
+	}
+}
+
+// setVolumeDefaults sets the default mode the API server applies to the volumes projecting files
+func setVolumeDefaults(volume *corev1.Volume) {
+	defaultMode := ptr.To(corev1.SecretVolumeSourceDefaultMode)
+	switch {
+	case volume.Secret != nil && volume.Secret.DefaultMode == nil:
+		volume.Secret.DefaultMode = defaultMode
+	case volume.ConfigMap != nil && volume.ConfigMap.DefaultMode == nil:
+		volume.ConfigMap.DefaultMode = defaultMode
+	case volume.Projected != nil && volume.Projected.DefaultMode == nil:
+		volume.Projected.DefaultMode = defaultMode
+	case volume.DownwardAPI != nil && volume.DownwardAPI.DefaultMode == nil:
+		volume.DownwardAPI.DefaultMode = defaultMode
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	"gotest.tools/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyPodTemplateOverrides(t *testing.T) {
+	deployment := pluginDeployment("")
+	deployment.Spec.Template.Annotations = map[string]string{"httpd-cfg-hash": "abc"}
+
+	applyPodTemplateOverrides(deployment, &pipelinesv1beta1.PodTemplateOverrides{
+		Labels:            map[string]string{"sidecar.istio.io/inject": "true", kubeAppLabelApp: "other"},
+		Annotations:       map[string]string{"proxy.istio.io/config": "{}", "httpd-cfg-hash": "overridden"},
+		PriorityClassName: "system-cluster-critical",
+		Env: []corev1.EnvVar{
+			{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"},
+			{Name: "SSL_CERT_DIR", Value: "/etc/pki/corporate"},
+		},
+		VolumeMounts: []corev1.VolumeMount{{Name: "corporate-ca", MountPath: "/etc/pki/corporate", ReadOnly: true}},
+		Volumes: []corev1.Volume{{
+			Name:         "corporate-ca",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "corporate-ca"}}},
+		}},
+		Sidecars: []corev1.Container{
+			{Name: "proxy", Image: "registry.example.com/proxy:1.0"},
+			{Name: gitopsPluginName, Image: "registry.example.com/plugin:latest"},
+		},
+	})
+
+	template := deployment.Spec.Template
+	// labels and annotations set by the operator are kept
+	assert.DeepEqual(t, template.Labels, map[string]string{kubeAppLabelApp: gitopsPluginName, "sidecar.istio.io/inject": "true"})
+	assert.DeepEqual(t, template.Annotations, map[string]string{"httpd-cfg-hash": "abc", "proxy.istio.io/config": "{}"})
+	assert.Equal(t, deployment.Annotations[overriddenAnnotationsAnnotation], "proxy.istio.io/config")
+	assert.Equal(t, template.Spec.PriorityClassName, "system-cluster-critical")
+
+	container := template.Spec.Containers[0]
+	assert.Equal(t, container.Env[len(container.Env)-1].Name, "SSL_CERT_DIR")
+	assert.Equal(t, container.VolumeMounts[len(container.VolumeMounts)-1].Name, "corporate-ca")
+	volume := template.Spec.Volumes[len(template.Spec.Volumes)-1]
+	assert.Equal(t, volume.Name, "corporate-ca")
+	assert.DeepEqual(t, volume.ConfigMap.DefaultMode, ptr.To(int32(420)))
+
+	// the workload container cannot be replaced by a sidecar
+	assert.Equal(t, len(template.Spec.Containers), 2)
+	assert.Equal(t, template.Spec.Containers[1].Name, "proxy")
+	assert.Equal(t, template.Spec.Containers[1].ImagePullPolicy, corev1.PullIfNotPresent)
+}
+
+func TestReconcileOverriddenAnnotations(t *testing.T) {
+	existing := &appsv1.Deployment{}
+	existing.Annotations = map[string]string{overriddenAnnotationsAnnotation: "a,b"}
+	existing.Spec.Template.Annotations = map[string]string{
+		"a":                                 "1",
+		"b":                                 "2",
+		"kubectl.kubernetes.io/restartedAt": "2024-01-01T00:00:00Z",
+	}
+	desired := &appsv1.Deployment{}
+	desired.Annotations = map[string]string{overriddenAnnotationsAnnotation: "a,c"}
+	desired.Spec.Template.Annotations = map[string]string{"a": "10", "c": "3"}
+
+	assert.Assert(t, reconcileOverriddenAnnotations(existing, desired))
+	assert.DeepEqual(t, existing.Spec.Template.Annotations, map[string]string{
+		"a":                                 "10",
+		"c":                                 "3",
+		"kubectl.kubernetes.io/restartedAt": "2024-01-01T00:00:00Z",
+	})
+	assert.Equal(t, existing.Annotations[overriddenAnnotationsAnnotation], "a,c")
+	assert.Assert(t, !reconcileOverriddenAnnotations(existing, desired))
+
+	// removing every override removes the tracking annotation
+	assert.Assert(t, reconcileOverriddenAnnotations(existing, &appsv1.Deployment{}))
+	assert.DeepEqual(t, existing.Spec.Template.Annotations, map[string]string{"kubectl.kubernetes.io/restartedAt": "2024-01-01T00:00:00Z"})
+	_, ok := existing.Annotations[overriddenAnnotationsAnnotation]
+	assert.Assert(t, !ok)
+}
+
+func TestReconcileBackend_PodTemplateOverrides(t *testing.T) {
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(newGitopsService()).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	instance := newGitopsService()
+	instance.Spec.Backend = &pipelinesv1beta1.BackendSpec{
+		PodTemplateOverrides: &pipelinesv1beta1.PodTemplateOverrides{
+			Labels:      map[string]string{"sidecar.istio.io/inject": "true"},
+			Annotations: map[string]string{"proxy.istio.io/config": "{}"},
+			Env:         []corev1.EnvVar{{Name: insecureEnvVar, Value: "false"}},
+			Sidecars:    []corev1.Container{{Name: "proxy", Image: "registry.example.com/proxy:1.0"}},
+		},
+	}
+
+	name := types.NamespacedName{Name: serviceName, Namespace: serviceNamespace}
+	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
+	_, err := reconciler.reconcileBackend(name, instance, reqLogger)
+	assertNoError(t, err)
+
+	deployment := &appsv1.Deployment{}
+	assertNoError(t, fakeClient.Get(context.TODO(), name, deployment))
+	assert.Equal(t, deployment.Spec.Template.Labels["sidecar.istio.io/inject"], "true")
+	assert.Equal(t, deployment.Spec.Template.Annotations["proxy.istio.io/config"], "{}")
+	assert.DeepEqual(t, deployment.Spec.Template.Spec.Containers[0].Env, []corev1.EnvVar{{Name: insecureEnvVar, Value: "false"}})
+	assert.Equal(t, deployment.Spec.Template.Spec.Containers[1].Name, "proxy")
+
+	// an unchanged GitopsService does not update the deployment
+	resourceVersion := deployment.ResourceVersion
+	_, err = reconciler.reconcileBackend(name, instance, reqLogger)
+	assertNoError(t, err)
+	assertNoError(t, fakeClient.Get(context.TODO(), name, deployment))
+	assert.Equal(t, deployment.ResourceVersion, resourceVersion)
+
+	// the overrides removed from the GitopsService are removed from the deployment
+	instance.Spec.Backend.PodTemplateOverrides = nil
+	_, err = reconciler.reconcileBackend(name, instance, reqLogger)
+	assertNoError(t, err)
+	assertNoError(t, fakeClient.Get(context.TODO(), name, deployment))
+	_, ok := deployment.Spec.Template.Labels["sidecar.istio.io/inject"]
+	assert.Assert(t, !ok)
+	_, ok = deployment.Spec.Template.Annotations["proxy.istio.io/config"]
+	assert.Assert(t, !ok)
+	assert.Equal(t, len(deployment.Spec.Template.Spec.Containers), 1)
+	assert.DeepEqual(t, deployment.Spec.Template.Spec.Containers[0].Env, newBackendDeployment(name, "", configv1.TLSProfileSpec{}).Spec.Template.Spec.Containers[0].Env)
+}
@@ -0,0 +1,411 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	argocommon "github.com/argoproj-labs/argocd-operator/common"
+	argocdutil "github.com/argoproj-labs/argocd-operator/controllers/argoutil"
+	"github.com/go-logr/logr"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// backendRBACLabel is set on the Roles and RoleBindings of the backend service in the namespaced RBAC scope, so
+// that the ones of the namespaces leaving the scope can be found and deleted
+const backendRBACLabel = "pipelines.openshift.io/backend-rbac"
+
+// backendRBACNamespaced returns true if the backend service is only granted access in the namespaces used by Argo CD
+func backendRBACNamespaced(instance *pipelinesv1beta1.GitopsService) bool {
+	return instance.Spec.Backend != nil && instance.Spec.Backend.RBACScope == pipelinesv1beta1.BackendRBACScopeNamespaced
+}
+
+// isBackendRBACNamespaced returns true if the GitopsService uses the namespaced RBAC scope, in which case the
+// changes to the namespaces and Argo CD instances change the RBAC of the backend service
+func (r *ReconcileGitopsService) isBackendRBACNamespaced() bool {
+	instance := &pipelinesv1beta1.GitopsService{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: serviceName}, instance); err != nil {
+		return false
+	}
+	return backendRBACNamespaced(instance)
+}
+
+// reconcileBackendRBAC grants access to the backend service with a ClusterRole or, in the namespaced RBAC scope,
+// with a Role in each namespace used by Argo CD. The RBAC resources that are out of the scope are deleted.
+func (r *ReconcileGitopsService) reconcileBackendRBAC(instance *pipelinesv1beta1.GitopsService, meta types.NamespacedName,
+	reqLogger logr.Logger) error {
+	var namespaces []string
+	if backendRBACNamespaced(instance) {
+		var err error
+		namespaces, err = r.backendTargetNamespaces()
+		if err != nil {
+			return err
+		}
+		for _, namespace := range namespaces {
+			if err := r.reconcileBackendRole(instance, newBackendRole(meta, namespace), reqLogger); err != nil {
+				return err
+			}
+			if err := r.reconcileBackendRoleBinding(instance, newBackendRoleBinding(meta, namespace), reqLogger); err != nil {
+				return err
+			}
+		}
+		clusterRBACName := types.NamespacedName{Name: gitopsServicePrefix + meta.Name}
+		if err := r.deleteControlledResource(instance, &rbacv1.ClusterRoleBinding{}, clusterRBACName, reqLogger); err != nil {
+			return err
+		}
+		if err := r.deleteControlledResource(instance, &rbacv1.ClusterRole{}, clusterRBACName, reqLogger); err != nil {
+			return err
+		}
+	} else if err := r.reconcileBackendClusterRBAC(instance, meta, reqLogger); err != nil {
+		return err
+	}
+	return r.deleteBackendRBACOutOfScope(instance, meta, namespaces, reqLogger)
+}
+
+// reconcileBackendClusterRBAC grants access to the backend service in every namespace
+func (r *ReconcileGitopsService) reconcileBackendClusterRBAC(instance *pipelinesv1beta1.GitopsService, meta types.NamespacedName,
+	reqLogger logr.Logger) error {
+	// Define a new cluster role for backend service
+	{
+		clusterRoleObj := newClusterRole(meta)
+
+		// Set GitopsService instance as the owner and controller
+		if err := controllerutil.SetControllerReference(instance, clusterRoleObj, r.Scheme); err != nil {
+			return err
+		}
+
+		existingClusterRole := &rbacv1.ClusterRole{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: clusterRoleObj.Name}, existingClusterRole)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				reqLogger.Info("Creating a new Cluster Role", "Name", clusterRoleObj.Name)
+				err = recordResourceEvent(r.Recorder, instance, resourceCreate, clusterRoleObj, r.Client.Create(context.TODO(), clusterRoleObj))
+				if err != nil {
+					return err
+				}
+			} else {
+				return err
+			}
+		} else {
+			var changed []string
+			if !reflect.DeepEqual(existingClusterRole.Rules, clusterRoleObj.Rules) {
+				existingClusterRole.Rules = clusterRoleObj.Rules
+				changed = append(changed, "rules")
+			}
+			ownerChanged, err := r.reconcileControllerReference(instance, existingClusterRole)
+			if err != nil {
+				return err
+			}
+			if ownerChanged {
+				changed = append(changed, "ownerReferences")
+			}
+			if len(changed) > 0 {
+				reqLogger.Info("Reconciling existing Cluster Role", "Name", clusterRoleObj.Name, "Changed", changed)
+				err = recordResourceDriftEvent(r.Recorder, instance, existingClusterRole, changed, r.Client.Update(context.TODO(), existingClusterRole))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Define Cluster Role Binding for backend service
+	{
+		clusterRoleBinding := newClusterRoleBinding(meta)
+
+		// Set GitopsService instance as the owner and controller
+		if err := controllerutil.SetControllerReference(instance, clusterRoleBinding, r.Scheme); err != nil {
+			return err
+		}
+
+		existingClusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: clusterRoleBinding.Name}, existingClusterRoleBinding)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				reqLogger.Info("Creating a new Cluster Role Binding", "Name", clusterRoleBinding.Name)
+				err = recordResourceEvent(r.Recorder, instance, resourceCreate, clusterRoleBinding, r.Client.Create(context.TODO(), clusterRoleBinding))
+				if err != nil {
+					return err
+				}
+			} else {
+				return err
+			}
+		} else if !reflect.DeepEqual(existingClusterRoleBinding.RoleRef, clusterRoleBinding.RoleRef) {
+			// The roleRef of a binding is immutable, the binding is recreated to change it
+			reqLogger.Info("Recreating existing Cluster Role Binding, roleRef changed", "Name", clusterRoleBinding.Name)
+			err = recordResourceEvent(r.Recorder, instance, resourceDelete, existingClusterRoleBinding, r.Client.Delete(context.TODO(), existingClusterRoleBinding))
+			if err != nil {
+				return err
+			}
+			err = recordResourceEvent(r.Recorder, instance, resourceCreate, clusterRoleBinding, r.Client.Create(context.TODO(), clusterRoleBinding))
+			if err != nil {
+				return err
+			}
+		} else {
+			var changed []string
+			if !reflect.DeepEqual(existingClusterRoleBinding.Subjects, clusterRoleBinding.Subjects) {
+				existingClusterRoleBinding.Subjects = clusterRoleBinding.Subjects
+				changed = append(changed, "subjects")
+			}
+			ownerChanged, err := r.reconcileControllerReference(instance, existingClusterRoleBinding)
+			if err != nil {
+				return err
+			}
+			if ownerChanged {
+				changed = append(changed, "ownerReferences")
+			}
+			if len(changed) > 0 {
+				reqLogger.Info("Reconciling existing Cluster Role Binding", "Name", clusterRoleBinding.Name, "Changed", changed)
+				err = recordResourceDriftEvent(r.Recorder, instance, existingClusterRoleBinding, changed, r.Client.Update(context.TODO(), existingClusterRoleBinding))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// backendTargetNamespaces returns the sorted names of the namespaces of the Argo CD instances, of the namespaces
+// matching their source namespaces and of the namespaces they manage
+func (r *ReconcileGitopsService) backendTargetNamespaces() ([]string, error) {
+	argoCDs := &argoapp.ArgoCDList{}
+	if err := r.Client.List(context.TODO(), argoCDs); err != nil {
+		return nil, err
+	}
+	instanceNamespaces := map[string]bool{}
+	var patterns []string
+	for _, argoCD := range argoCDs.Items {
+		instanceNamespaces[argoCD.Namespace] = true
+		patterns = append(patterns, argoCD.Spec.SourceNamespaces...)
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.Client.List(context.TODO(), namespaceList); err != nil {
+		return nil, err
+	}
+	var namespaces []string
+	for _, namespace := range namespaceList.Items {
+		if namespace.Status.Phase == corev1.NamespaceTerminating {
+			continue
+		}
+		if instanceNamespaces[namespace.Name] || instanceNamespaces[namespace.Labels[argocommon.ArgoCDKeyManagedBy]] ||
+			matchesNamespacePattern(patterns, namespace.Name) {
+			namespaces = append(namespaces, namespace.Name)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// matchesNamespacePattern returns true if the namespace matches one of the given names or glob patterns
+func matchesNamespacePattern(patterns []string, namespace string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, namespace); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func newBackendRole(meta types.NamespacedName, namespace string) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gitopsServicePrefix + meta.Name,
+			Namespace: namespace,
+			Labels:    map[string]string{backendRBACLabel: meta.Name},
+		},
+		Rules: policyRuleForBackendServiceClusterRole(),
+	}
+}
+
+func newBackendRoleBinding(meta types.NamespacedName, namespace string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gitopsServicePrefix + meta.Name,
+			Namespace: namespace,
+			Labels:    map[string]string{backendRBACLabel: meta.Name},
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      gitopsServicePrefix + meta.Name,
+				Namespace: meta.Namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     gitopsServicePrefix + meta.Name,
+		},
+	}
+}
+
+// reconcileBackendRole creates or updates the given Role of the backend service, owned by the GitopsService
+func (r *ReconcileGitopsService) reconcileBackendRole(instance *pipelinesv1beta1.GitopsService, role *rbacv1.Role,
+	reqLogger logr.Logger) error {
+	if err := controllerutil.SetControllerReference(instance, role, r.Scheme); err != nil {
+		return err
+	}
+
+	existing := &rbacv1.Role{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: role.Name, Namespace: role.Namespace}, existing)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		reqLogger.Info("Creating a new Role", "Namespace", role.Namespace, "Name", role.Name)
+		return recordResourceEvent(r.Recorder, instance, resourceCreate, role, r.Client.Create(context.TODO(), role))
+	}
+
+	var changed []string
+	if !containsStringMap(existing.Labels, role.Labels) {
+		existing.Labels = argocdutil.AppendStringMap(existing.Labels, role.Labels)
+		changed = append(changed, "labels")
+	}
+	if !reflect.DeepEqual(existing.Rules, role.Rules) {
+		existing.Rules = role.Rules
+		changed = append(changed, "rules")
+	}
+	ownerChanged, err := r.reconcileControllerReference(instance, existing)
+	if err != nil {
+		return err
+	}
+	if ownerChanged {
+		changed = append(changed, "ownerReferences")
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	reqLogger.Info("Reconciling existing Role", "Namespace", existing.Namespace, "Name", existing.Name, "Changed", changed)
+	return recordResourceDriftEvent(r.Recorder, instance, existing, changed, r.Client.Update(context.TODO(), existing))
+}
+
+// reconcileBackendRoleBinding creates or updates the given RoleBinding of the backend service, owned by the
+// GitopsService. The binding is recreated when its roleRef changed, as the roleRef is immutable.
+func (r *ReconcileGitopsService) reconcileBackendRoleBinding(instance *pipelinesv1beta1.GitopsService, binding *rbacv1.RoleBinding,
+	reqLogger logr.Logger) error {
+	if err := controllerutil.SetControllerReference(instance, binding, r.Scheme); err != nil {
+		return err
+	}
+
+	existing := &rbacv1.RoleBinding{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: binding.Name, Namespace: binding.Namespace}, existing)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		reqLogger.Info("Creating a new Role Binding", "Namespace", binding.Namespace, "Name", binding.Name)
+		return recordResourceEvent(r.Recorder, instance, resourceCreate, binding, r.Client.Create(context.TODO(), binding))
+	}
+
+	if !reflect.DeepEqual(existing.RoleRef, binding.RoleRef) {
+		reqLogger.Info("Recreating existing Role Binding, roleRef changed", "Namespace", existing.Namespace, "Name", existing.Name)
+		if err := recordResourceEvent(r.Recorder, instance, resourceDelete, existing, r.Client.Delete(context.TODO(), existing)); err != nil {
+			return err
+		}
+		return recordResourceEvent(r.Recorder, instance, resourceCreate, binding, r.Client.Create(context.TODO(), binding))
+	}
+
+	var changed []string
+	if !containsStringMap(existing.Labels, binding.Labels) {
+		existing.Labels = argocdutil.AppendStringMap(existing.Labels, binding.Labels)
+		changed = append(changed, "labels")
+	}
+	if !reflect.DeepEqual(existing.Subjects, binding.Subjects) {
+		existing.Subjects = binding.Subjects
+		changed = append(changed, "subjects")
+	}
+	ownerChanged, err := r.reconcileControllerReference(instance, existing)
+	if err != nil {
+		return err
+	}
+	if ownerChanged {
+		changed = append(changed, "ownerReferences")
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	reqLogger.Info("Reconciling existing Role Binding", "Namespace", existing.Namespace, "Name", existing.Name, "Changed", changed)
+	return recordResourceDriftEvent(r.Recorder, instance, existing, changed, r.Client.Update(context.TODO(), existing))
+}
+
+// deleteBackendRBACOutOfScope deletes the Roles and RoleBindings of the backend service that are not in one of the
+// given namespaces
+func (r *ReconcileGitopsService) deleteBackendRBACOutOfScope(instance *pipelinesv1beta1.GitopsService, meta types.NamespacedName,
+	namespaces []string, reqLogger logr.Logger) error {
+	selector := client.MatchingLabels{backendRBACLabel: meta.Name}
+
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err := r.Client.List(context.TODO(), roleBindings, selector); err != nil {
+		return err
+	}
+	for i := range roleBindings.Items {
+		binding := &roleBindings.Items[i]
+		if slices.Contains(namespaces, binding.Namespace) || !metav1.IsControlledBy(binding, instance) {
+			continue
+		}
+		reqLogger.Info("Deleting Role Binding, namespace is out of the backend RBAC scope", "Namespace", binding.Namespace, "Name", binding.Name)
+		if err := recordResourceEvent(r.Recorder, instance, resourceDelete, binding, r.Client.Delete(context.TODO(), binding)); err != nil {
+			return err
+		}
+	}
+
+	roles := &rbacv1.RoleList{}
+	if err := r.Client.List(context.TODO(), roles, selector); err != nil {
+		return err
+	}
+	for i := range roles.Items {
+		role := &roles.Items[i]
+		if slices.Contains(namespaces, role.Namespace) || !metav1.IsControlledBy(role, instance) {
+			continue
+		}
+		reqLogger.Info("Deleting Role, namespace is out of the backend RBAC scope", "Namespace", role.Namespace, "Name", role.Name)
+		if err := recordResourceEvent(r.Recorder, instance, resourceDelete, role, r.Client.Delete(context.TODO(), role)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteControlledResource deletes the resource of the given name if it exists and is controlled by the GitopsService
+func (r *ReconcileGitopsService) deleteControlledResource(instance *pipelinesv1beta1.GitopsService, obj client.Object,
+	name types.NamespacedName, reqLogger logr.Logger) error {
+	if err := r.Client.Get(context.TODO(), name, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !metav1.IsControlledBy(obj, instance) {
+		return nil
+	}
+	reqLogger.Info("Deleting resource", "Kind", resourceKind(obj), "Name", obj.GetName())
+	return recordResourceEvent(r.Recorder, instance, resourceDelete, obj, r.Client.Delete(context.TODO(), obj))
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	argocommon "github.com/argoproj-labs/argocd-operator/common"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileBackend_NamespacedRBAC(t *testing.T) {
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	managed := namespace("guestbook", map[string]string{argocommon.ArgoCDKeyManagedBy: "team-argocd"})
+	argoCD := &argoapp.ArgoCD{
+		ObjectMeta: metav1.ObjectMeta{Name: "argocd", Namespace: "team-argocd"},
+		Spec:       argoapp.ArgoCDSpec{SourceNamespaces: []string{"team-apps-*"}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(newGitopsService(), argoCD, managed,
+		namespace("team-argocd", nil), namespace("team-apps-1", nil), namespace("other-apps", nil)).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	instance := newGitopsService()
+
+	name := types.NamespacedName{Name: serviceName, Namespace: serviceNamespace}
+	reqLogger := logs.WithValues("Request.Namespace", "test", "Request.Name", "test")
+	rbacName := gitopsServicePrefix + serviceName
+	reconcileBackend := func() {
+		t.Helper()
+		_, err := reconciler.reconcileBackend(name, instance, reqLogger)
+		assertNoError(t, err)
+	}
+	roleNamespaces := func() []string {
+		t.Helper()
+		roles := &rbacv1.RoleList{}
+		assertNoError(t, fakeClient.List(context.TODO(), roles, client.MatchingLabels{backendRBACLabel: serviceName}))
+		bindings := &rbacv1.RoleBindingList{}
+		assertNoError(t, fakeClient.List(context.TODO(), bindings, client.MatchingLabels{backendRBACLabel: serviceName}))
+		assert.Equal(t, len(bindings.Items), len(roles.Items))
+		var namespaces []string
+		for i, role := range roles.Items {
+			assert.Equal(t, role.Name, rbacName)
+			assert.DeepEqual(t, role.Rules, policyRuleForBackendServiceClusterRole())
+			assert.DeepEqual(t, bindings.Items[i].Subjects, newClusterRoleBinding(name).Subjects)
+			assert.DeepEqual(t, bindings.Items[i].RoleRef, rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: rbacName})
+			namespaces = append(namespaces, role.Namespace)
+		}
+		return namespaces
+	}
+	clusterRBACExists := func() bool {
+		t.Helper()
+		err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: rbacName}, &rbacv1.ClusterRole{})
+		if errors.IsNotFound(err) {
+			err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: rbacName}, &rbacv1.ClusterRoleBinding{})
+			assert.Assert(t, errors.IsNotFound(err))
+			return false
+		}
+		assertNoError(t, err)
+		assertNoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: rbacName}, &rbacv1.ClusterRoleBinding{}))
+		return true
+	}
+
+	// the backend is granted access cluster wide by default
+	reconcileBackend()
+	assert.Assert(t, clusterRBACExists())
+	assert.Equal(t, len(roleNamespaces()), 0)
+
+	// the namespaced scope grants access in the namespaces used by Argo CD only
+	instance.Spec.Backend = &pipelinesv1beta1.BackendSpec{RBACScope: pipelinesv1beta1.BackendRBACScopeNamespaced}
+	reconcileBackend()
+	assert.Assert(t, !clusterRBACExists())
+	assert.DeepEqual(t, roleNamespaces(), []string{"guestbook", "team-apps-1", "team-argocd"})
+
+	// the namespaces leaving the scope are cleaned up
+	delete(managed.Labels, argocommon.ArgoCDKeyManagedBy)
+	assertNoError(t, fakeClient.Update(context.TODO(), managed))
+	reconcileBackend()
+	assert.DeepEqual(t, roleNamespaces(), []string{"team-apps-1", "team-argocd"})
+
+	// going back to the cluster scope removes the namespaced RBAC
+	instance.Spec.Backend.RBACScope = pipelinesv1beta1.BackendRBACScopeCluster
+	reconcileBackend()
+	assert.Assert(t, clusterRBACExists())
+	assert.Equal(t, len(roleNamespaces()), 0)
+}
+
+func TestMatchesNamespacePattern(t *testing.T) {
+	patterns := []string{"team-a", "apps-*"}
+	assert.Assert(t, matchesNamespacePattern(patterns, "team-a"))
+	assert.Assert(t, matchesNamespacePattern(patterns, "apps-prod"))
+	assert.Assert(t, !matchesNamespacePattern(patterns, "team-b"))
+	assert.Assert(t, !matchesNamespacePattern([]string{"["}, "["))
+}
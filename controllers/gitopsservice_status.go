@@ -0,0 +1,269 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	"github.com/redhat-developer/gitops-operator/common"
+	argocd "github.com/redhat-developer/gitops-operator/controllers/argocd"
+	"github.com/redhat-developer/gitops-operator/controllers/util"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// argoCDPhaseAvailable is the phase reported by an ArgoCD instance once all of its workloads are available
+const argoCDPhaseAvailable = "Available"
+
+// reconcileStatus computes the status of the GitopsService from the components it manages and
+// writes it through the status subresource. reconcileErr is the error, if any, returned by the
+// reconciliation of the components.
+func (r *ReconcileGitopsService) reconcileStatus(ctx context.Context, instance *pipelinesv1beta1.GitopsService, reconcileErr error) error {
+	status := instance.Status.DeepCopy()
+	status.ObservedGeneration = instance.Generation
+
+	// The GitopsService is reported degraded when the status of its components cannot be read
+	unavailable, statusErr := r.componentsStatus(ctx, instance, status)
+	var degraded []string
+	if reconcileErr != nil {
+		degraded = append(degraded, reconcileErr.Error())
+	}
+	if statusErr != nil {
+		degraded = append(degraded, fmt.Sprintf("unable to read the status of the components: %v", statusErr))
+	}
+
+	// Degraded and Ready summarize the state of the components
+	if len(degraded) > 0 {
+		setStatusCondition(status, instance, pipelinesv1beta1.ConditionDegraded, metav1.ConditionTrue,
+			pipelinesv1beta1.ReasonReconcileFailed, strings.Join(degraded, "; "))
+	} else {
+		setStatusCondition(status, instance, pipelinesv1beta1.ConditionDegraded, metav1.ConditionFalse,
+			pipelinesv1beta1.ReasonReconcileSucceeded, "All components were reconciled successfully")
+	}
+
+	switch {
+	case len(degraded) > 0:
+		setStatusCondition(status, instance, pipelinesv1beta1.ConditionReady, metav1.ConditionFalse,
+			pipelinesv1beta1.ReasonReconcileFailed, "GitopsService failed to reconcile, see the Degraded condition")
+	case len(unavailable) > 0:
+		setStatusCondition(status, instance, pipelinesv1beta1.ConditionReady, metav1.ConditionFalse,
+			pipelinesv1beta1.ReasonUnavailable, fmt.Sprintf("Waiting for %s to become available", strings.Join(unavailable, ", ")))
+	default:
+		setStatusCondition(status, instance, pipelinesv1beta1.ConditionReady, metav1.ConditionTrue,
+			pipelinesv1beta1.ReasonAvailable, "All components are available")
+	}
+
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return statusErr
+	}
+	instance.Status = *status
+	// The GitopsService may have been deleted since it was read, there is nothing left to report then
+	if err := r.Client.Status().Update(ctx, instance); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return statusErr
+}
+
+// componentsStatus sets the status and the availability conditions of the components managed by the GitopsService,
+// and returns the components that are expected to run but are not available.
+func (r *ReconcileGitopsService) componentsStatus(ctx context.Context, instance *pipelinesv1beta1.GitopsService, status *pipelinesv1beta1.GitopsServiceStatus) ([]string, error) {
+	// unavailable collects the components that are expected to run but are not available
+	var unavailable []string
+
+	// Default Argo CD instance
+	managementState := r.defaultArgoCDManagementState(instance)
+	if managementState == pipelinesv1beta1.DefaultArgoCDRemoved {
+		message := fmt.Sprintf("Default Argo CD instance is disabled by %s", common.DisableDefaultInstallEnvVar)
+		if instance.Spec.DefaultArgoCD != nil && instance.Spec.DefaultArgoCD.ManagementState != "" {
+			message = "Default Argo CD instance is disabled by spec.defaultArgoCD.managementState"
+		}
+		status.DefaultArgoCD = nil
+		setStatusCondition(status, instance, pipelinesv1beta1.ConditionDefaultArgoCDAvailable, metav1.ConditionFalse,
+			pipelinesv1beta1.ReasonDisabled, message)
+		setStatusCondition(status, instance, pipelinesv1beta1.ConditionDefaultArgoCDConflict, metav1.ConditionFalse,
+			pipelinesv1beta1.ReasonDisabled, message)
+	} else {
+		argoCDStatus, available, message, err := r.defaultArgoCDStatus(ctx, instance)
+		if err != nil {
+			return nil, err
+		}
+		status.DefaultArgoCD = argoCDStatus
+		// An unmanaged instance is reported, but the operator does not wait for it
+		if !available && managementState != pipelinesv1beta1.DefaultArgoCDUnmanaged {
+			unavailable = append(unavailable, "default Argo CD instance")
+		}
+		setStatusCondition(status, instance, pipelinesv1beta1.ConditionDefaultArgoCDAvailable, conditionStatus(available),
+			availabilityReason(argoCDStatus != nil, available), message)
+
+		conflicts, err := r.defaultArgoCDConflicts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case managementState == pipelinesv1beta1.DefaultArgoCDUnmanaged:
+			setStatusCondition(status, instance, pipelinesv1beta1.ConditionDefaultArgoCDConflict, metav1.ConditionFalse,
+				pipelinesv1beta1.ReasonUnmanaged, "Default Argo CD instance is not managed by the operator")
+		case conflicts != "":
+			setStatusCondition(status, instance, pipelinesv1beta1.ConditionDefaultArgoCDConflict, metav1.ConditionTrue,
+				pipelinesv1beta1.ReasonFieldConflict, fmt.Sprintf("Fields of Argo CD instance %s/%s owned by other field managers are left unchanged: %s",
+					serviceNamespace, common.ArgoCDInstanceName, conflicts))
+		default:
+			setStatusCondition(status, instance, pipelinesv1beta1.ConditionDefaultArgoCDConflict, metav1.ConditionFalse,
+				pipelinesv1beta1.ReasonNoConflict, "No field of the default Argo CD instance set by the operator is owned by another field manager")
+		}
+	}
+
+	// Backend
+	namespace, err := GetBackendNamespace(r.Client)
+	if err != nil {
+		return nil, err
+	}
+	backendStatus, available, message, err := r.deploymentStatus(ctx, instance, types.NamespacedName{Name: serviceName, Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+	status.Backend = backendStatus
+	if !available {
+		unavailable = append(unavailable, "backend")
+	}
+	setStatusCondition(status, instance, pipelinesv1beta1.ConditionBackendAvailable, conditionStatus(available),
+		availabilityReason(backendStatus != nil, available), message)
+
+	// Console plugin
+	if !util.IsConsoleAPIFound() || !r.isDynamicPluginSupported() {
+		status.ConsolePlugin = nil
+		setStatusCondition(status, instance, pipelinesv1beta1.ConditionConsolePluginAvailable, metav1.ConditionFalse,
+			pipelinesv1beta1.ReasonNotSupported, "Console dynamic plugins are not supported by this cluster")
+	} else {
+		pluginStatus, available, message, err := r.deploymentStatus(ctx, instance, types.NamespacedName{Name: gitopsPluginName, Namespace: serviceNamespace})
+		if err != nil {
+			return nil, err
+		}
+		status.ConsolePlugin = pluginStatus
+		if !available {
+			unavailable = append(unavailable, "console plugin")
+		}
+		setStatusCondition(status, instance, pipelinesv1beta1.ConditionConsolePluginAvailable, conditionStatus(available),
+			availabilityReason(pluginStatus != nil, available), message)
+	}
+
+	return unavailable, nil
+}
+
+// defaultArgoCDStatus returns the status of the default Argo CD instance, whether it is available,
+// and a message describing its availability.
+func (r *ReconcileGitopsService) defaultArgoCDStatus(ctx context.Context, instance *pipelinesv1beta1.GitopsService) (*pipelinesv1beta1.ArgoCDInstanceStatus, bool, string, error) {
+	argocdInstance := &argoapp.ArgoCD{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argocdInstance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, false, fmt.Sprintf("Argo CD instance %s/%s not found", serviceNamespace, common.ArgoCDInstanceName), nil
+		}
+		return nil, false, "", err
+	}
+
+	argoCDStatus := &pipelinesv1beta1.ArgoCDInstanceStatus{
+		ObservedGeneration: instance.Generation,
+		Name:               argocdInstance.Name,
+		Namespace:          argocdInstance.Namespace,
+		Phase:              argocdInstance.Status.Phase,
+	}
+	if argocdInstance.Status.Phase != argoCDPhaseAvailable {
+		return argoCDStatus, false, fmt.Sprintf("Argo CD instance %s/%s is in phase %q", argocdInstance.Namespace, argocdInstance.Name, argocdInstance.Status.Phase), nil
+	}
+	return argoCDStatus, true, fmt.Sprintf("Argo CD instance %s/%s is available", argocdInstance.Namespace, argocdInstance.Name), nil
+}
+
+// defaultArgoCDConflicts returns the fields of the default Argo CD instance left unchanged by the operator
+// because they are owned by another field manager, as recorded by applyArgoCDInstance.
+func (r *ReconcileGitopsService) defaultArgoCDConflicts(ctx context.Context) (string, error) {
+	argocdInstance := &argoapp.ArgoCD{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argocdInstance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return argocdInstance.Annotations[argocd.ConflictingFieldsAnnotation], nil
+}
+
+// deploymentStatus returns the status of the component run by the given Deployment, whether it is
+// available, and a message describing its availability.
+func (r *ReconcileGitopsService) deploymentStatus(ctx context.Context, instance *pipelinesv1beta1.GitopsService, name types.NamespacedName) (*pipelinesv1beta1.ComponentStatus, bool, string, error) {
+	deployment := &appsv1.Deployment{}
+	err := r.Client.Get(ctx, name, deployment)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, false, fmt.Sprintf("Deployment %s/%s not found", name.Namespace, name.Name), nil
+		}
+		return nil, false, "", err
+	}
+
+	var replicas int32 = 1
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	componentStatus := &pipelinesv1beta1.ComponentStatus{
+		ObservedGeneration: instance.Generation,
+		Replicas:           replicas,
+		AvailableReplicas:  deployment.Status.AvailableReplicas,
+	}
+	if len(deployment.Spec.Template.Spec.Containers) > 0 {
+		componentStatus.Image = deployment.Spec.Template.Spec.Containers[0].Image
+	}
+
+	message := fmt.Sprintf("Deployment %s/%s has %d/%d replicas available", name.Namespace, name.Name, componentStatus.AvailableReplicas, replicas)
+	return componentStatus, componentStatus.AvailableReplicas >= replicas, message, nil
+}
+
+func setStatusCondition(status *pipelinesv1beta1.GitopsServiceStatus, instance *pipelinesv1beta1.GitopsService,
+	conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	apimeta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: instance.Generation,
+	})
+}
+
+func conditionStatus(available bool) metav1.ConditionStatus {
+	if available {
+		return metav1.ConditionTrue
+	}
+	return metav1.ConditionFalse
+}
+
+func availabilityReason(found, available bool) string {
+	switch {
+	case !found:
+		return pipelinesv1beta1.ReasonNotFound
+	case !available:
+		return pipelinesv1beta1.ReasonUnavailable
+	default:
+		return pipelinesv1beta1.ReasonAvailable
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	argoapp "github.com/argoproj-labs/argocd-operator/api/v1beta1"
+	"github.com/argoproj-labs/argocd-operator/controllers/argocd"
+	pipelinesv1beta1 "github.com/redhat-developer/gitops-operator/api/v1beta1"
+	"github.com/redhat-developer/gitops-operator/common"
+	"github.com/redhat-developer/gitops-operator/controllers/util"
+	"gotest.tools/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestReconcile_Status(t *testing.T) {
+	defer util.SetConsoleAPIFound(util.IsConsoleAPIFound())
+	util.SetConsoleAPIFound(true)
+
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).
+		WithObjects(util.NewClusterVersion("4.15.1"), newGitopsService()).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	// Nothing is available yet, the workloads have just been created
+	instance := getGitopsService(t, fakeClient)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionReady, metav1.ConditionFalse, pipelinesv1beta1.ReasonUnavailable)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionDegraded, metav1.ConditionFalse, pipelinesv1beta1.ReasonReconcileSucceeded)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionBackendAvailable, metav1.ConditionFalse, pipelinesv1beta1.ReasonUnavailable)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionConsolePluginAvailable, metav1.ConditionFalse, pipelinesv1beta1.ReasonUnavailable)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionDefaultArgoCDAvailable, metav1.ConditionFalse, pipelinesv1beta1.ReasonUnavailable)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionDefaultArgoCDConflict, metav1.ConditionFalse, pipelinesv1beta1.ReasonNoConflict)
+
+	assert.DeepEqual(t, instance.Status.Backend, &pipelinesv1beta1.ComponentStatus{
+		ObservedGeneration: instance.Generation,
+		Image:              backendImage,
+		Replicas:           1,
+	})
+	assert.Equal(t, instance.Status.ConsolePlugin.Replicas, int32(1))
+	assert.Equal(t, instance.Status.ConsolePlugin.AvailableReplicas, int32(0))
+	assert.DeepEqual(t, instance.Status.DefaultArgoCD, &pipelinesv1beta1.ArgoCDInstanceStatus{
+		ObservedGeneration: instance.Generation,
+		Name:               common.ArgoCDInstanceName,
+		Namespace:          serviceNamespace,
+	})
+
+	// Mark every component as available
+	for _, name := range []types.NamespacedName{
+		{Name: serviceName, Namespace: serviceNamespace},
+		{Name: gitopsPluginName, Namespace: serviceNamespace},
+	} {
+		deploy := &appsv1.Deployment{}
+		assertNoError(t, fakeClient.Get(context.TODO(), name, deploy))
+		deploy.Status.AvailableReplicas = 1
+		assertNoError(t, fakeClient.Status().Update(context.TODO(), deploy))
+	}
+	argoCD := &argoapp.ArgoCD{}
+	assertNoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: common.ArgoCDInstanceName, Namespace: serviceNamespace}, argoCD))
+	argoCD.Status.Phase = "Available"
+	assertNoError(t, fakeClient.Update(context.TODO(), argoCD))
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	instance = getGitopsService(t, fakeClient)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionReady, metav1.ConditionTrue, pipelinesv1beta1.ReasonAvailable)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionBackendAvailable, metav1.ConditionTrue, pipelinesv1beta1.ReasonAvailable)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionConsolePluginAvailable, metav1.ConditionTrue, pipelinesv1beta1.ReasonAvailable)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionDefaultArgoCDAvailable, metav1.ConditionTrue, pipelinesv1beta1.ReasonAvailable)
+	assert.Equal(t, instance.Status.Backend.AvailableReplicas, int32(1))
+	assert.Equal(t, instance.Status.DefaultArgoCD.Phase, "Available")
+}
+
+func TestReconcile_StatusDisabledComponents(t *testing.T) {
+	defer util.SetConsoleAPIFound(util.IsConsoleAPIFound())
+	util.SetConsoleAPIFound(true)
+
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	// The console plugin is not supported below OCP 4.15
+	fakeClient := fake.NewClientBuilder().WithScheme(s).
+		WithObjects(util.NewClusterVersion("4.14.1"), newGitopsService()).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+	reconciler.DisableDefaultInstall = true
+
+	_, err := reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	deploy := &appsv1.Deployment{}
+	assertNoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: serviceName, Namespace: serviceNamespace}, deploy))
+	deploy.Status.AvailableReplicas = 1
+	assertNoError(t, fakeClient.Status().Update(context.TODO(), deploy))
+
+	_, err = reconciler.Reconcile(context.TODO(), newRequest("test", "test"))
+	assertNoError(t, err)
+
+	// Disabled and unsupported components must not keep the GitopsService from being ready
+	instance := getGitopsService(t, fakeClient)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionReady, metav1.ConditionTrue, pipelinesv1beta1.ReasonAvailable)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionDefaultArgoCDAvailable, metav1.ConditionFalse, pipelinesv1beta1.ReasonDisabled)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionConsolePluginAvailable, metav1.ConditionFalse, pipelinesv1beta1.ReasonNotSupported)
+	assert.Assert(t, instance.Status.DefaultArgoCD == nil)
+	assert.Assert(t, instance.Status.ConsolePlugin == nil)
+}
+
+func TestReconcileStatus_Degraded(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).
+		WithObjects(newGitopsService()).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+
+	instance := getGitopsService(t, fakeClient)
+	err := reconciler.reconcileStatus(context.TODO(), instance, fmt.Errorf("unable to reconcile default Argo CD instance"))
+	assertNoError(t, err)
+
+	instance = getGitopsService(t, fakeClient)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionDegraded, metav1.ConditionTrue, pipelinesv1beta1.ReasonReconcileFailed)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionReady, metav1.ConditionFalse, pipelinesv1beta1.ReasonReconcileFailed)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionBackendAvailable, metav1.ConditionFalse, pipelinesv1beta1.ReasonNotFound)
+	assert.Equal(t, apimeta.FindStatusCondition(instance.Status.Conditions, pipelinesv1beta1.ConditionDegraded).Message,
+		"unable to reconcile default Argo CD instance")
+}
+
+func TestReconcileStatus_read_error(t *testing.T) {
+	logf.SetLogger(argocd.ZapLogger(true))
+	s := scheme.Scheme
+	addKnownTypesToScheme(s)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).
+		WithObjects(newGitopsService()).
+		WithStatusSubresource(&pipelinesv1beta1.GitopsService{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if _, ok := obj.(*appsv1.Deployment); ok {
+					return fmt.Errorf("connection refused")
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).Build()
+	reconciler := newReconcileGitOpsService(fakeClient, s)
+
+	// The status is written even when the components cannot be read
+	instance := getGitopsService(t, fakeClient)
+	err := reconciler.reconcileStatus(context.TODO(), instance, nil)
+	assert.Error(t, err, "connection refused")
+
+	instance = getGitopsService(t, fakeClient)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionDegraded, metav1.ConditionTrue, pipelinesv1beta1.ReasonReconcileFailed)
+	assertCondition(t, instance, pipelinesv1beta1.ConditionReady, metav1.ConditionFalse, pipelinesv1beta1.ReasonReconcileFailed)
+	assert.Equal(t, apimeta.FindStatusCondition(instance.Status.Conditions, pipelinesv1beta1.ConditionDegraded).Message,
+		"unable to read the status of the components: connection refused")
+}
+
+func getGitopsService(t *testing.T, c client.Client) *pipelinesv1beta1.GitopsService {
+	t.Helper()
+	instance := &pipelinesv1beta1.GitopsService{}
+	assertNoError(t, c.Get(context.TODO(), types.NamespacedName{Name: serviceName}, instance))
+	return instance
+}
+
+func assertCondition(t *testing.T, instance *pipelinesv1beta1.GitopsService, conditionType string, status metav1.ConditionStatus, reason string) {
+	t.Helper()
+	condition := apimeta.FindStatusCondition(instance.Status.Conditions, conditionType)
+	assert.Assert(t, condition != nil, "condition %s not found", conditionType)
+	assert.Equal(t, condition.Status, status, "unexpected status for condition %s", conditionType)
+	assert.Equal(t, condition.Reason, reason, "unexpected reason for condition %s", conditionType)
+}
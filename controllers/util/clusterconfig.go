@@ -0,0 +1,114 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ClusterConfigNamespacesEnvVar lists the namespaces of the Argo CD instances allowed to manage cluster configuration,
+// as configured on the operator Subscription.
+const ClusterConfigNamespacesEnvVar = "ARGOCD_CLUSTER_CONFIG_NAMESPACES"
+
+// clusterConfig holds the namespaces granted the cluster configuration privileges by the GitopsService, in addition
+// to the namespaces the operator was configured with. It is the state read by the OpenShift reconciler hook.
+var clusterConfig = struct {
+	sync.RWMutex
+	// configured are the namespaces of the environment variable the operator was started with
+	configured []string
+	granted    []string
+}{configured: unionNamespaces(strings.Split(os.Getenv(ClusterConfigNamespacesEnvVar), ","))}
+
+// SetClusterConfigNamespaces grants the cluster configuration privileges to the Argo CD instances in the given
+// namespaces, in addition to the namespaces the operator was configured with. Namespaces granted by a previous
+// call and not given anymore are revoked. It returns the namespaces that were granted or revoked, whose Argo CD
+// instances must be reconciled again.
+//
+// The Argo CD reconciler of the argocd-operator only reads the namespaces from the environment variable, which is
+// therefore written as well when the granted namespaces change.
+func SetClusterConfigNamespaces(namespaces []string) ([]string, error) {
+	clusterConfig.Lock()
+	defer clusterConfig.Unlock()
+
+	granted := unionNamespaces(namespaces)
+	if slices.Equal(granted, clusterConfig.granted) {
+		return nil, nil
+	}
+	previous := unionNamespaces(clusterConfig.configured, clusterConfig.granted)
+	current := unionNamespaces(clusterConfig.configured, granted)
+	if err := os.Setenv(ClusterConfigNamespacesEnvVar, strings.Join(current, ",")); err != nil {
+		return nil, err
+	}
+	clusterConfig.granted = granted
+
+	var changed []string
+	for _, namespace := range unionNamespaces(granted, previous) {
+		if namespace != "*" && namespaceAllowed(namespace, previous) != namespaceAllowed(namespace, current) {
+			changed = append(changed, namespace)
+		}
+	}
+	return changed, nil
+}
+
+// ClusterConfigNamespaces returns the namespaces of the Argo CD instances allowed to manage cluster configuration:
+// the namespaces the operator was configured with and the namespaces granted by SetClusterConfigNamespaces
+func ClusterConfigNamespaces() []string {
+	clusterConfig.RLock()
+	defer clusterConfig.RUnlock()
+	return unionNamespaces(clusterConfig.configured, clusterConfig.granted)
+}
+
+// IsClusterConfigNamespace returns true if the Argo CD instances of the given namespace are allowed to manage
+// cluster configuration
+func IsClusterConfigNamespace(namespace string) bool {
+	return namespaceAllowed(namespace, ClusterConfigNamespaces())
+}
+
+// SetConfiguredClusterConfigNamespaces replaces the namespaces the operator was configured with and revokes the
+// granted ones, for tests
+func SetConfiguredClusterConfigNamespaces(namespaces string) {
+	clusterConfig.Lock()
+	defer clusterConfig.Unlock()
+	clusterConfig.configured = unionNamespaces(strings.Split(namespaces, ","))
+	clusterConfig.granted = nil
+}
+
+func namespaceAllowed(namespace string, namespaces []string) bool {
+	return slices.Contains(namespaces, "*") || slices.Contains(namespaces, namespace)
+}
+
+// unionNamespaces returns the namespaces of the given lists without blanks and duplicates, in order. A wildcard
+// already grants the privileges to every namespace.
+func unionNamespaces(lists ...[]string) []string {
+	var values []string
+	for _, list := range lists {
+		for _, namespace := range list {
+			namespace = strings.TrimSpace(namespace)
+			if namespace == "" || slices.Contains(values, namespace) {
+				continue
+			}
+			values = append(values, namespace)
+		}
+	}
+	if slices.Contains(values, "*") {
+		return []string{"*"}
+	}
+	return values
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSetClusterConfigNamespaces(t *testing.T) {
+	defer SetConfiguredClusterConfigNamespaces(strings.Join(clusterConfig.configured, ","))
+	t.Setenv(ClusterConfigNamespacesEnvVar, "openshift-gitops")
+	SetConfiguredClusterConfigNamespaces("openshift-gitops, argocd")
+
+	changed, err := SetClusterConfigNamespaces([]string{"cluster-config", "openshift-gitops"})
+	assertNoError(t, err)
+	assertNamespaces(t, changed, []string{"cluster-config"})
+	assertNamespaces(t, ClusterConfigNamespaces(), []string{"openshift-gitops", "argocd", "cluster-config"})
+	assertNamespaces(t, []string{os.Getenv(ClusterConfigNamespacesEnvVar)}, []string{"openshift-gitops,argocd,cluster-config"})
+	if !IsClusterConfigNamespace("cluster-config") || IsClusterConfigNamespace("tenants") {
+		t.Fatalf("unexpected cluster config namespaces %q", ClusterConfigNamespaces())
+	}
+
+	// Nothing changes while the granted namespaces are unchanged
+	changed, err = SetClusterConfigNamespaces([]string{"openshift-gitops", "cluster-config"})
+	assertNoError(t, err)
+	assertNamespaces(t, changed, nil)
+
+	// Namespaces that are no longer given are revoked, the configured ones are kept
+	changed, err = SetClusterConfigNamespaces([]string{"tenants"})
+	assertNoError(t, err)
+	assertNamespaces(t, changed, []string{"tenants", "cluster-config"})
+	assertNamespaces(t, ClusterConfigNamespaces(), []string{"openshift-gitops", "argocd", "tenants"})
+
+	// A configured wildcard already grants every namespace
+	SetConfiguredClusterConfigNamespaces(" *")
+	changed, err = SetClusterConfigNamespaces([]string{"cluster-config"})
+	assertNoError(t, err)
+	assertNamespaces(t, changed, nil)
+	assertNamespaces(t, []string{os.Getenv(ClusterConfigNamespacesEnvVar)}, []string{"*"})
+	if !IsClusterConfigNamespace("tenants") {
+		t.Fatalf("unexpected cluster config namespaces %q", ClusterConfigNamespaces())
+	}
+}
+
+func assertNamespaces(t *testing.T, got, want []string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var discoveryLog = logf.Log.WithName("api_discovery")
+
+// APIDiscovery inspects the cluster periodically and starts the components registered for an API once the API
+// is available, so that the APIs installed after the operator, like the Prometheus operator or the console, are
+// used without restarting the operator. It runs as a Runnable of the manager.
+type APIDiscovery struct {
+	// Interval is the time between two inspections of the cluster
+	Interval time.Duration
+	// Inspect updates the availability of the APIs, InspectCluster when not set
+	Inspect func() error
+
+	mu       sync.Mutex
+	handlers []*apiHandler
+}
+
+type apiHandler struct {
+	name    string
+	found   func() bool
+	start   func() error
+	started bool
+}
+
+// OnAPIFound registers start to be run once the API reported by found is available. When the API is already
+// available, start runs immediately and its error is returned. A start that fails after the manager started is
+// retried by the next inspection.
+func (d *APIDiscovery) OnAPIFound(name string, found func() bool, start func() error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	handler := &apiHandler{name: name, found: found, start: start}
+	d.handlers = append(d.handlers, handler)
+	if !found() {
+		discoveryLog.Info("API not found, waiting for it to be installed", "API", name)
+		return nil
+	}
+	return handler.run()
+}
+
+// Start inspects the cluster every Interval until the context is done
+func (d *APIDiscovery) Start(ctx context.Context) error {
+	inspect := d.Inspect
+	if inspect == nil {
+		inspect = InspectCluster
+	}
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := inspect(); err != nil {
+				discoveryLog.Error(err, "unable to inspect cluster")
+			}
+			d.startFound()
+		}
+	}
+}
+
+// startFound runs the handlers of the APIs that became available
+func (d *APIDiscovery) startFound() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, handler := range d.handlers {
+		if handler.started || !handler.found() {
+			continue
+		}
+		discoveryLog.Info("API found, starting its components", "API", handler.name)
+		if err := handler.run(); err != nil {
+			discoveryLog.Error(err, "unable to start the components of the API", "API", handler.name)
+		}
+	}
+}
+
+func (h *apiHandler) run() error {
+	if err := h.start(); err != nil {
+		return err
+	}
+	h.started = true
+	return nil
+}
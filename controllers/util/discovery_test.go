@@ -0,0 +1,93 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	stderrors "errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAPIDiscovery(t *testing.T) {
+	var found, failing atomic.Bool
+	var inspections, starts atomic.Int32
+	discovery := &APIDiscovery{
+		Interval: 10 * time.Millisecond,
+		Inspect: func() error {
+			inspections.Add(1)
+			return nil
+		},
+	}
+
+	// the components of an available API start immediately
+	err := discovery.OnAPIFound("available", func() bool { return true }, func() error { return nil })
+	assertNoError(t, err)
+	err = discovery.OnAPIFound("failing", func() bool { return true }, func() error { return stderrors.New("failed") })
+	if err == nil {
+		t.Fatal("expected the error of the start function")
+	}
+
+	err = discovery.OnAPIFound("later", found.Load, func() error {
+		starts.Add(1)
+		if failing.Load() {
+			return stderrors.New("failed")
+		}
+		return nil
+	})
+	assertNoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- discovery.Start(ctx) }()
+	waitFor := func(condition func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !condition(); {
+			if time.Now().After(deadline) {
+				t.Fatal("timed out")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// the components are not started before the API is found
+	waitFor(func() bool { return inspections.Load() >= 2 })
+	if starts.Load() != 0 {
+		t.Fatalf("got %d starts before the API was found", starts.Load())
+	}
+
+	// a failed start is retried, and the components are only started once
+	failing.Store(true)
+	found.Store(true)
+	waitFor(func() bool { return starts.Load() >= 2 })
+	failing.Store(false)
+	waitFor(func() bool {
+		discovery.mu.Lock()
+		defer discovery.mu.Unlock()
+		return discovery.handlers[2].started
+	})
+	current := starts.Load()
+	inspected := inspections.Load()
+	waitFor(func() bool { return inspections.Load() >= inspected+2 })
+	if starts.Load() != current {
+		t.Fatalf("got %d starts after the components started, want %d", starts.Load(), current)
+	}
+
+	cancel()
+	assertNoError(t, <-done)
+}
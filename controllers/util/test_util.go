@@ -18,39 +18,39 @@ package util
 
 // *** THIS SHOULD ONLY BE USED FOR UNIT TESTING ***
 func SetConfigAPIFound(found bool) {
-	configAPIFound = found
+	configAPIFound.Store(found)
 }
 
 func SetMonitoringAPIFound(found bool) {
-	monitoringAPIFound = found
+	monitoringAPIFound.Store(found)
 }
 
 // *** THIS SHOULD ONLY BE USED FOR UNIT TESTING ***
 func SetConsoleAPIFound(found bool) {
-	consoleAPIFound = found
+	consoleAPIFound.Store(found)
 }
 
 // *** THIS SHOULD ONLY BE USED FOR UNIT TESTING ***
 func SetRouteAPIFound(found bool) {
-	routeAPIFound = found
+	routeAPIFound.Store(found)
 }
 
 // *** THIS SHOULD ONLY BE USED FOR UNIT TESTING ***
 func SetTemplateAPIFound(found bool) {
-	templateAPIFound = found
+	templateAPIFound.Store(found)
 }
 
 // *** THIS SHOULD ONLY BE USED FOR UNIT TESTING ***
 func SetAppsAPIFound(found bool) {
-	appsAPIFound = found
+	appsAPIFound.Store(found)
 }
 
 // *** THIS SHOULD ONLY BE USED FOR UNIT TESTING ***
 func SetOAuthAPIFound(found bool) {
-	oauthAPIFound = found
+	oauthAPIFound.Store(found)
 }
 
 // *** THIS SHOULD ONLY BE USED FOR UNIT TESTING ***
 func SetOLMAPIFound(found bool) {
-	olmAPIFound = found
+	olmAPIFound.Store(found)
 }
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync/atomic"
 
 	"github.com/argoproj-labs/argocd-operator/controllers/argoutil"
 	oappsv1 "github.com/openshift/api/apps/v1"
@@ -45,14 +46,14 @@ const (
 )
 
 var (
-	consoleAPIFound    = false
-	routeAPIFound      = false
-	monitoringAPIFound = false
-	configAPIFound     = false
-	templateAPIFound   = false
-	appsAPIFound       = false
-	oauthAPIFound      = false
-	olmAPIFound        = false
+	consoleAPIFound    atomic.Bool
+	routeAPIFound      atomic.Bool
+	monitoringAPIFound atomic.Bool
+	configAPIFound     atomic.Bool
+	templateAPIFound   atomic.Bool
+	appsAPIFound       atomic.Bool
+	oauthAPIFound      atomic.Bool
+	olmAPIFound        atomic.Bool
 )
 
 // GetClusterVersion returns the OpenShift Cluster version in which the operator is installed
@@ -98,7 +99,7 @@ func InspectCluster() error {
 		errs = append(errs, err)
 		return stderrors.Join(errs...)
 	}
-	if !configAPIFound {
+	if !configAPIFound.Load() {
 		return nil
 	}
 
@@ -118,7 +119,7 @@ func InspectCluster() error {
 
 // IsConfigAPIFound return true if the CRD config.openshift.io is available in the cluster and false otherwise.
 func IsConfigAPIFound() bool {
-	return configAPIFound
+	return configAPIFound.Load()
 }
 
 // IsOpenShiftCluster uses IsConfigAPIFound to check if the cluster is an OpenShift cluster.
@@ -132,13 +133,13 @@ func verifyConfigAPI() error {
 	if err != nil {
 		return err
 	}
-	configAPIFound = found
+	configAPIFound.Store(found)
 	return nil
 }
 
 // IsConsoleAPIFound return true if the CRD console.openshift.io is available in the cluster.
 func IsConsoleAPIFound() bool {
-	return consoleAPIFound
+	return consoleAPIFound.Load()
 }
 
 func verifyConsoleAPI() error {
@@ -146,13 +147,13 @@ func verifyConsoleAPI() error {
 	if err != nil {
 		return err
 	}
-	consoleAPIFound = found
+	consoleAPIFound.Store(found)
 	return nil
 }
 
 // IsRouteAPIFound return true if the CRD route.openshift.io is available in the cluster.
 func IsRouteAPIFound() bool {
-	return routeAPIFound
+	return routeAPIFound.Load()
 }
 
 func verifyRouteAPI() error {
@@ -160,7 +161,7 @@ func verifyRouteAPI() error {
 	if err != nil {
 		return err
 	}
-	routeAPIFound = found
+	routeAPIFound.Store(found)
 	return nil
 }
 
@@ -172,18 +173,18 @@ func verifyMonitoringAPI() error {
 	if err != nil {
 		return err
 	}
-	monitoringAPIFound = found
+	monitoringAPIFound.Store(found)
 	return nil
 }
 
 // IsMonitoringAPIFound return true if the CRD monitoring.coreos.com is available in the cluster.
 func IsMonitoringAPIFound() bool {
-	return monitoringAPIFound
+	return monitoringAPIFound.Load()
 }
 
 // IsTemplateAPIFound return true if the CRD template.openshift.io is available in the cluster.
 func IsTemplateAPIFound() bool {
-	return templateAPIFound
+	return templateAPIFound.Load()
 }
 
 func verifyTemplateAPI() error {
@@ -191,13 +192,13 @@ func verifyTemplateAPI() error {
 	if err != nil {
 		return err
 	}
-	templateAPIFound = found
+	templateAPIFound.Store(found)
 	return nil
 }
 
 // IsAppsAPIFound return true if the CRD apps.openshift.io is available in the cluster.
 func IsAppsAPIFound() bool {
-	return appsAPIFound
+	return appsAPIFound.Load()
 }
 
 func verifyAppsAPI() error {
@@ -205,13 +206,13 @@ func verifyAppsAPI() error {
 	if err != nil {
 		return err
 	}
-	appsAPIFound = found
+	appsAPIFound.Store(found)
 	return nil
 }
 
 // IsOAuthAPIFound return true if the CRD oauth.openshift.io is available in the cluster.
 func IsOAuthAPIFound() bool {
-	return oauthAPIFound
+	return oauthAPIFound.Load()
 }
 
 func verifyOAuthAPI() error {
@@ -219,13 +220,13 @@ func verifyOAuthAPI() error {
 	if err != nil {
 		return err
 	}
-	oauthAPIFound = found
+	oauthAPIFound.Store(found)
 	return nil
 }
 
 // IsOLMAPIFound return true if the CRD operators.coreos.com is available in the cluster.
 func IsOLMAPIFound() bool {
-	return olmAPIFound
+	return olmAPIFound.Load()
 }
 
 func verifyOLMAPI() error {
@@ -233,7 +234,7 @@ func verifyOLMAPI() error {
 	if err != nil {
 		return err
 	}
-	olmAPIFound = found
+	olmAPIFound.Store(found)
 	return nil
 }
 
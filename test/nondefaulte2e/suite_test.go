@@ -136,6 +136,7 @@ var _ = BeforeSuite(func() {
 		Client:                mgr.GetClient(),
 		Scheme:                mgr.GetScheme(),
 		DisableDefaultInstall: strings.ToLower(os.Getenv(common.DisableDefaultInstallEnvVar)) == "true",
+		Recorder:              mgr.GetEventRecorderFor("gitopsservice-controller"), //nolint:staticcheck // SA1019: core events are used by the operator
 	}).SetupWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
@@ -146,8 +147,9 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 
 	err = (&controllers.ArgoCDMetricsReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("argocd-metrics-controller"), //nolint:staticcheck // SA1019: core events are used by the operator
 	}).SetupWithManager(mgr)
 	Expect(err).NotTo(HaveOccurred())
 
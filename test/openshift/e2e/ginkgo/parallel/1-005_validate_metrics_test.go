@@ -87,32 +87,29 @@ var _ = Describe("GitOps Operator Parallel E2E Tests", func() {
 				},
 			}))
 
-			By("verifying PrometheusRule gitops-operator-argocd-alerts exists and has expected values")
+			By("verifying PrometheusRule openshift-gitops-alerts exists and has expected values")
 			alertRule := &monitoringv1.PrometheusRule{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "gitops-operator-argocd-alerts",
+					Name:      "openshift-gitops-alerts",
 					Namespace: "openshift-gitops",
 				},
 			}
 			Eventually(alertRule).Should(k8sFixture.ExistByName())
 
-			Expect(alertRule.Spec.Groups).To(Equal([]monitoringv1.RuleGroup{{
-				Name: "GitOpsOperatorArgoCD",
-				Rules: []monitoringv1.Rule{
-					{
-						Alert: "ArgoCDSyncAlert",
-						Annotations: map[string]string{
-							"summary":     "Argo CD application is out of sync",
-							"description": "Argo CD application {{ $labels.name }} is out of sync. Check ArgoCDSyncAlert status, this alert is designed to notify that an application managed by Argo CD is out of sync.",
-						},
-						Expr: intstr.FromString(`argocd_app_info{namespace="openshift-gitops",sync_status="OutOfSync"} > 0`),
-						For:  ptr.To(monitoringv1.Duration("5m")),
-						Labels: map[string]string{
-							"severity": "warning",
-						},
-					},
+			Expect(alertRule.Spec.Groups).To(HaveLen(1))
+			Expect(alertRule.Spec.Groups[0].Name).To(Equal("GitOpsOperatorArgoCD"))
+			Expect(alertRule.Spec.Groups[0].Rules).To(ContainElement(monitoringv1.Rule{
+				Alert: "ArgoCDSyncAlert",
+				Annotations: map[string]string{
+					"summary":     "Argo CD application is out of sync",
+					"description": "Argo CD application {{ $labels.name }} is out of sync. Check ArgoCDSyncAlert status, this alert is designed to notify that an application managed by Argo CD is out of sync.",
 				},
-			}}))
+				Expr: intstr.FromString(`argocd_app_info{namespace="openshift-gitops",service="openshift-gitops-metrics",sync_status="OutOfSync"} > 0`),
+				For:  ptr.To(monitoringv1.Duration("5m")),
+				Labels: map[string]string{
+					"severity": "warning",
+				},
+			}))
 
 		})
 